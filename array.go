@@ -0,0 +1,148 @@
+package jsonb
+
+import (
+	"bytes"
+	"sort"
+)
+
+// FilterArray returns a JSON array with the elements of the JSON array data
+// for which fn returns true. The fn function receives the raw bytes of each
+// element, which are only valid for the duration of the call, and the
+// elements that are kept are copied verbatim. It returns ErrNotArray if data
+// is not an array, and the first error returned by fn.
+func FilterArray(data []byte, fn func(elem []byte) (bool, error)) ([]byte, error) {
+	dst := []byte{'['}
+	n := 0
+	err := eachElement(data, func(elem []byte) error {
+		keep, err := fn(elem)
+		if err != nil || !keep {
+			return err
+		}
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, elem...)
+		n++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, ']'), nil
+}
+
+// MapArray returns a JSON array with the results of fn for each element of
+// the JSON array data. The fn function receives the raw bytes of each
+// element, which are only valid for the duration of the call, and returns
+// the raw bytes of the new element, which must be a single valid JSON value.
+// If fn returns nil, the element is omitted. It returns ErrNotArray if data
+// is not an array, and the first error returned by fn.
+func MapArray(data []byte, fn func(elem []byte) ([]byte, error)) ([]byte, error) {
+	var b Builder
+	b.BeginArray()
+	err := eachElement(data, func(elem []byte) error {
+		v, err := fn(elem)
+		if err != nil || v == nil {
+			return err
+		}
+		b.Raw(v)
+		return b.err
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.EndArray()
+	return b.Bytes(), nil
+}
+
+// GroupBy groups the objects of the JSON array data by the value of their
+// member key, and returns a JSON object with one member per distinct value,
+// which is the array of the elements of that group, in order. Its keys are
+// sorted. Strings are grouped by their decoded value, and other values by
+// their compact JSON text, so the number 1 is in the "1" group. Elements
+// that are not objects or do not have the member are in the "" group. It
+// returns ErrNotArray if data is not an array.
+func GroupBy(data []byte, key string) ([]byte, error) {
+	groups := make(map[string]*bytes.Buffer)
+	err := eachElement(data, func(elem []byte) error {
+		name, err := groupName(elem, key)
+		if err != nil {
+			return err
+		}
+		buf := groups[name]
+		if buf == nil {
+			buf = new(bytes.Buffer)
+			groups[name] = buf
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.Write(elem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dst := []byte{'{'}
+	for i, name := range names {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendQuote(dst, name)
+		dst = append(dst, ':', '[')
+		dst = append(dst, groups[name].Bytes()...)
+		dst = append(dst, ']')
+	}
+	return append(dst, '}'), nil
+}
+
+// groupName returns the name of the group of elem for GroupBy.
+func groupName(elem []byte, key string) (string, error) {
+	p := NewParserBytes(elem)
+	if err := p.nextToken(); err != nil {
+		return "", err
+	}
+	if p.tok != ObjectStart {
+		return "", nil
+	}
+	if err := p.descend("", key); err != nil {
+		if _, ok := err.(*PointerError); ok {
+			return "", nil
+		}
+		return "", err
+	}
+	if p.tok == String {
+		return p.String()
+	}
+	// ReadRaw drops the whitespace between the tokens of arrays and objects,
+	// so that [1, 2] and [1,2] are in the same group
+	v, err := ReadRaw(p)
+	return string(v), err
+}
+
+// eachElement calls fn with the raw bytes of each element of the JSON array
+// data, in order. It returns ErrNotArray if data is not an array, and the
+// first error returned by fn.
+func eachElement(data []byte, fn func(elem []byte) error) error {
+	p := NewParserBytes(data)
+	err := ReadArray(p, func(int) error {
+		start := p.start
+		if !p.Skip() {
+			return p.valueErr()
+		}
+		return fn(data[start : p.start+int64(p.buf.Len())])
+	})
+	if err != nil {
+		return err
+	}
+
+	// only whitespace may follow the array
+	p.Next()
+	return p.Err()
+}
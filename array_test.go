@@ -0,0 +1,110 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFilterArray(t *testing.T) {
+	errFail := errors.New("fail")
+	notNull := func(elem []byte) (bool, error) {
+		return !IsNull(elem), nil
+	}
+
+	cases := []struct {
+		in  string
+		fn  func([]byte) (bool, error)
+		out string
+		err error
+	}{
+		{in: `[]`, fn: notNull, out: `[]`},
+		{in: ` [null, 1, {"a": null}, null, [ 2 ]] `, fn: notNull, out: `[1,{"a": null},[ 2 ]]`},
+		{in: `[null, null]`, fn: notNull, out: `[]`},
+		{in: `[1, 2]`, fn: func(elem []byte) (bool, error) { return false, errFail }, err: errFail},
+		{in: `{"a": 1}`, fn: notNull, err: ErrNotArray},
+		{in: `[1, 2`, fn: notNull, err: io.ErrUnexpectedEOF},
+		{in: `[1] 2`, fn: notNull, err: &SyntaxError{Char: '2', Line: 1, Column: 5, Offset: 4, Near: []byte(`[1] 2`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		out, err := FilterArray([]byte(c.in), c.fn)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+func TestMapArray(t *testing.T) {
+	errFail := errors.New("fail")
+	wrap := func(elem []byte) ([]byte, error) {
+		if IsNull(elem) {
+			return nil, nil
+		}
+		return append(append([]byte(`{"v": `), elem...), '}'), nil
+	}
+
+	cases := []struct {
+		in  string
+		fn  func([]byte) ([]byte, error)
+		out string
+		err error
+	}{
+		{in: `[]`, fn: wrap, out: `[]`},
+		{in: ` [1, null, [ 2 ], "a"] `, fn: wrap, out: `[{"v": 1},{"v": [ 2 ]},{"v": "a"}]`},
+		{in: `[null]`, fn: wrap, out: `[]`},
+		{in: `[1]`, fn: func(elem []byte) ([]byte, error) { return nil, errFail }, err: errFail},
+		{in: `[1]`, fn: func(elem []byte) ([]byte, error) { return []byte(`[`), nil },
+			err: &BuilderError{Op: "Raw", Reason: io.ErrUnexpectedEOF.Error()}},
+		{in: `[1, 2]`, fn: func(elem []byte) ([]byte, error) { return []byte(` `), nil },
+			err: &BuilderError{Op: "Raw", Reason: io.ErrUnexpectedEOF.Error()}},
+		{in: `1`, fn: wrap, err: ErrNotArray},
+		{in: `[1,`, fn: wrap, err: &SyntaxError{Char: -1, Line: 1, Column: 4, Offset: 3, Near: []byte(`[1,`), typ: begVal}},
+	}
+
+	for i, c := range cases {
+		out, err := MapArray([]byte(c.in), c.fn)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	cases := []struct {
+		in  string
+		key string
+		out string
+		err error
+	}{
+		{in: `[]`, key: "a", out: `{}`},
+		{in: `[{"a": "x", "b": 1}, {"a": "y"}, {"a": "x", "b": 2}]`, key: "a",
+			out: `{"x":[{"a": "x", "b": 1},{"a": "x", "b": 2}],"y":[{"a": "y"}]}`},
+		{in: `[{"a": 1}, {"a": "1"}, {"a": [1, 2]}, {"a": null}, {"b": 1}, 3, {"a": "é"}]`, key: "a",
+			out: `{"":[{"b": 1},3],"1":[{"a": 1},{"a": "1"}],"[1,2]":[{"a": [1, 2]}],"null":[{"a": null}],"é":[{"a": "é"}]}`},
+		{in: `[{"k": [1, 2]}, {"k":[1,2]}, {"k": { "a" : [ 1 ] }}, {"k":{"a":[1]}}]`, key: "k",
+			out: `{"[1,2]":[{"k": [1, 2]},{"k":[1,2]}],"{\"a\":[1]}":[{"k": { "a" : [ 1 ] }},{"k":{"a":[1]}}]}`},
+		{in: `{"a": 1}`, key: "a", err: ErrNotArray},
+		{in: `[{"a": 1}`, key: "a", err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		out, err := GroupBy([]byte(c.in), c.key)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
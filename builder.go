@@ -0,0 +1,234 @@
+package jsonb
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// BuilderError is returned by Builder.Err when a method of the builder is
+// called at a position where it would produce invalid JSON.
+type BuilderError struct {
+	Op     string // the method of the Builder that failed
+	Reason string
+}
+
+func (e *BuilderError) Error() string {
+	return fmt.Sprintf("jsonb: Builder.%s: %s", e.Op, e.Reason)
+}
+
+// Builder generates a JSON document incrementally. It inserts the commas
+// between the elements of arrays and the members of objects, and the colons
+// after the object keys. The zero value is ready to use.
+//
+// The first invalid call, such as Key in an array or EndObject without
+// a matching BeginObject, sets the error returned by Err, and all later
+// calls are ignored.
+type Builder struct {
+	buf   bytes.Buffer
+	stack []state
+	elems bool // an element was written in the current container
+	done  bool // the top-level value was written
+	err   error
+}
+
+// Bytes returns the JSON document built so far. The slice is only valid
+// until the next call to a method of the builder.
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// Err returns the first error encountered, or an error if some arrays or
+// objects are not closed.
+func (b *Builder) Err() error {
+	if b.err == nil && len(b.stack) > 0 {
+		return &BuilderError{Op: "Err", Reason: "unclosed array or object"}
+	}
+	return b.err
+}
+
+// Null writes a null value.
+func (b *Builder) Null() {
+	if b.value("Null") {
+		b.buf.WriteString("null")
+	}
+}
+
+// Bool writes a true or false value.
+func (b *Builder) Bool(v bool) {
+	if b.value("Bool") {
+		b.buf.Write(strconv.AppendBool(b.buf.AvailableBuffer(), v))
+	}
+}
+
+// String writes s as a string value, escaping it as needed. Invalid UTF-8
+// bytes are replaced by \ufffd.
+func (b *Builder) String(s string) {
+	if b.value("String") {
+		b.buf.Write(appendQuote(b.buf.AvailableBuffer(), s))
+	}
+}
+
+// RawString writes s as a string value without escaping it, only adding
+// the surrounding double-quotes. The content of s must be a valid, escaped,
+// JSON string.
+func (b *Builder) RawString(s []byte) {
+	if b.value("RawString") {
+		b.buf.WriteByte('"')
+		b.buf.Write(s)
+		b.buf.WriteByte('"')
+	}
+}
+
+// Int64 writes v as a number value.
+func (b *Builder) Int64(v int64) {
+	if b.value("Int64") {
+		b.buf.Write(strconv.AppendInt(b.buf.AvailableBuffer(), v, 10))
+	}
+}
+
+// Uint64 writes v as a number value.
+func (b *Builder) Uint64(v uint64) {
+	if b.value("Uint64") {
+		b.buf.Write(strconv.AppendUint(b.buf.AvailableBuffer(), v, 10))
+	}
+}
+
+// Float64 writes v as a number value, in the shortest form that represents
+// it. NaN and infinite values cannot be written and set the error.
+func (b *Builder) Float64(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		b.fail("Float64", "unsupported value "+strconv.FormatFloat(v, 'g', -1, 64))
+		return
+	}
+	if b.value("Float64") {
+		b.buf.Write(appendES6Number(b.buf.AvailableBuffer(), v))
+	}
+}
+
+// Raw writes v verbatim as a value. It must be a single valid JSON value.
+func (b *Builder) Raw(v []byte) {
+	if err := ValidateBytes(v); err != nil {
+		b.fail("Raw", err.Error())
+		return
+	}
+	b.rawValue("Raw", v)
+}
+
+// rawValue writes v verbatim as a value, without validating it.
+func (b *Builder) rawValue(op string, v []byte) {
+	if b.value(op) {
+		b.buf.Write(v)
+	}
+}
+
+// BeginObject starts an object value.
+func (b *Builder) BeginObject() {
+	if b.value("BeginObject") {
+		b.buf.WriteByte('{')
+		b.stack = append(b.stack, stObjKey)
+		b.elems = false
+	}
+}
+
+// EndObject ends the current object.
+func (b *Builder) EndObject() {
+	if b.end("EndObject", stObjKey) {
+		b.buf.WriteByte('}')
+	}
+}
+
+// BeginArray starts an array value.
+func (b *Builder) BeginArray() {
+	if b.value("BeginArray") {
+		b.buf.WriteByte('[')
+		b.stack = append(b.stack, stArray)
+		b.elems = false
+	}
+}
+
+// EndArray ends the current array.
+func (b *Builder) EndArray() {
+	if b.end("EndArray", stArray) {
+		b.buf.WriteByte(']')
+	}
+}
+
+// Key writes the key of the next member of the current object. It must be
+// followed by the value of the member. The key is escaped like String.
+func (b *Builder) Key(k string) {
+	if b.err != nil {
+		return
+	}
+	l := len(b.stack)
+	if l == 0 || b.stack[l-1] != stObjKey {
+		b.fail("Key", "not expecting an object key")
+		return
+	}
+	if b.elems {
+		b.buf.WriteByte(',')
+	}
+	b.buf.Write(appendQuote(b.buf.AvailableBuffer(), k))
+	b.buf.WriteByte(':')
+	b.stack[l-1] = stObjVal
+}
+
+// value prepares the builder to write a value, returning false if a value
+// cannot be written at the current position.
+func (b *Builder) value(op string) bool {
+	if b.err != nil {
+		return false
+	}
+
+	l := len(b.stack)
+	if l == 0 {
+		if b.done {
+			b.fail(op, "multiple top-level values")
+			return false
+		}
+		b.done = true
+		return true
+	}
+
+	switch b.stack[l-1] {
+	case stObjKey:
+		b.fail(op, "missing object key")
+		return false
+	case stObjVal:
+		b.stack[l-1] = stObjKey
+	default:
+		if b.elems {
+			b.buf.WriteByte(',')
+		}
+	}
+	b.elems = true
+	return true
+}
+
+// end closes the current container, which must be of the specified state,
+// returning false if it cannot be closed.
+func (b *Builder) end(op string, st state) bool {
+	if b.err != nil {
+		return false
+	}
+	l := len(b.stack)
+	if l == 0 || b.stack[l-1] != st {
+		if l > 0 && b.stack[l-1] == stObjVal {
+			b.fail(op, "missing value for object key")
+		} else {
+			b.fail(op, "no matching begin")
+		}
+		return false
+	}
+	b.stack = b.stack[:l-1]
+	b.elems = true
+	return true
+}
+
+// fail sets the error of the builder, if it is the first one.
+func (b *Builder) fail(op, reason string) {
+	if b.err == nil {
+		b.err = &BuilderError{Op: op, Reason: reason}
+	}
+}
@@ -0,0 +1,104 @@
+package jsonb
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	cases := []struct {
+		build func(b *Builder)
+		out   string
+		err   error
+	}{
+		{build: func(b *Builder) {}, out: ``},
+		{build: func(b *Builder) { b.Null() }, out: `null`},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.Bool(true)
+			b.Int64(-1)
+			b.Uint64(math.MaxUint64)
+			b.Float64(1.5e-7)
+			b.String("a\"\n")
+			b.RawString([]byte(`é`))
+			b.Raw([]byte(`{"x": [1]}`))
+			b.BeginArray()
+			b.EndArray()
+			b.EndArray()
+		}, out: `[true,-1,18446744073709551615,1.5e-7,"a\"\n","é",{"x": [1]},[]]`},
+		{build: func(b *Builder) {
+			b.BeginObject()
+			b.Key("a")
+			b.BeginObject()
+			b.EndObject()
+			b.Key("b")
+			b.BeginArray()
+			b.BeginObject()
+			b.Key("c")
+			b.Null()
+			b.EndObject()
+			b.Float64(0)
+			b.EndArray()
+			b.Key("d")
+			b.String("e")
+			b.EndObject()
+		}, out: `{"a":{},"b":[{"c":null},0],"d":"e"}`},
+		{build: func(b *Builder) {
+			b.BeginObject()
+			b.Key("k\xff")
+			b.String("a\xffb")
+			b.EndObject()
+		}, out: `{"k\ufffd":"a\ufffdb"}`},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.Key("a")
+			b.Null()
+		}, out: `[`, err: &BuilderError{Op: "Key", Reason: "not expecting an object key"}},
+		{build: func(b *Builder) {
+			b.BeginObject()
+			b.Null()
+		}, out: `{`, err: &BuilderError{Op: "Null", Reason: "missing object key"}},
+		{build: func(b *Builder) {
+			b.BeginObject()
+			b.Key("a")
+			b.EndObject()
+		}, out: `{"a":`, err: &BuilderError{Op: "EndObject", Reason: "missing value for object key"}},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.EndObject()
+		}, out: `[`, err: &BuilderError{Op: "EndObject", Reason: "no matching begin"}},
+		{build: func(b *Builder) {
+			b.Int64(1)
+			b.Int64(2)
+		}, out: `1`, err: &BuilderError{Op: "Int64", Reason: "multiple top-level values"}},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.Float64(math.NaN())
+		}, out: `[`, err: &BuilderError{Op: "Float64", Reason: "unsupported value NaN"}},
+		{build: func(b *Builder) {
+			b.Raw([]byte(`[1`))
+		}, out: ``, err: &BuilderError{Op: "Raw", Reason: "unexpected EOF"}},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.Raw([]byte(``))
+			b.Raw([]byte(`  `))
+			b.Int64(1)
+		}, out: `[`, err: &BuilderError{Op: "Raw", Reason: "unexpected EOF"}},
+		{build: func(b *Builder) {
+			b.BeginArray()
+			b.BeginObject()
+		}, out: `[{`, err: &BuilderError{Op: "Err", Reason: "unclosed array or object"}},
+	}
+
+	for i, c := range cases {
+		var b Builder
+		c.build(&b)
+		if err := b.Err(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if got := string(b.Bytes()); got != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, got)
+		}
+	}
+}
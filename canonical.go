@@ -0,0 +1,134 @@
+package jsonb
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+var (
+	// ErrDuplicateKey is returned by Canonicalize when an object has
+	// duplicate keys, which cannot be ordered.
+	ErrDuplicateKey = errors.New("jsonb: duplicate object key")
+
+	// ErrNumberRange is returned by Canonicalize when a number cannot be
+	// represented as an IEEE 754 double.
+	ErrNumberRange = errors.New("jsonb: number out of range")
+)
+
+// Canonicalize returns the canonical form of the JSON document src, as
+// defined by the JSON Canonicalization Scheme of RFC 8785. The document is
+// written without whitespace, with the members of objects sorted by the
+// UTF-16 code units of their keys, strings written with the minimal escape
+// sequences and numbers written in the shortest form that represents the
+// same IEEE 754 double, as in ECMAScript. Two semantically identical
+// documents have the same canonical form.
+//
+// As required by RFC 8785, strings are not normalized: the decoded
+// characters are written as-is. Objects must not have duplicate keys.
+func Canonicalize(src []byte) ([]byte, error) {
+	root, err := parseTree(src)
+	if err != nil {
+		return nil, err
+	}
+	return root.appendCanonical(nil)
+}
+
+// appendCanonical appends the canonical form of n to dst.
+func (n *Node) appendCanonical(dst []byte) ([]byte, error) {
+	var err error
+	switch n.tok {
+	case ArrayStart:
+		dst = append(dst, '[')
+		for i, elem := range n.elems {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = elem.appendCanonical(dst); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+
+	case ObjectStart:
+		order := make([]int, len(n.keys))
+		for i := range order {
+			order[i] = i
+		}
+		keys := make([][]uint16, len(n.keys))
+		for i, k := range n.keys {
+			keys[i] = utf16.Encode([]rune(k))
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return lessUTF16(keys[order[i]], keys[order[j]])
+		})
+
+		dst = append(dst, '{')
+		for i, ix := range order {
+			if i > 0 {
+				if n.keys[order[i-1]] == n.keys[ix] {
+					return nil, ErrDuplicateKey
+				}
+				dst = append(dst, ',')
+			}
+			dst = appendQuote(dst, n.keys[ix])
+			dst = append(dst, ':')
+			if dst, err = n.elems[ix].appendCanonical(dst); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+
+	case String:
+		s, err := appendUnquote(nil, n.raw)
+		if err != nil {
+			return nil, err
+		}
+		return appendQuote(dst, string(s)), nil
+
+	case Number:
+		f, err := strconv.ParseFloat(unsafeString(n.raw), 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, ErrNumberRange
+		}
+		return appendES6Number(dst, f), nil
+	}
+	return append(dst, n.raw...), nil
+}
+
+// lessUTF16 returns true if a sorts before b by comparing their UTF-16
+// code units.
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// appendES6Number appends the shortest representation of the finite number
+// f to dst, following the Number serialization of ECMAScript.
+func appendES6Number(dst []byte, f float64) []byte {
+	if f == 0 {
+		// including negative zero
+		return append(dst, '0')
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// ECMAScript has no leading zero in the exponent, e.g. 1e-07 is 1e-7
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
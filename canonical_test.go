@@ -0,0 +1,47 @@
+package jsonb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+		err error
+	}{
+		// example of RFC 8785, section 3.2.2
+		{in: `{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`, out: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		// sorting example of RFC 8785, section 3.2.3
+		{in: `{"\u20ac": 1, "\r": 2, "\ufb33": 3, "1": 4, "\ud83d\ude00": 5, "\u0080": 6, "\u00f6": 7}`,
+			out: "{\"\\r\":2,\"1\":4,\"\u0080\":6,\"ö\":7,\"€\":1,\"\U0001F600\":5,\"\ufb33\":3}"},
+		{in: `[-0, 0.0, 1e21, 1e20, 9007199254740992, 5e-324, 1.7976931348623157e308, 1e-7, 0.000001, -1.5e-10, 100]`,
+			out: `[0,0,1e+21,100000000000000000000,9007199254740992,5e-324,1.7976931348623157e+308,1e-7,0.000001,-1.5e-10,100]`},
+		{in: `{"b": {"d": 1, "c": 2}, "a": []}`, out: `{"a":[],"b":{"c":2,"d":1}}`},
+		{in: `{"a": 1, "b": 2, "a": 3}`, err: ErrDuplicateKey},
+		{in: `[1e400]`, err: ErrNumberRange},
+	}
+
+	for i, c := range cases {
+		out, err := Canonicalize([]byte(c.in))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+
+	// semantically identical documents have the same canonical form
+	a, err1 := Canonicalize([]byte(`{"a": [1.0, "\u00e9"], "b": {"y": null, "x": 10}}`))
+	b, err2 := Canonicalize([]byte(`{ "b":{"x":1e1,"y":null},"a":[1,"é"] }`))
+	if err1 != nil || err2 != nil || string(a) != string(b) {
+		t.Errorf("want identical canonical forms, got %s (%v) and %s (%v)", a, err1, b, err2)
+	}
+}
@@ -0,0 +1,116 @@
+package jsonb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Decoder reads and decodes a stream of JSON values. It implements the
+// Token, More, Decode and Buffered methods of encoding/json.Decoder on top
+// of a Parser, so that it can be used in place of the standard decoder.
+type Decoder struct {
+	p   *Parser
+	dec decodeState
+}
+
+// NewDecoder returns a decoder that reads a stream of JSON values from r.
+func NewDecoder(r io.Reader) *Decoder {
+	p := NewParserOptions(r, WithMultiValue())
+	return &Decoder{p: p, dec: decodeState{p: p}}
+}
+
+// Token returns the next JSON token in the input stream. At the end of the
+// input, it returns nil and io.EOF. As with encoding/json, strings and object
+// keys are returned as string, numbers as json.Number, booleans as bool,
+// null as nil and the delimiters of arrays and objects as json.Delim.
+func (d *Decoder) Token() (json.Token, error) {
+	p := d.p
+	if !p.Next() {
+		if err := p.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	switch p.tok {
+	case ArrayStart:
+		return json.Delim('['), nil
+	case ArrayEnd:
+		return json.Delim(']'), nil
+	case ObjectStart:
+		return json.Delim('{'), nil
+	case ObjectEnd:
+		return json.Delim('}'), nil
+	case String, ObjectKey:
+		return p.String()
+	case Number:
+		return json.Number(p.buf.String()), nil
+	case True:
+		return true, nil
+	case False:
+		return false, nil
+	case Null:
+		return nil, nil
+	}
+	return nil, p.valueErr()
+}
+
+// More reports whether there is another element in the current array or
+// object, or another value in the input stream at the top level.
+func (d *Decoder) More() bool {
+	tok := d.p.Peek()
+	return tok != Invalid && tok != ArrayEnd && tok != ObjectEnd
+}
+
+// Decode reads the next JSON value from the input and stores it in the
+// value pointed to by v, following the rules of Unmarshal. At the end of
+// the input, it returns io.EOF.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	p := d.p
+	if !p.Next() {
+		if err := p.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	switch p.tok {
+	case Invalid:
+		return p.valueErr()
+	case ArrayEnd, ObjectEnd:
+		return &UnmarshalTypeError{Value: p.tok.String(), Type: rv.Type(), Offset: p.start}
+	}
+
+	d.dec.err = nil
+	if err := d.dec.value(rv); err != nil {
+		return err
+	}
+	return d.dec.err
+}
+
+// Buffered returns a reader of the data remaining in the buffer of the
+// decoder, starting with the rune that follows the last token. It is only
+// valid until the next call to a method of the decoder, and is empty if the
+// decoder has looked ahead with More.
+func (d *Decoder) Buffered() io.Reader {
+	p := d.p
+	var b []byte
+	if p.peeked {
+		return bytes.NewReader(nil)
+	}
+	if p.ch >= 0 {
+		b = utf8.AppendRune(b, p.ch)
+	}
+	if p.bufr != nil && p.r == io.RuneReader(p.bufr) {
+		buf, _ := p.bufr.Peek(p.bufr.Buffered())
+		b = append(b, buf...)
+	}
+	return bytes.NewReader(b)
+}
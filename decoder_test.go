@@ -0,0 +1,122 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoderToken(t *testing.T) {
+	cases := []string{
+		``,
+		`1 "a" true`,
+		`{"a": [1, -2.5e3, "b\n", null, false], "c": {}}`,
+		"[1]\n{\"x\": true}\n",
+	}
+
+	for i, c := range cases {
+		std := json.NewDecoder(strings.NewReader(c))
+		std.UseNumber()
+		want := jsonTokens(std)
+		got := jsonTokens(NewDecoder(strings.NewReader(c)))
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%d (%s): want %#v, got %#v", i, c, want, got)
+		}
+	}
+}
+
+// jsonTokens returns the tokens of dec, followed by the error that ended the
+// stream.
+func jsonTokens(dec interface {
+	Token() (json.Token, error)
+}) []interface{} {
+	var toks []interface{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return append(toks, err)
+		}
+		toks = append(toks, tok)
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	const in = `{"items": [{"field_a": "a", "field_b": 1}, {"field_a": "b", "field_c": true}]} 42`
+
+	dec := NewDecoder(strings.NewReader(in))
+	for _, want := range []interface{}{json.Delim('{'), "items", json.Delim('[')} {
+		if tok, err := dec.Token(); err != nil || tok != want {
+			t.Fatalf("want token %v, got %v (%v)", want, tok, err)
+		}
+	}
+
+	var got []fieldsAD
+	for dec.More() {
+		var v fieldsAD
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	want := []fieldsAD{{A: "a", B: 1}, {A: "b", C: true}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	for _, want := range []interface{}{json.Delim(']'), json.Delim('}')} {
+		if tok, err := dec.Token(); err != nil || tok != want {
+			t.Fatalf("want token %v, got %v (%v)", want, tok, err)
+		}
+	}
+
+	var n int
+	if err := dec.Decode(&n); err != nil || n != 42 {
+		t.Errorf("want 42, got %d (%v)", n, err)
+	}
+	if dec.More() {
+		t.Errorf("want no more values")
+	}
+	if err := dec.Decode(&n); err != io.EOF {
+		t.Errorf("want %v, got %v", io.EOF, err)
+	}
+}
+
+func TestDecoderDecodeKey(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": 1, "b": 2}`))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		t.Fatalf("want token {, got %v (%v)", tok, err)
+	}
+
+	var got []interface{}
+	for dec.More() {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	want := []interface{}{"a", float64(1), "b", float64(2)}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoderBuffered(t *testing.T) {
+	// the input is read through a bufio.Reader
+	r := ioutil.NopCloser(strings.NewReader(`{"a": 1} trailing data`))
+	dec := NewDecoder(r)
+	var v map[string]int
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(io.MultiReader(dec.Buffered(), r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != "trailing data" {
+		t.Errorf("want %q, got %q", "trailing data", got)
+	}
+}
@@ -0,0 +1,184 @@
+package jsonb
+
+import "strconv"
+
+// DiffOp is an operation of the JSON patch returned by Diff.
+type DiffOp struct {
+	Op    string   // "add", "remove", "replace" or "move"
+	Path  []string // the unescaped segments of the path
+	From  []string // the unescaped segments of the source path of a move
+	Value []byte   // the compact value of an add or replace
+}
+
+// Diff returns the operations that transform the JSON document a into the
+// JSON document b, as a JSON patch defined by RFC 6902. The members of
+// objects are compared by key, a member whose value is moved to a new key
+// of the same object being reported as a move, and the elements of arrays
+// are compared using their longest common subsequence, so that inserting
+// or removing an element only adds or removes that element. Values that
+// are equal as reported by Equal are not reported, and for objects with
+// duplicate keys, only the first member of each key is compared.
+//
+// The operations must be applied in order, see DiffPatch to get them as a
+// JSON patch document.
+func Diff(a, b []byte) ([]DiffOp, error) {
+	na, err := parseTree(a)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := parseTree(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffNodes(nil, nil, na, nb), nil
+}
+
+// DiffPatch is like Diff, but returns the operations as a JSON patch
+// document that can be applied to a with Apply.
+func DiffPatch(a, b []byte) ([]byte, error) {
+	ops, err := Diff(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var bld Builder
+	bld.BeginArray()
+	for _, op := range ops {
+		bld.BeginObject()
+		bld.Key("op")
+		bld.String(op.Op)
+		if op.Op == "move" {
+			bld.Key("from")
+			bld.String(formatPointer(op.From))
+		}
+		bld.Key("path")
+		bld.String(formatPointer(op.Path))
+		if op.Value != nil {
+			bld.Key("value")
+			bld.rawValue("Raw", op.Value)
+		}
+		bld.EndObject()
+	}
+	bld.EndArray()
+	return bld.Bytes(), nil
+}
+
+// diffNodes appends to ops the operations that transform a into b, which
+// are at path.
+func diffNodes(ops []DiffOp, path []string, a, b *Node) []DiffOp {
+	if a.equal(b) {
+		return ops
+	}
+	switch {
+	case a.tok == ObjectStart && b.tok == ObjectStart:
+		return diffObjects(ops, path, a, b)
+	case a.tok == ArrayStart && b.tok == ArrayStart:
+		return diffArrays(ops, path, a, b)
+	}
+	return append(ops, DiffOp{Op: "replace", Path: path, Value: b.appendJSON(nil)})
+}
+
+// diffObjects appends to ops the operations that transform the object a
+// into the object b, which are at path.
+func diffObjects(ops []DiffOp, path []string, a, b *Node) []DiffOp {
+	var removed, added []int
+	for i, key := range a.keys {
+		if a.index(key) != i {
+			continue
+		}
+		j := b.index(key)
+		if j < 0 {
+			removed = append(removed, i)
+			continue
+		}
+		ops = diffNodes(ops, appendPath(path, key), a.elems[i], b.elems[j])
+	}
+	for j, key := range b.keys {
+		if b.index(key) == j && a.index(key) < 0 {
+			added = append(added, j)
+		}
+	}
+
+	// a removed member whose value is added under another key is moved
+	for _, j := range added {
+		moved := false
+		for k, i := range removed {
+			if i >= 0 && a.elems[i].equal(b.elems[j]) {
+				ops = append(ops, DiffOp{Op: "move", Path: appendPath(path, b.keys[j]), From: appendPath(path, a.keys[i])})
+				removed[k] = -1
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			ops = append(ops, DiffOp{Op: "add", Path: appendPath(path, b.keys[j]), Value: b.elems[j].appendJSON(nil)})
+		}
+	}
+	for _, i := range removed {
+		if i >= 0 {
+			ops = append(ops, DiffOp{Op: "remove", Path: appendPath(path, a.keys[i])})
+		}
+	}
+	return ops
+}
+
+// diffArrays appends to ops the operations that transform the array a into
+// the array b, which are at path.
+func diffArrays(ops []DiffOp, path []string, a, b *Node) []DiffOp {
+	n, m := len(a.elems), len(b.elems)
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a.elems[i:] and b.elems[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a.elems[i].equal(b.elems[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// k is the index of the current element in the array being patched
+	i, j, k := 0, 0, 0
+	for i < n || j < m {
+		if i < n && j < m && a.elems[i].equal(b.elems[j]) {
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// the elements removed and inserted before the next common element
+		di, dj := i, j
+		for (di < n || dj < m) && !(di < n && dj < m && a.elems[di].equal(b.elems[dj])) {
+			if dj == m || di < n && lcs[di+1][dj] >= lcs[di][dj+1] {
+				di++
+			} else {
+				dj++
+			}
+		}
+
+		// the first ones are changed in place, then the others are removed
+		// or inserted
+		for ; i < di && j < dj; i, j, k = i+1, j+1, k+1 {
+			ops = diffNodes(ops, appendPath(path, strconv.Itoa(k)), a.elems[i], b.elems[j])
+		}
+		for ; i < di; i++ {
+			ops = append(ops, DiffOp{Op: "remove", Path: appendPath(path, strconv.Itoa(k))})
+		}
+		for ; j < dj; j, k = j+1, k+1 {
+			ops = append(ops, DiffOp{Op: "add", Path: appendPath(path, strconv.Itoa(k)), Value: b.elems[j].appendJSON(nil)})
+		}
+	}
+	return ops
+}
+
+// appendPath returns a copy of path with seg appended.
+func appendPath(path []string, seg string) []string {
+	return append(path[:len(path):len(path)], seg)
+}
@@ -0,0 +1,69 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		patch string
+		err   error
+	}{
+		{a: `{"a": 1}`, b: `{"a": 1.0}`, patch: `[]`},
+		{a: `{"a": 1e9999999}`, b: `{"a": 10e9999998}`, patch: `[]`},
+		{a: `[1e999999, 1e999999, 1e999999, 1e999999, 1e999999, 1e999999, 1e999999, 1e999999]`,
+			b:     `[2e999999, 2e999999, 2e999999, 2e999999, 2e999999, 2e999999, 2e999999, 2e999999]`,
+			patch: `[{"op":"replace","path":"/0","value":2e999999},{"op":"replace","path":"/1","value":2e999999},{"op":"replace","path":"/2","value":2e999999},{"op":"replace","path":"/3","value":2e999999},{"op":"replace","path":"/4","value":2e999999},{"op":"replace","path":"/5","value":2e999999},{"op":"replace","path":"/6","value":2e999999},{"op":"replace","path":"/7","value":2e999999}]`},
+		{a: `{"a": 1}`, b: `{"a": 1, "b": 2}`, patch: `[{"op":"add","path":"/b","value":2}]`},
+		{a: `{"a": 1, "b": 2}`, b: `{"a": 1}`, patch: `[{"op":"remove","path":"/b"}]`},
+		{a: `{"a": 1}`, b: `{"a": "x"}`, patch: `[{"op":"replace","path":"/a","value":"x"}]`},
+		{a: `{"a": {"b": [1, {"c/d": true}]}}`, b: `{"a": {"b": [1, {"c/d": false}]}}`, patch: `[{"op":"replace","path":"/a/b/1/c~1d","value":false}]`},
+		{a: `{"a": {"b": 1}, "c": 2}`, b: `{"d": {"b": 1}, "c": 2}`, patch: `[{"op":"move","from":"/a","path":"/d"}]`},
+		{a: `[1, 2, 3]`, b: `[1, 4, 2, 3, 5]`, patch: `[{"op":"add","path":"/1","value":4},{"op":"add","path":"/4","value":5}]`},
+		{a: `[1, 2, 3, 4]`, b: `[2, 4]`, patch: `[{"op":"remove","path":"/0"},{"op":"remove","path":"/1"}]`},
+		{a: `[1, {"a": 1}, 3]`, b: `[1, {"a": 2}, 3]`, patch: `[{"op":"replace","path":"/1/a","value":2}]`},
+		{a: `[1, 2]`, b: `[3]`, patch: `[{"op":"replace","path":"/0","value":3},{"op":"remove","path":"/1"}]`},
+		{a: `[]`, b: `{}`, patch: `[{"op":"replace","path":"","value":{}}]`},
+		{a: `{"a": 1`, b: `{}`, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		patch, err := DiffPatch([]byte(c.a), []byte(c.b))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(patch) != c.patch {
+			t.Errorf("%d: want %s, got %s", i, c.patch, patch)
+		}
+		if err != nil {
+			continue
+		}
+
+		got, err := Apply([]byte(c.a), patch)
+		if err != nil {
+			t.Errorf("%d: Apply failed: %v", i, err)
+			continue
+		}
+		if !Equal(got, []byte(c.b)) {
+			t.Errorf("%d: patched document %s is not %s", i, got, c.b)
+		}
+	}
+}
+
+func TestDiffOps(t *testing.T) {
+	ops, err := Diff([]byte(`{"a": [1], "b/c": 1}`), []byte(`{"a": [1, 2], "b/c": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffOp{
+		{Op: "add", Path: []string{"a", "1"}, Value: []byte(`2`)},
+		{Op: "replace", Path: []string{"b/c"}, Value: []byte(`null`)},
+	}
+	if !reflect.DeepEqual(want, ops) {
+		t.Errorf("want %#v, got %#v", want, ops)
+	}
+}
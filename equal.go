@@ -0,0 +1,107 @@
+package jsonb
+
+import "bytes"
+
+// Equal returns true if the JSON documents a and b are semantically equal,
+// ignoring insignificant whitespace and the order of the members of objects.
+// Strings are compared once decoded, so "\u00e9" equals "é", and numbers
+// are compared by their exact decimal value, so 1.0 equals 1 and 1e1 equals
+// 10. For objects with duplicate keys, only the last member of each key is
+// compared. It returns false if a or b is not valid JSON.
+func Equal(a, b []byte) bool {
+	return equal(a, b, false)
+}
+
+// EqualOrdered is like Equal, but the members of objects must also be in the
+// same order, and duplicate keys are compared like any other member.
+func EqualOrdered(a, b []byte) bool {
+	return equal(a, b, true)
+}
+
+// equal compares the JSON documents a and b.
+func equal(a, b []byte, ordered bool) bool {
+	pa, pb := NewParserBytes(a), NewParserBytes(b)
+	if pa.nextToken() != nil || pb.nextToken() != nil {
+		return false
+	}
+	if !equalValues(pa, pb, a, b, ordered) {
+		return false
+	}
+
+	// only whitespace may follow the values
+	return !pa.Next() && pa.Err() == nil && !pb.Next() && pb.Err() == nil
+}
+
+// equalValues compares the current values of pa and pb, which read from
+// a and b, advancing the parsers to the end of the values.
+func equalValues(pa, pb *Parser, a, b []byte, ordered bool) bool {
+	if pa.tok != pb.tok {
+		return false
+	}
+
+	switch pa.tok {
+	case String, ObjectKey:
+		return equalStrings(pa.buf.Bytes(), pb.buf.Bytes())
+
+	case Number:
+		return equalNumbers(pa.buf.Bytes(), pb.buf.Bytes())
+
+	case ArrayStart:
+		for {
+			if pa.nextToken() != nil || pb.nextToken() != nil {
+				return false
+			}
+			if pa.tok == ArrayEnd || pb.tok == ArrayEnd {
+				return pa.tok == pb.tok
+			}
+			if !equalValues(pa, pb, a, b, ordered) {
+				return false
+			}
+		}
+
+	case ObjectStart:
+		if ordered {
+			for {
+				// the keys, then the values
+				for i := 0; i < 2; i++ {
+					if pa.nextToken() != nil || pb.nextToken() != nil {
+						return false
+					}
+					if pa.tok == ObjectEnd || pb.tok == ObjectEnd {
+						return pa.tok == pb.tok
+					}
+					if !equalValues(pa, pb, a, b, ordered) {
+						return false
+					}
+				}
+			}
+		}
+
+		ma, err := readMembers(pa, a)
+		if err != nil {
+			return false
+		}
+		mb, err := readMembers(pb, b)
+		if err != nil || len(ma) != len(mb) {
+			return false
+		}
+		for i := range ma {
+			if ma[i].key != mb[i].key || !equal(ma[i].raw, mb[i].raw, ordered) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equalStrings returns true if the quoted JSON strings a and b have the
+// same decoded value.
+func equalStrings(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var bufa, bufb [64]byte
+	da, erra := appendUnquote(bufa[:0], a)
+	db, errb := appendUnquote(bufb[:0], b)
+	return erra == nil && errb == nil && bytes.Equal(da, db)
+}
@@ -0,0 +1,50 @@
+package jsonb
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b    string
+		equal   bool
+		ordered bool
+	}{
+		{a: `null`, b: ` null `, equal: true, ordered: true},
+		{a: `true`, b: `false`},
+		{a: `1`, b: `"1"`},
+		{a: `1`, b: `1.0`, equal: true, ordered: true},
+		{a: `1e1`, b: `10`, equal: true, ordered: true},
+		{a: `-0`, b: `0.0e5`, equal: true, ordered: true},
+		{a: `0.001`, b: `1E-3`, equal: true, ordered: true},
+		{a: `100`, b: `1e3`},
+		{a: `12345678901234567890`, b: `12345678901234567891`},
+		{a: `-1`, b: `1`},
+		{a: `"é\n"`, b: `"\u00e9\u000a"`, equal: true, ordered: true},
+		{a: `"a"`, b: `"b"`},
+		{a: `[1, [2, {}]]`, b: `[1,[2,{}]]`, equal: true, ordered: true},
+		{a: `[1, 2]`, b: `[2, 1]`},
+		{a: `[1, 2]`, b: `[1, 2, 3]`},
+		{a: `[1, 2, 3]`, b: `[1, 2]`},
+		{a: `{"a": 1, "b": [true]}`, b: `{"b": [true], "a": 1.0}`, equal: true},
+		{a: `{"a": 1, "b": 2}`, b: `{"a": 1, "b": 2}`, equal: true, ordered: true},
+		{a: `{"a": {"x": 1, "y": 2}}`, b: `{"a": {"y": 2, "x": 1}}`, equal: true},
+		{a: `{"a": 1}`, b: `{"a": 1, "b": 2}`},
+		{a: `{"a": 1, "b": 2}`, b: `{"a": 1}`},
+		{a: `{"a": 1}`, b: `{"b": 1}`},
+		{a: `{"a": 1}`, b: `{"a": 1}`, equal: true, ordered: true},
+		{a: `{"a": 1, "a": 2}`, b: `{"a": 2}`, equal: true},
+		{a: `{}`, b: `[]`},
+		{a: `[1`, b: `[1`},
+		{a: `1 2`, b: `1`},
+		{a: `1`, b: `1 2`},
+		{a: ``, b: ``},
+	}
+
+	for i, c := range cases {
+		if got := Equal([]byte(c.a), []byte(c.b)); got != c.equal {
+			t.Errorf("%d: Equal(%s, %s): want %t, got %t", i, c.a, c.b, c.equal, got)
+		}
+		if got := EqualOrdered([]byte(c.a), []byte(c.b)); got != c.ordered {
+			t.Errorf("%d: EqualOrdered(%s, %s): want %t, got %t", i, c.a, c.b, c.ordered, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package jsonb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DepthLimitError is returned when the nesting depth of the document exceeds
+// the limit set with WithMaxDepth.
+type DepthLimitError struct {
+	Limit int
+}
+
+func (e *DepthLimitError) Error() string {
+	return fmt.Sprintf("jsonb: exceeded maximum nesting depth of %d", e.Limit)
+}
+
+// StringLenError is returned when a string token exceeds the limit set with
+// WithMaxStringLen.
+type StringLenError struct {
+	Limit int
+	Len   int
+}
+
+func (e *StringLenError) Error() string {
+	return fmt.Sprintf("jsonb: string of %d bytes exceeds maximum length of %d", e.Len, e.Limit)
+}
+
+// NumberLenError is returned when a number token exceeds the limit set with
+// WithMaxNumberLen.
+type NumberLenError struct {
+	Limit int
+	Len   int
+}
+
+func (e *NumberLenError) Error() string {
+	return fmt.Sprintf("jsonb: number of %d bytes exceeds maximum length of %d", e.Len, e.Limit)
+}
+
+// TokenLimitError is returned when the document exceeds the number of tokens
+// set with WithMaxTokens.
+type TokenLimitError struct {
+	Limit int64
+}
+
+func (e *TokenLimitError) Error() string {
+	return fmt.Sprintf("jsonb: exceeded maximum number of %d tokens", e.Limit)
+}
+
+// DuplicateKeyError is returned when an object has the same key more than
+// once and duplicate keys are rejected.
+type DuplicateKeyError struct {
+	Key    string // the decoded key
+	Offset int64  // byte offset of the duplicate key
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("jsonb: duplicate object key %q at offset %d", e.Key, e.Offset)
+}
+
+// KeyValidationError is returned when an object key is rejected by the
+// function set with WithKeyValidator.
+type KeyValidationError struct {
+	Key    string // the decoded key
+	Offset int64  // byte offset of the key
+	Cause  error  // the error returned by the validator
+}
+
+func (e *KeyValidationError) Error() string {
+	return fmt.Sprintf("jsonb: invalid object key %q at offset %d: %v", e.Key, e.Offset, e.Cause)
+}
+
+func (e *KeyValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// SurrogatePairError is returned when a \u escape of a UTF-16 surrogate is
+// not part of a valid pair and strict surrogates are required.
+type SurrogatePairError struct {
+	Rune   rune  // the unpaired surrogate
+	Offset int64 // byte offset of its escape sequence
+}
+
+func (e *SurrogatePairError) Error() string {
+	return fmt.Sprintf("jsonb: unpaired surrogate \\u%04x at offset %d", e.Rune, e.Offset)
+}
+
+// InvalidCommentError is returned when comments are allowed and a comment
+// is invalid or unterminated.
+type InvalidCommentError struct {
+	Offset       int64 // byte offset of the start of the comment
+	Unterminated bool  // the end of input was reached in a block comment
+}
+
+func (e *InvalidCommentError) Error() string {
+	if e.Unterminated {
+		return fmt.Sprintf("jsonb: unterminated comment at offset %d", e.Offset)
+	}
+	return fmt.Sprintf("jsonb: invalid comment at offset %d", e.Offset)
+}
+
+// NumberSyntaxError is returned by ParseNumber and ParseInt when the bytes
+// are not a valid JSON number.
+type NumberSyntaxError struct {
+	Num string
+}
+
+func (e *NumberSyntaxError) Error() string {
+	return fmt.Sprintf("jsonb: invalid number %q", e.Num)
+}
+
+// MultiError is a list of errors, such as the errors collected by a parser
+// created with WithCollectErrors.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors of the list, for errors.Is and errors.As.
+func (e MultiError) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,33 @@
+package jsonb
+
+import (
+	"io"
+	"os"
+)
+
+// ParseFile returns a parser that reads from the named file. The file is
+// closed when the input is exhausted or when Close is called on the parser.
+// If the file cannot be opened, the parser returns no token and Err returns
+// the error.
+func ParseFile(name string, opts ...ParserOption) *Parser {
+	f, err := os.Open(name)
+	if err != nil {
+		p := newParser(opts)
+		p.err = err
+		return p
+	}
+	p := NewParserOptions(f, opts...)
+	p.autoClose = true
+	return p
+}
+
+// NewParserReadCloser returns a parser that reads from rc, such as the body
+// of an http.Response. Like for ParseFile, rc is closed when the input is
+// exhausted or when Close is called on the parser, whichever comes first,
+// and it is closed only once. If parsing stops before the end of the input,
+// for example on a syntax error, Close must be called to close rc.
+func NewParserReadCloser(rc io.ReadCloser, opts ...ParserOption) *Parser {
+	p := NewParserOptions(rc, opts...)
+	p.autoClose = true
+	return p
+}
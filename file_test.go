@@ -0,0 +1,112 @@
+package jsonb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(name, []byte(`{"a": [1, true]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := ParseFile(name)
+	f := p.closer.(*os.File)
+	var toks []Token
+	for p.Next() {
+		toks = append(toks, p.Token())
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != 7 {
+		t.Errorf("want 7 tokens, got %v", toks)
+	}
+
+	// the file is closed once exhausted
+	if p.closer != nil {
+		t.Error("want closer to be cleared")
+	}
+	if err := f.Close(); !isClosedErr(err) {
+		t.Errorf("want file to be closed, got %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("want no error closing twice, got %v", err)
+	}
+
+	// closed before the end of the input
+	p = ParseFile(name)
+	f = p.closer.(*os.File)
+	if !p.Next() {
+		t.Fatal(p.Err())
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); !isClosedErr(err) {
+		t.Errorf("want file to be closed, got %v", err)
+	}
+
+	// closed by Reset
+	p = ParseFile(name)
+	f = p.closer.(*os.File)
+	p.ResetString(`1`)
+	if err := f.Close(); !isClosedErr(err) {
+		t.Errorf("want file to be closed by Reset, got %v", err)
+	}
+
+	// missing file
+	p = ParseFile(filepath.Join(t.TempDir(), "missing.json"))
+	if p.Next() {
+		t.Errorf("want no token, got %v", p.Token())
+	}
+	if err := p.Err(); !os.IsNotExist(err) {
+		t.Errorf("want not exist error, got %v", err)
+	}
+}
+
+// isClosedErr returns true if err is the error returned when closing an
+// already closed file.
+func isClosedErr(err error) bool {
+	pe, ok := err.(*os.PathError)
+	return ok && pe.Err == os.ErrClosed
+}
+
+func TestNewParserReadCloser(t *testing.T) {
+	// closed once exhausted
+	rc := &closeCounter{Reader: strings.NewReader(`{"a": 1}`)}
+	p := NewParserReadCloser(rc)
+	for p.Next() {
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once exhausted, got %d calls to Close", rc.n)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once, got %d calls to Close", rc.n)
+	}
+
+	// closed by Close before the end of the input
+	rc = &closeCounter{Reader: strings.NewReader(`{"a": 1}`)}
+	p = NewParserReadCloser(rc)
+	if !p.Next() {
+		t.Fatal(p.Err())
+	}
+	if rc.n != 0 {
+		t.Errorf("want reader to be open, got %d calls to Close", rc.n)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once, got %d calls to Close", rc.n)
+	}
+}
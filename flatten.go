@@ -0,0 +1,222 @@
+package jsonb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrFlatKeyConflict is returned by Unflatten when a key addresses a
+	// value inside another value, such as "a.b" with "a".
+	ErrFlatKeyConflict = errors.New("jsonb: conflicting flattened keys")
+
+	errEmptySeparator = errors.New("jsonb: empty separator")
+)
+
+// Flatten returns a JSON object with one member per value of the JSON
+// object or array data that is not a non-empty array or object, in order.
+// The key of each member is the path of the value, with its object keys and
+// array indices joined by sep, so {"a":{"b":1},"c":[2,3]} is flattened to
+// {"a.b":1,"c.0":2,"c.1":3} with "." as separator. Empty arrays and objects
+// are kept as values. The occurrences of sep and of the backslash in the
+// object keys are escaped with a backslash. The values are written
+// verbatim. It returns ErrNotObject if data is neither an object nor an
+// array.
+func Flatten(data []byte, sep string) ([]byte, error) {
+	if sep == "" {
+		return nil, errEmptySeparator
+	}
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	if p.tok != ObjectStart && p.tok != ArrayStart {
+		return nil, ErrNotObject
+	}
+
+	var b Builder
+	b.BeginObject()
+	for {
+		switch p.tok {
+		case ArrayStart, ObjectStart:
+			if next := p.Peek(); next == ArrayEnd || next == ObjectEnd {
+				if len(p.stack) > 1 {
+					b.Key(flatKey(p.Path(), sep))
+					if p.tok == ArrayStart {
+						b.rawValue("Raw", []byte("[]"))
+					} else {
+						b.rawValue("Raw", []byte("{}"))
+					}
+				}
+				p.Next()
+			}
+		case ObjectKey, ArrayEnd, ObjectEnd:
+		default:
+			b.Key(flatKey(p.Path(), sep))
+			b.rawValue("Raw", p.buf.Bytes())
+		}
+
+		if p.IsAtRoot() {
+			break
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	b.EndObject()
+	return b.Bytes(), nil
+}
+
+// flatKey returns the segments of path escaped and joined by sep.
+func flatKey(path []string, sep string) string {
+	var sb strings.Builder
+	for i, seg := range path {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		for len(seg) > 0 {
+			switch {
+			case seg[0] == '\\':
+				sb.WriteString(`\\`)
+				seg = seg[1:]
+			case strings.HasPrefix(seg, sep):
+				sb.WriteByte('\\')
+				sb.WriteString(sep)
+				seg = seg[len(sep):]
+			default:
+				sb.WriteByte(seg[0])
+				seg = seg[1:]
+			}
+		}
+	}
+	return sb.String()
+}
+
+// splitFlatKey returns the unescaped segments of the flattened key.
+func splitFlatKey(key, sep string) []string {
+	var segs []string
+	var sb strings.Builder
+	for len(key) > 0 {
+		switch {
+		case key[0] == '\\' && len(key) > 1:
+			key = key[1:]
+			n := 1
+			if strings.HasPrefix(key, sep) {
+				n = len(sep)
+			}
+			sb.WriteString(key[:n])
+			key = key[n:]
+		case strings.HasPrefix(key, sep):
+			segs = append(segs, sb.String())
+			sb.Reset()
+			key = key[len(sep):]
+		default:
+			sb.WriteByte(key[0])
+			key = key[1:]
+		}
+	}
+	return append(segs, sb.String())
+}
+
+// Unflatten reverses Flatten, returning the JSON object made of the values
+// of the JSON object flat addressed by the keys of its members. Objects
+// whose keys are exactly the indices 0 to n-1 are returned as arrays, so
+// {"0":"a"} is unflattened to ["a"]. The values are written in compact
+// form. It returns ErrNotObject if flat is not an object, and
+// ErrFlatKeyConflict if a key addresses a value inside another value.
+func Unflatten(flat []byte, sep string) ([]byte, error) {
+	if sep == "" {
+		return nil, errEmptySeparator
+	}
+	p := NewParserBytes(flat)
+	root := new(flatNode)
+	err := ReadObject(p, func(key []byte) error {
+		raw, err := ReadRaw(p)
+		if err != nil {
+			return err
+		}
+		return root.insert(splitFlatKey(string(key), sep), raw)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// only whitespace may follow the object
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	var b Builder
+	root.write(&b)
+	return b.Bytes(), nil
+}
+
+// flatNode is a value being rebuilt by Unflatten.
+type flatNode struct {
+	raw   []byte               // raw bytes of a leaf value
+	keys  []string             // keys of the children, in order
+	elems map[string]*flatNode // children of an array or object
+}
+
+// insert inserts the leaf value raw at path.
+func (n *flatNode) insert(path []string, raw []byte) error {
+	for _, seg := range path {
+		if n.raw != nil {
+			return ErrFlatKeyConflict
+		}
+		child := n.elems[seg]
+		if child == nil {
+			if n.elems == nil {
+				n.elems = make(map[string]*flatNode)
+			}
+			child = new(flatNode)
+			n.elems[seg] = child
+			n.keys = append(n.keys, seg)
+		}
+		n = child
+	}
+	if n.raw != nil || n.elems != nil {
+		return ErrFlatKeyConflict
+	}
+	n.raw = raw
+	return nil
+}
+
+// isArray returns true if the keys of n are the indices 0 to n-1.
+func (n *flatNode) isArray() bool {
+	for i := range n.keys {
+		if n.elems[strconv.Itoa(i)] == nil {
+			return false
+		}
+	}
+	return len(n.keys) > 0
+}
+
+// write writes n to b.
+func (n *flatNode) write(b *Builder) {
+	switch {
+	case n.raw != nil:
+		b.rawValue("Raw", n.raw)
+	case n.isArray():
+		b.BeginArray()
+		for i := range n.keys {
+			n.elems[strconv.Itoa(i)].write(b)
+		}
+		b.EndArray()
+	default:
+		b.BeginObject()
+		for _, k := range n.keys {
+			b.Key(k)
+			n.elems[k].write(b)
+		}
+		b.EndObject()
+	}
+}
@@ -0,0 +1,77 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	cases := []struct {
+		in   string
+		sep  string
+		flat string
+		out  string // result of Unflatten, if different from in
+		err  error
+	}{
+		{in: `{}`, sep: ".", flat: `{}`},
+		{in: `{"a":{"b":1},"c":[2,3]}`, sep: ".", flat: `{"a.b":1,"c.0":2,"c.1":3}`},
+		{in: `{"a":{"b":{"c":"d"}},"e":[{"f":null},[true]]}`, sep: "/", flat: `{"a/b/c":"d","e/0/f":null,"e/1/0":true}`},
+		{in: `{"a":{},"b":[],"c":{"d":[]}}`, sep: ".", flat: `{"a":{},"b":[],"c.d":[]}`},
+		{in: `{"a.b":{"c\\d":1},"":2}`, sep: ".", flat: `{"a\\.b.c\\\\d":1,"":2}`},
+		{in: `{"a":1}`, sep: "::", flat: `{"a":1}`},
+		{in: `{"a":{"b::c":1}}`, sep: "::", flat: `{"a::b\\::c":1}`},
+		{in: `["a",{"b":1.50}]`, sep: ".", flat: `{"0":"a","1.b":1.50}`},
+		{in: `{"x": [ 1 ]}`, sep: ".", flat: `{"x.0":1}`, out: `{"x":[1]}`},
+		{in: `{"0":"a"}`, sep: ".", flat: `{"0":"a"}`, out: `["a"]`},
+		{in: `1`, sep: ".", err: ErrNotObject},
+		{in: `{"a": [1`, sep: ".", err: io.ErrUnexpectedEOF},
+		{in: `{}`, sep: "", err: errEmptySeparator},
+	}
+
+	for i, c := range cases {
+		flat, err := Flatten([]byte(c.in), c.sep)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(flat) != c.flat {
+			t.Errorf("%d: want %s, got %s", i, c.flat, flat)
+		}
+		if c.err != nil {
+			continue
+		}
+
+		out, err := Unflatten(flat, c.sep)
+		if err != nil {
+			t.Errorf("%d: Unflatten: %v", i, err)
+			continue
+		}
+		want := c.out
+		if want == "" {
+			want = c.in
+		}
+		if string(out) != want {
+			t.Errorf("%d: Unflatten: want %s, got %s", i, want, out)
+		}
+	}
+}
+
+func TestUnflattenInvalid(t *testing.T) {
+	cases := []struct {
+		in  string
+		err error
+	}{
+		{in: `{"a": 1, "a.b": 2}`, err: ErrFlatKeyConflict},
+		{in: `{"a.b": 1, "a": 2}`, err: ErrFlatKeyConflict},
+		{in: `{"a": 1, "a": 2}`, err: ErrFlatKeyConflict},
+		{in: `[1]`, err: ErrNotObject},
+		{in: `{"a": 1`, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		if _, err := Unflatten([]byte(c.in), "."); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+	}
+}
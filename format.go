@@ -0,0 +1,127 @@
+package jsonb
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Format returns the JSON document src indented with one element per line.
+// Each line after the first starts with prefix followed by one copy of
+// indent per level of nesting, and the colon after an object key is followed
+// by a space. Empty arrays and objects are written as [] and {}. If indent
+// is empty, the document is returned in compact form, as with Compact.
+func Format(src []byte, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if indent == "" {
+		if err := Compact(&buf, src); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if err := formatTokens(NewParserBytes(src), &buf, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Reformat is like Format, but it reads the JSON document from src and
+// writes the result to dst as it parses the document, without holding the
+// document in memory. If the document is invalid, what was written to dst
+// up to the error is undefined.
+func Reformat(src io.Reader, dst io.Writer, prefix, indent string) error {
+	w := bufio.NewWriter(dst)
+	if err := formatTokens(NewParser(src), w, prefix, indent); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// tokenWriter is implemented by bytes.Buffer and bufio.Writer.
+type tokenWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// formatTokens writes the document read by p to w, indented as described
+// by Format, or in compact form if indent is empty.
+func formatTokens(p *Parser, w tokenWriter, prefix, indent string) error {
+	if err := p.nextToken(); err != nil {
+		return err
+	}
+
+	newline := func(depth int) {
+		if indent == "" {
+			return
+		}
+		w.WriteByte('\n')
+		w.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			w.WriteString(indent)
+		}
+	}
+	colon := ": "
+	if indent == "" {
+		colon = ":"
+	}
+
+	depth := 0
+	prev := Invalid
+	for {
+		switch {
+		case p.tok == ArrayEnd || p.tok == ObjectEnd:
+			depth--
+			if prev != ArrayStart && prev != ObjectStart {
+				newline(depth)
+			}
+		case prev == ObjectKey:
+			w.WriteString(colon)
+		case prev == ArrayStart || prev == ObjectStart:
+			newline(depth)
+		case prev != Invalid:
+			w.WriteByte(',')
+			newline(depth)
+		}
+		w.Write(p.buf.Bytes())
+
+		if p.tok == ArrayStart || p.tok == ObjectStart {
+			depth++
+		}
+		if depth == 0 {
+			break
+		}
+		prev = p.tok
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	return p.Err()
+}
+
+// Compact appends the JSON document src to dst without insignificant
+// whitespace. Strings and numbers are written verbatim, with their escape
+// sequences untouched. If src is invalid, dst is left unchanged and the
+// error is returned.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	n := dst.Len()
+	p := NewParserBytes(src)
+	if err := p.nextToken(); err != nil {
+		return err
+	}
+	b, err := p.appendValue(dst.AvailableBuffer())
+	if err == nil {
+		dst.Write(b)
+
+		// only whitespace may follow the value
+		p.Next()
+		err = p.Err()
+	}
+	if err != nil {
+		dst.Truncate(n)
+	}
+	return err
+}
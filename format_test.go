@@ -0,0 +1,120 @@
+package jsonb
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		in     string
+		prefix string
+		indent string
+		out    string
+		err    error
+	}{
+		{in: ` 1 `, indent: "  ", out: `1`},
+		{in: `[]`, indent: "  ", out: `[]`},
+		{in: ` { } `, indent: "  ", out: `{}`},
+		{in: `[1,"a\n\u00e9\"",true]`, indent: "\t", out: "[\n\t1,\n\t\"a\\n\\u00e9\\\"\",\n\ttrue\n]"},
+		{in: `{"a":[1,{"b":null,"c":[]},{}],"d":{"e":false}}`, indent: "  ",
+			out: "{\n  \"a\": [\n    1,\n    {\n      \"b\": null,\n      \"c\": []\n    },\n    {}\n  ],\n  \"d\": {\n    \"e\": false\n  }\n}"},
+		{in: `{"a": [1]}`, prefix: "> ", indent: "-", out: "{\n> -\"a\": [\n> --1\n> -]\n> }"},
+		{in: "{\n  \"a\" : [ 1 , 2 ] ,\n  \"b\" : \"c d\"\n}", out: `{"a":[1,2],"b":"c d"}`},
+		{in: `[1, 2`, indent: "  ", err: io.ErrUnexpectedEOF},
+		{in: `[1, 2] 3`, indent: "  ", err: &SyntaxError{Char: '3', Line: 1, Column: 8, Offset: 7, Near: []byte(`[1, 2] 3`), typ: endLit}},
+		{in: `[1, 2] 3`, err: &SyntaxError{Char: '3', Line: 1, Column: 8, Offset: 7, Near: []byte(`[1, 2] 3`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		out, err := Format([]byte(c.in), c.prefix, c.indent)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %q, got %q", i, c.out, out)
+		}
+
+		var buf bytes.Buffer
+		err = Reformat(strings.NewReader(c.in), &buf, c.prefix, c.indent)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: Reformat: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if c.err == nil && buf.String() != c.out {
+			t.Errorf("%d: Reformat: want %q, got %q", i, c.out, buf.String())
+		}
+
+		buf.Reset()
+		err = TranscodeReformat(strings.NewReader(c.in), &buf, c.prefix, c.indent)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: TranscodeReformat: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if c.err == nil && buf.String() != c.out {
+			t.Errorf("%d: TranscodeReformat: want %q, got %q", i, c.out, buf.String())
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{in: ` 1 `, out: `1`},
+		{in: "[ ]", out: `[]`},
+		{in: " {\n\t\"a b\" : [ 1 , \"\\u0020 \\n\" , { } ] , \"c\" : null\r\n} ", out: `{"a b":[1,"\u0020 \n",{}],"c":null}`},
+		{in: `{"a": [1, 2}`, err: &SyntaxError{Char: '}', Line: 1, Column: 12, Offset: 11, Near: []byte(`{"a": [1, 2}`), typ: begVal}},
+		{in: `[1] [2]`, err: &SyntaxError{Char: '[', Line: 1, Column: 5, Offset: 4, Near: []byte(`[1] [2]`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		var buf bytes.Buffer
+		buf.WriteString("prefix")
+		err := Compact(&buf, []byte(c.in))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if got := buf.String(); got != "prefix"+c.out {
+			t.Errorf("%d: want %q, got %q", i, "prefix"+c.out, got)
+		}
+	}
+}
+
+func TestFormatCompactRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"a":[1,{"b":null,"c":[]},{}],"d":{"e":false,"f":"\"\\\/\u00e9"}}`,
+		strings.Repeat("[", 1000) + "1" + strings.Repeat("]", 1000),
+		string(jsonE1K),
+	}
+
+	for i, doc := range docs {
+		var want bytes.Buffer
+		if err := Compact(&want, []byte(doc)); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		formatted, err := Format([]byte(doc), "", "  ")
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		var got bytes.Buffer
+		if err := Compact(&got, formatted); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%d: round-trip changed the document", i)
+		}
+
+		// compacting is idempotent
+		var again bytes.Buffer
+		if err := Compact(&again, got.Bytes()); err != nil || again.String() != got.String() {
+			t.Errorf("%d: compacting a compact document changed it (%v)", i, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package jsonb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// NewGzipParser returns a parser that reads a gzip-compressed JSON document
+// from r. It returns an error if the gzip header is invalid. The gzip
+// reader is closed when the input is exhausted or when Close is called on
+// the parser, but r is not closed.
+func NewGzipParser(r io.Reader, opts ...ParserOption) (*Parser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	p := NewParserOptions(zr, opts...)
+	p.autoClose = true
+	return p, nil
+}
+
+// NewAutoGzipParser is like NewGzipParser, but it detects whether the input
+// is compressed by looking for the gzip magic number in its first two bytes,
+// and reads the input as plain JSON if it is not.
+func NewAutoGzipParser(r io.Reader, opts ...ParserOption) (*Parser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return NewGzipParser(br, opts...)
+	}
+	return NewParserOptions(br, opts...), nil
+}
@@ -0,0 +1,81 @@
+package jsonb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGzipParser(t *testing.T) {
+	const in = `{"a": ["b", 1, null]}`
+	want := []Token{ObjectStart, ObjectKey, ArrayStart, String, Number, Null, ArrayEnd, ObjectEnd}
+	zin := gzipBytes(t, in)
+
+	cases := []struct {
+		name string
+		new  func() (*Parser, error)
+		gz   bool
+	}{
+		{name: "gzip", new: func() (*Parser, error) { return NewGzipParser(bytes.NewReader(zin)) }, gz: true},
+		{name: "auto gzip", new: func() (*Parser, error) { return NewAutoGzipParser(bytes.NewReader(zin)) }, gz: true},
+		{name: "auto plain", new: func() (*Parser, error) { return NewAutoGzipParser(bytes.NewReader([]byte(in))) }},
+	}
+
+	for _, c := range cases {
+		p, err := c.new()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if (p.closer != nil) != c.gz {
+			t.Errorf("%s: want closer %t", c.name, c.gz)
+		}
+
+		var got []Token
+		for p.Next() {
+			got = append(got, p.Token())
+		}
+		if err := p.Err(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want %v, got %v", c.name, want, got)
+		}
+		if p.closer != nil {
+			t.Errorf("%s: want closer to be cleared once exhausted", c.name)
+		}
+	}
+}
+
+func TestGzipParserInvalid(t *testing.T) {
+	if _, err := NewGzipParser(bytes.NewReader([]byte(`{"a": "not compressed"}`))); err != gzip.ErrHeader {
+		t.Errorf("want %v, got %v", gzip.ErrHeader, err)
+	}
+
+	// short inputs are not compressed
+	for _, in := range []string{"", "1"} {
+		p, err := NewAutoGzipParser(bytes.NewReader([]byte(in)))
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		for p.Next() {
+		}
+		if in == "1" && (p.Token() != Number || p.Err() != nil) {
+			t.Errorf("%q: want number, got %v (%v)", in, p.Token(), p.Err())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package jsonb
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"sort"
+)
+
+// Hash returns a 64-bit FNV-1a hash of the JSON document data that ignores
+// insignificant whitespace and the order of the members of objects, and
+// where numbers are hashed by their exact decimal value, so that 1.0 and 1
+// have the same hash. For objects with duplicate keys, only the last member
+// of each key is hashed. Strings are hashed verbatim, with their escape
+// sequences, see HashNormalized. Documents that are equal as reported by
+// Equal have the same hash, provided their strings are escaped identically.
+//
+// The hash is not cryptographic and must not be used to protect against
+// malicious inputs.
+func Hash(data []byte) (uint64, error) {
+	return hashDocument(data, false)
+}
+
+// HashNormalized is like Hash, but the strings are decoded before being
+// hashed, so that "\u00e9" and "é" have the same hash. Documents that are
+// equal as reported by Equal always have the same hash.
+func HashNormalized(data []byte) (uint64, error) {
+	return hashDocument(data, true)
+}
+
+// hashDocument returns the hash of the JSON document data, decoding its
+// strings if normalize is true.
+func hashDocument(data []byte, normalize bool) (uint64, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	if _, err := root.hash(h, nil, normalize); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+// hash writes an unambiguous representation of n to h, using buf as scratch
+// buffer.
+func (n *Node) hash(h hash.Hash64, buf []byte, normalize bool) ([]byte, error) {
+	var err error
+	switch n.tok {
+	case ArrayStart:
+		h.Write([]byte{'['})
+		for _, elem := range n.elems {
+			if buf, err = elem.hash(h, buf, normalize); err != nil {
+				return nil, err
+			}
+		}
+		h.Write([]byte{']'})
+
+	case ObjectStart:
+		order := make([]int, len(n.keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return n.keys[order[i]] < n.keys[order[j]]
+		})
+
+		h.Write([]byte{'{'})
+		for i, ix := range order {
+			if i+1 < len(order) && n.keys[order[i+1]] == n.keys[ix] {
+				// only the last member of duplicate keys
+				continue
+			}
+			buf = appendHashString(buf[:0], n.keys[ix])
+			h.Write(buf)
+			if buf, err = n.elems[ix].hash(h, buf, normalize); err != nil {
+				return nil, err
+			}
+		}
+		h.Write([]byte{'}'})
+
+	case String:
+		raw := n.raw
+		if normalize {
+			s, err := appendUnquote(nil, n.raw)
+			if err != nil {
+				return nil, err
+			}
+			raw = s
+		}
+		buf = appendHashString(buf[:0], unsafeString(raw))
+		h.Write(buf)
+
+	case Number:
+		d := parseDecimal(buf[:0], n.raw)
+		buf = append(d.digits, 'd')
+		if d.neg {
+			buf = append(buf, '-')
+		}
+		buf = binary.BigEndian.AppendUint64(buf, uint64(d.exp))
+		h.Write(buf)
+
+	default:
+		// null, true or false
+		h.Write(n.raw)
+	}
+	return buf, nil
+}
+
+// appendHashString appends s to dst, prefixed by its length.
+func appendHashString(dst []byte, s string) []byte {
+	dst = append(dst, 's')
+	dst = binary.BigEndian.AppendUint64(dst, uint64(len(s)))
+	return append(dst, s...)
+}
@@ -0,0 +1,61 @@
+package jsonb
+
+import (
+	"io"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	cases := []struct {
+		a, b       string
+		same       bool
+		normalized bool
+	}{
+		{a: `null`, b: ` null `, same: true, normalized: true},
+		{a: `null`, b: `false`},
+		{a: `1`, b: `1.0`, same: true, normalized: true},
+		{a: `1e1`, b: `10`, same: true, normalized: true},
+		{a: `-0`, b: `0`, same: true, normalized: true},
+		{a: `-1`, b: `1`},
+		{a: `1`, b: `"1"`},
+		{a: `"é"`, b: `"\u00e9"`, normalized: true},
+		{a: `"ab"`, b: `"ab"`, same: true, normalized: true},
+		{a: `["a", "b"]`, b: `["ab"]`},
+		{a: `["a", "b"]`, b: `["b", "a"]`},
+		{a: `[[]]`, b: `[]`},
+		{a: `{"a": 1, "b": [true, null]}`, b: `{"b": [true, null], "a": 1}`, same: true, normalized: true},
+		{a: `{"a": 1, "a": 2}`, b: `{"a": 2}`, same: true, normalized: true},
+		{a: `{"a": "b"}`, b: `{"ab": ""}`},
+		{a: `{}`, b: `[]`},
+	}
+
+	for i, c := range cases {
+		ha, err := Hash([]byte(c.a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hb, err := Hash([]byte(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (ha == hb) != c.same {
+			t.Errorf("%d: Hash(%s, %s): want same %t, got %x and %x", i, c.a, c.b, c.same, ha, hb)
+		}
+
+		ha, err = HashNormalized([]byte(c.a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hb, err = HashNormalized([]byte(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (ha == hb) != c.normalized {
+			t.Errorf("%d: HashNormalized(%s, %s): want same %t, got %x and %x", i, c.a, c.b, c.normalized, ha, hb)
+		}
+	}
+
+	if _, err := Hash([]byte(`[1`)); err != io.ErrUnexpectedEOF {
+		t.Errorf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
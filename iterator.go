@@ -0,0 +1,62 @@
+package jsonb
+
+// Iterator is an iterator over the tokens of a parser, with the token and
+// its bytes, to be used in a for range loop:
+//
+//	for tok, b := range NewIterator(p) {
+//		...
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+//
+// The bytes are only valid for the current iteration, as for Parser.Bytes.
+// Errors are reported by the Err method of the parser once the loop ends.
+type Iterator func(yield func(Token, []byte) bool)
+
+// NewIterator returns an iterator over the remaining tokens of p. The
+// iteration ends when p is exhausted or when the loop is exited.
+func NewIterator(p *Parser) Iterator {
+	return func(yield func(Token, []byte) bool) {
+		for p.Next() {
+			if !yield(p.tok, p.buf.Bytes()) {
+				return
+			}
+		}
+	}
+}
+
+// ObjectIterator returns an iterator over the tokens of the members of an
+// object, excluding its ObjectStart and ObjectEnd tokens. If p is not
+// positioned on an ObjectStart token, it is first advanced to the next
+// token, which must be an ObjectStart, otherwise the iteration stops
+// immediately and Err returns ErrNotObject. If the loop runs to completion,
+// p is positioned on the ObjectEnd token.
+func ObjectIterator(p *Parser) Iterator {
+	return containerIterator(p, ObjectStart)
+}
+
+// ArrayIterator is like ObjectIterator, but it iterates over the tokens of
+// the elements of an array, and Err returns ErrNotArray if the value is not
+// an array.
+func ArrayIterator(p *Parser) Iterator {
+	return containerIterator(p, ArrayStart)
+}
+
+// containerIterator returns an iterator over the tokens inside the array or
+// object that starts with the token start.
+func containerIterator(p *Parser, start Token) Iterator {
+	return func(yield func(Token, []byte) bool) {
+		if err := p.startValue(start); err != nil {
+			p.error(err)
+			return
+		}
+
+		depth := len(p.stack)
+		for p.Next() && p.tok != Invalid && len(p.stack) >= depth {
+			if !yield(p.tok, p.buf.Bytes()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	p := NewParserString(`{"a": [1, true]}`)
+	var toks []Token
+	var vals []string
+	for tok, b := range NewIterator(p) {
+		toks = append(toks, tok)
+		vals = append(vals, string(b))
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	wantToks := []Token{ObjectStart, ObjectKey, ArrayStart, Number, True, ArrayEnd, ObjectEnd}
+	wantVals := []string{`{`, `"a"`, `[`, `1`, `true`, `]`, `}`}
+	if !reflect.DeepEqual(wantToks, toks) || !reflect.DeepEqual(wantVals, vals) {
+		t.Errorf("want %v %q, got %v %q", wantToks, wantVals, toks, vals)
+	}
+
+	// exit the loop early
+	p = NewParserString(`[1, 2, 3]`)
+	n := 0
+	for tok := range NewIterator(p) {
+		n++
+		if tok == Number {
+			break
+		}
+	}
+	if n != 2 || !p.Next() || p.Token() != Number || string(p.Bytes()) != "2" {
+		t.Errorf("want to stop after the first number, got %d tokens", n)
+	}
+
+	// syntax error
+	p = NewParserString(`[1 2]`)
+	for range NewIterator(p) {
+	}
+	if p.Err() == nil {
+		t.Error("want syntax error")
+	}
+}
+
+func TestContainerIterator(t *testing.T) {
+	cases := []struct {
+		in   string
+		arr  bool
+		toks []Token
+		err  error
+	}{
+		{in: `{"a": [1], "b": {}}`, toks: []Token{ObjectKey, ArrayStart, Number, ArrayEnd, ObjectKey, ObjectStart, ObjectEnd}},
+		{in: `{}`},
+		{in: `[1, {"a": null}]`, arr: true, toks: []Token{Number, ObjectStart, ObjectKey, Null, ObjectEnd}},
+		{in: `[]`, arr: true},
+		{in: `[1]`, err: ErrNotObject},
+		{in: `{}`, arr: true, err: ErrNotArray},
+		{in: ``, err: io.ErrUnexpectedEOF},
+		{in: `[1, 2`, arr: true, toks: []Token{Number, Number}, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		p := NewParserString(c.in)
+		it := ObjectIterator(p)
+		end := ObjectEnd
+		if c.arr {
+			it, end = ArrayIterator(p), ArrayEnd
+		}
+
+		var toks []Token
+		for tok := range it {
+			toks = append(toks, tok)
+		}
+		if !reflect.DeepEqual(c.err, p.Err()) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, p.Err())
+			continue
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d: want %v, got %v", i, c.toks, toks)
+		}
+		if c.err == nil && p.Token() != end {
+			t.Errorf("%d: want parser on %s, got %s", i, end, p.Token())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package jsonb
+
+// Keys returns the decoded keys of the members of the JSON object data, in
+// order and including duplicates. The values are skipped without being
+// decoded. It returns ErrNotObject if data is not an object.
+func Keys(data []byte) ([]string, error) {
+	p := NewParserBytes(data)
+	var keys []string
+	err := ReadObject(p, func(key []byte) error {
+		keys = append(keys, string(key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// only whitespace may follow the object
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// AllKeys is like Keys, but it returns the keys of all the objects of the
+// JSON document data, including the nested ones, indexed by the JSON
+// pointer of each object. The root object has the empty pointer.
+func AllKeys(data []byte) (map[string][]string, error) {
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	if p.tok != ObjectStart {
+		return nil, ErrNotObject
+	}
+
+	all := make(map[string][]string)
+	for {
+		switch p.tok {
+		case ObjectStart:
+			ptr := formatPointer(p.Path())
+			if _, ok := all[ptr]; !ok {
+				all[ptr] = []string{}
+			}
+		case ObjectKey:
+			path := p.Path()
+			ptr := formatPointer(path[:len(path)-1])
+			all[ptr] = append(all[ptr], path[len(path)-1])
+		}
+		if p.IsAtRoot() && p.tok == ObjectEnd {
+			break
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	// only whitespace may follow the object
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
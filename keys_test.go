@@ -0,0 +1,57 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		in   string
+		keys []string
+		err  error
+	}{
+		{in: `{}`},
+		{in: `{"a": 1, "bé": {"c": [2]}, "a": null}`, keys: []string{"a", "bé", "a"}},
+		{in: `[1]`, err: ErrNotObject},
+		{in: `{"a": 1`, err: io.ErrUnexpectedEOF},
+		{in: `{"a": 1} 2`, err: &SyntaxError{Char: '2', Line: 1, Column: 10, Offset: 9, Near: []byte(`{"a": 1} 2`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		keys, err := Keys([]byte(c.in))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if !reflect.DeepEqual(c.keys, keys) {
+			t.Errorf("%d: want %q, got %q", i, c.keys, keys)
+		}
+	}
+}
+
+func TestAllKeys(t *testing.T) {
+	const in = `{"a": {"x": 1, "y": {}}, "b": [{"c": 2}, [{"d/~": {"e": null}}]], "f": true}`
+	want := map[string][]string{
+		"":             {"a", "b", "f"},
+		"/a":           {"x", "y"},
+		"/a/y":         {},
+		"/b/0":         {"c"},
+		"/b/1/0":       {"d/~"},
+		"/b/1/0/d~1~0": {"e"},
+	}
+	got, err := AllKeys([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if _, err := AllKeys([]byte(`"a"`)); err != ErrNotObject {
+		t.Errorf("want %v, got %v", ErrNotObject, err)
+	}
+	if _, err := AllKeys([]byte(`{"a": {}`)); err != io.ErrUnexpectedEOF {
+		t.Errorf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
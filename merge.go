@@ -0,0 +1,169 @@
+package jsonb
+
+import (
+	"bytes"
+	"sort"
+)
+
+// member is a member of an object, with the raw bytes of its value.
+type member struct {
+	key string
+	raw []byte
+}
+
+// MergePatch applies the JSON merge patch to the JSON document target, as
+// defined by RFC 7396, and returns the resulting document in compact form.
+// The members of the merged objects are written in sorted order.
+//
+// Both documents are parsed one object at a time, collecting the members of
+// the object as slices of the input, and the merged objects are written
+// directly to the result without building the documents in memory.
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := mergeValue(&buf, target, patch, true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Merge deep-merges the JSON document overlay into the JSON document base
+// and returns the resulting document in compact form. The members of the
+// objects of overlay are merged recursively into the objects of base, and
+// the other values of overlay, including arrays and null, replace those of
+// base. Unlike MergePatch, a null value does not delete the member. If
+// overlay is not an object, it replaces base. The members of the merged
+// objects are written in sorted order.
+func Merge(base, overlay []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := mergeValue(&buf, base, overlay, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeValue writes the result of merging patch into target to buf. The
+// target may be nil if it does not exist. If deleteNulls is true, the null
+// members of patch delete the members of target, as for MergePatch.
+func mergeValue(buf *bytes.Buffer, target, patch []byte, deleteNulls bool) error {
+	pm, ok, err := objectMembers(patch)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// a patch that is not an object replaces the target
+		return Compact(buf, patch)
+	}
+	var tm []member
+	if target != nil {
+		if tm, _, err = objectMembers(target); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('{')
+	first := true
+	writeKey := func(key string) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(appendQuote(buf.AvailableBuffer(), key))
+		buf.WriteByte(':')
+	}
+
+	i, j := 0, 0
+	for i < len(tm) || j < len(pm) {
+		switch {
+		case j == len(pm) || i < len(tm) && tm[i].key < pm[j].key:
+			writeKey(tm[i].key)
+			if err := Compact(buf, tm[i].raw); err != nil {
+				return err
+			}
+			i++
+
+		case i == len(tm) || pm[j].key < tm[i].key:
+			if !deleteNulls || !IsNull(pm[j].raw) {
+				writeKey(pm[j].key)
+				if err := mergeValue(buf, nil, pm[j].raw, deleteNulls); err != nil {
+					return err
+				}
+			}
+			j++
+
+		default:
+			if !deleteNulls || !IsNull(pm[j].raw) {
+				writeKey(pm[j].key)
+				if err := mergeValue(buf, tm[i].raw, pm[j].raw, deleteNulls); err != nil {
+					return err
+				}
+			}
+			i++
+			j++
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// objectMembers returns the members of the JSON object data sorted by key,
+// keeping only the last member of duplicate keys. It returns false if data
+// is not an object.
+func objectMembers(data []byte) ([]member, bool, error) {
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, false, err
+	}
+	if p.tok != ObjectStart {
+		return nil, false, nil
+	}
+	members, err := readMembers(p, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// only whitespace may follow the object
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, false, err
+	}
+	return members, true, nil
+}
+
+// readMembers reads the members of the object of p, which must be
+// positioned on its ObjectStart token and read from data. The members are
+// returned sorted by key, keeping only the last member of duplicate keys.
+func readMembers(p *Parser, data []byte) ([]member, error) {
+	var members []member
+	for {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		if p.tok == ObjectEnd {
+			break
+		}
+		key, err := p.String()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		start := p.start
+		if !p.Skip() {
+			return nil, p.valueErr()
+		}
+		members = append(members, member{key: key, raw: data[start : p.start+int64(p.buf.Len())]})
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].key < members[j].key
+	})
+	dedup := members[:0]
+	for i, m := range members {
+		if i+1 < len(members) && members[i+1].key == m.key {
+			continue
+		}
+		dedup = append(dedup, m)
+	}
+	return dedup, nil
+}
@@ -0,0 +1,130 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// from the examples of RFC 7396, appendix A
+	cases := []struct {
+		target string
+		patch  string
+		out    string
+		err    error
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, out: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, out: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, out: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, out: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, out: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, out: `{"a":["b"]}`},
+		{target: `{"a": {"b": "c"}}`, patch: `{"a": {"b": "d", "c": null}}`, out: `{"a":{"b":"d"}}`},
+		{target: `{"a": [{"b":"c"}]}`, patch: `{"a": [1]}`, out: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, out: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, out: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, out: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, out: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, out: `{"a":1,"e":null}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, out: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a": {"bb": {"ccc": null}}}`, out: `{"a":{"bb":{}}}`},
+		// members are sorted and compacted
+		{target: `{"z": [1, 2], "b": {"y": 1, "x": 2}, "b\n": 1}`, patch: `{"c": 3}`, out: `{"b":{"y":1,"x":2},"b\n":1,"c":3,"z":[1,2]}`},
+		{target: `{"a": 1, "a": 2}`, patch: `{}`, out: `{"a":2}`},
+		{target: `{"a": 1`, patch: `{"b": 2}`, err: io.ErrUnexpectedEOF},
+		{target: `{"a": 1}`, patch: `{"b": }`, err: &SyntaxError{Char: '}', Line: 1, Column: 7, Offset: 6, Near: []byte(`{"b": }`), typ: begVal}},
+	}
+
+	for i, c := range cases {
+		out, err := MergePatch([]byte(c.target), []byte(c.patch))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		base    string
+		overlay string
+		out     string
+		err     error
+	}{
+		{base: `{"a": 1, "b": 2}`, overlay: `{"b": 3, "c": 4}`, out: `{"a":1,"b":3,"c":4}`},
+		{base: `{"a": {"b": 1, "c": {"d": 2}}}`, overlay: `{"a": {"c": {"e": 3}}}`, out: `{"a":{"b":1,"c":{"d":2,"e":3}}}`},
+		{base: `{"a": 1, "b": {"c": 2}}`, overlay: `{"a": null, "b": {"c": null}}`, out: `{"a":null,"b":{"c":null}}`},
+		{base: `{"a": [1, 2, 3]}`, overlay: `{"a": [4]}`, out: `{"a":[4]}`},
+		{base: `{"a": {"b": 1}}`, overlay: `{"a": [1]}`, out: `{"a":[1]}`},
+		{base: `{"a": 1}`, overlay: `{"a": {"b": null}}`, out: `{"a":{"b":null}}`},
+		{base: `{"a": 1}`, overlay: `{}`, out: `{"a":1}`},
+		{base: `[1, 2]`, overlay: `{"a": 1}`, out: `{"a":1}`},
+		{base: `{"a": 1}`, overlay: `[1, 2]`, out: `[1,2]`},
+		{base: `{"a": 1}`, overlay: `null`, out: `null`},
+		{base: `{"a": 1`, overlay: `{"b": 2}`, err: io.ErrUnexpectedEOF},
+		{base: `{"a": 1}`, overlay: `{"b": }`, err: &SyntaxError{Char: '}', Line: 1, Column: 7, Offset: 6, Near: []byte(`{"b": }`), typ: begVal}},
+	}
+
+	for i, c := range cases {
+		out, err := Merge([]byte(c.base), []byte(c.overlay))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+var (
+	mergeTarget = []byte(`{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"},
+		"tags": ["example", "sample"], "content": "This will be unchanged"}`)
+	mergePatch = []byte(`{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`)
+)
+
+func BenchmarkMergePatch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := MergePatch(mergeTarget, mergePatch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMergePatchStdlib(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var target, patch interface{}
+		if err := json.Unmarshal(mergeTarget, &target); err != nil {
+			b.Fatal(err)
+		}
+		if err := json.Unmarshal(mergePatch, &patch); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(mergeInterface(target, patch)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// mergeInterface merges patch into target as decoded by encoding/json.
+func mergeInterface(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]interface{})
+	if !ok {
+		tm = make(map[string]interface{})
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergeInterface(tm[k], v)
+		}
+	}
+	return tm
+}
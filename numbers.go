@@ -0,0 +1,563 @@
+package jsonb
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"unsafe"
+)
+
+var (
+	// ErrNotNumber is returned when a numeric value is requested for a token
+	// that is not a Number.
+	ErrNotNumber = errors.New("jsonb: token is not a number")
+
+	// ErrNotInteger is returned when an integer value is requested for a
+	// Number token that has a fraction or an exponent, or that does not
+	// represent an integer.
+	ErrNotInteger = errors.New("jsonb: number is not an integer")
+
+	// ErrNaN is returned by BigFloat for a NaN number, which cannot be
+	// represented by a big.Float.
+	ErrNaN = errors.New("jsonb: number is NaN")
+)
+
+// NumberKind is the kind of a Number token, as written in the document.
+type NumberKind int
+
+const (
+	NumberInvalid  NumberKind = iota - 1 // not a Number token
+	NumberInt                            // an integer, e.g. -12
+	NumberFloat                          // a number with a fraction, e.g. 1.5
+	NumberExponent                       // a number with an exponent, e.g. 1.5e3
+)
+
+var numberKindString = map[NumberKind]string{
+	NumberInvalid:  "<invalid>",
+	NumberInt:      "int",
+	NumberFloat:    "float",
+	NumberExponent: "exponent",
+}
+
+func (k NumberKind) String() string {
+	return numberKindString[k]
+}
+
+// NumberKind returns the kind of the current Number token, or NumberInvalid
+// if the current token is not a Number. The NaN and Infinity numbers allowed
+// by WithAllowSpecialFloats are of kind NumberFloat, and the hexadecimal and
+// octal integers allowed by WithAllowHexNumbers and WithAllowOctalNumbers are
+// of kind NumberInt.
+func (p *Parser) NumberKind() NumberKind {
+	if p.tok != Number {
+		return NumberInvalid
+	}
+	if isRadixNumber(p.buf.Bytes()) {
+		return NumberInt
+	}
+	kind := NumberInt
+	for _, c := range p.buf.Bytes() {
+		switch c {
+		case 'e', 'E':
+			return NumberExponent
+		case '.', 'N', 'I':
+			kind = NumberFloat
+		}
+	}
+	return kind
+}
+
+// Int64 returns the value of the current Number token as an int64. The number
+// must be written as an integer, without fraction or exponent.
+func (p *Parser) Int64() (int64, error) {
+	return p.int64(false)
+}
+
+// TryInt64 is like Int64 but accepts numbers with a fraction or an exponent,
+// as long as they represent an integer (e.g. 1e2 or 1.50e1).
+func (p *Parser) TryInt64() (int64, error) {
+	return p.int64(true)
+}
+
+// Uint64 returns the value of the current Number token as an uint64. The
+// number must be written as a non-negative integer, without fraction or
+// exponent.
+func (p *Parser) Uint64() (uint64, error) {
+	if p.tok != Number {
+		return 0, ErrNotNumber
+	}
+	b := p.buf.Bytes()
+	neg, u, err := parseUint(b, false)
+	if err != nil {
+		return 0, numError("ParseUint", b, err)
+	}
+	if neg && u != 0 {
+		return 0, numError("ParseUint", b, strconv.ErrRange)
+	}
+	return u, nil
+}
+
+// Float64 returns the value of the current Number token as a float64. Numbers
+// too large to be represented are returned as positive or negative infinity,
+// unless the parser was created with the WithFloatOverflowError option.
+func (p *Parser) Float64() (float64, error) {
+	if p.tok != Number {
+		return 0, ErrNotNumber
+	}
+	b := p.buf.Bytes()
+	if isRadixNumber(b) {
+		return p.radixFloat64(b)
+	}
+	f, err := strconv.ParseFloat(unsafeString(b), 64)
+	if err != nil && (p.floatOverflow || !isRangeError(err)) {
+		return 0, err
+	}
+	return f, nil
+}
+
+// BigInt returns the value of the current Number token as a *big.Int. The
+// number must be written as an integer, without fraction or exponent, but
+// it may be arbitrarily large.
+func (p *Parser) BigInt() (*big.Int, error) {
+	if p.tok != Number {
+		return nil, ErrNotNumber
+	}
+	b := p.buf.Bytes()
+	base := 10
+	if isRadixNumber(b) {
+		base = 0
+	} else if bytes.IndexAny(b, ".eEIN") >= 0 {
+		return nil, ErrNotInteger
+	}
+	n, ok := new(big.Int).SetString(unsafeString(b), base)
+	if !ok {
+		return nil, &strconv.NumError{Func: "ParseInt", Num: string(b), Err: strconv.ErrSyntax}
+	}
+	return n, nil
+}
+
+// BigFloat returns the value of the current Number token as a *big.Float.
+// The precision of the result is large enough to hold all the digits of the
+// number, with a minimum of 64 bits.
+func (p *Parser) BigFloat() (*big.Float, error) {
+	if p.tok != Number {
+		return nil, ErrNotNumber
+	}
+	b := p.buf.Bytes()
+	switch string(b) {
+	case "NaN":
+		return nil, ErrNaN
+	case "Infinity":
+		return new(big.Float).SetInf(false), nil
+	case "-Infinity":
+		return new(big.Float).SetInf(true), nil
+	}
+
+	// a decimal digit needs less than 4 bits
+	prec := uint(len(b)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	f, ok := new(big.Float).SetPrec(prec).SetString(unsafeString(b))
+	if !ok {
+		return nil, &strconv.NumError{Func: "ParseFloat", Num: string(b), Err: strconv.ErrSyntax}
+	}
+	return f, nil
+}
+
+// radixFloat64 returns the value of the hexadecimal or octal integer b as a
+// float64, like Float64.
+func (p *Parser) radixFloat64(b []byte) (float64, error) {
+	n, ok := new(big.Int).SetString(unsafeString(b), 0)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: string(b), Err: strconv.ErrSyntax}
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	if math.IsInf(f, 0) && p.floatOverflow {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: string(b), Err: strconv.ErrRange}
+	}
+	return f, nil
+}
+
+// isRadixNumber returns true if b is a Number token with a 0x or 0o prefix,
+// as allowed by WithAllowHexNumbers and WithAllowOctalNumbers.
+func isRadixNumber(b []byte) bool {
+	if len(b) > 0 && b[0] == '-' {
+		b = b[1:]
+	}
+	return len(b) > 1 && b[0] == '0' && (b[1]|0x20 == 'x' || b[1]|0x20 == 'o')
+}
+
+// ParseNumber parses the raw bytes of a JSON number, as returned by Bytes for
+// a Number token, as a float64. It returns a *NumberSyntaxError if b is not
+// a valid JSON number. Like Parser.Float64, numbers too large to be
+// represented are returned as positive or negative infinity.
+func ParseNumber(b []byte) (float64, error) {
+	if !isNumber(b) {
+		return 0, &NumberSyntaxError{Num: string(b)}
+	}
+	f, err := strconv.ParseFloat(unsafeString(b), 64)
+	if err != nil && !isRangeError(err) {
+		return 0, err
+	}
+	return f, nil
+}
+
+// ParseInt parses the raw bytes of a JSON number as an int64, like
+// Parser.Int64. It returns a *NumberSyntaxError if b is not a valid JSON
+// number, and ErrNotInteger if it has a fraction or an exponent.
+func ParseInt(b []byte) (int64, error) {
+	if !isNumber(b) {
+		return 0, &NumberSyntaxError{Num: string(b)}
+	}
+	return parseInt64(b, false)
+}
+
+// isNumber returns true if b is a valid JSON number.
+func isNumber(b []byte) bool {
+	return len(b) > 0 && validNumber(b, 0) == len(b)
+}
+
+func (p *Parser) int64(exp bool) (int64, error) {
+	if p.tok != Number {
+		return 0, ErrNotNumber
+	}
+	return parseInt64(p.buf.Bytes(), exp)
+}
+
+// parseInt64 parses the syntactically valid JSON number b as an int64. If
+// exp is false, the number must not have a fraction or an exponent.
+func parseInt64(b []byte, exp bool) (int64, error) {
+	neg, u, err := parseUint(b, exp)
+	if err != nil {
+		return 0, numError("ParseInt", b, err)
+	}
+	if neg {
+		if u > 1<<63 {
+			return 0, numError("ParseInt", b, strconv.ErrRange)
+		}
+		return -int64(u), nil
+	}
+	if u > 1<<63-1 {
+		return 0, numError("ParseInt", b, strconv.ErrRange)
+	}
+	return int64(u), nil
+}
+
+// numError wraps a range error in a *strconv.NumError, other errors are
+// returned as is.
+func numError(fn string, b []byte, err error) error {
+	if err == strconv.ErrRange {
+		return &strconv.NumError{Func: fn, Num: string(b), Err: err}
+	}
+	return err
+}
+
+// parseUint parses the syntactically valid JSON number b as an integer,
+// returning its sign and magnitude. If exp is false, the number must not
+// have a fraction or an exponent. Hexadecimal and octal integers are
+// decoded by strconv.ParseUint.
+func parseUint(b []byte, exp bool) (neg bool, u uint64, err error) {
+	if len(b) > 0 && b[0] == '-' {
+		neg = true
+		b = b[1:]
+	}
+	if isRadixNumber(b) {
+		u, err := strconv.ParseUint(unsafeString(b), 0, 64)
+		if err != nil {
+			return neg, 0, err.(*strconv.NumError).Err
+		}
+		return neg, u, nil
+	}
+
+	// split the number in its integer, fraction and exponent parts
+	i := 0
+	for i < len(b) && isDigit(b[i]) {
+		i++
+	}
+	intPart, frac := b[:i], b[i:i]
+	if i < len(b) && b[i] == '.' {
+		j := i + 1
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		frac = b[i+1 : j]
+		i = j
+	}
+	scale := 0
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		esign := 1
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			if b[i] == '-' {
+				esign = -1
+			}
+			i++
+		}
+		for ; i < len(b); i++ {
+			if scale < 1<<20 {
+				scale = scale*10 + int(b[i]-'0')
+			}
+		}
+		scale *= esign
+	} else if len(frac) == 0 && len(b) > len(intPart) {
+		// a dot without fraction digits
+		return neg, 0, ErrNotInteger
+	}
+	if !exp && len(b) > len(intPart) {
+		return neg, 0, ErrNotInteger
+	}
+	scale -= len(frac)
+
+	// drop the trailing zeros compensated by a negative scale
+	for scale < 0 && len(frac) > 0 && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+		scale++
+	}
+	if len(frac) == 0 {
+		for scale < 0 && len(intPart) > 0 && intPart[len(intPart)-1] == '0' {
+			intPart = intPart[:len(intPart)-1]
+			scale++
+		}
+	}
+
+	for _, part := range [2][]byte{intPart, frac} {
+		for _, c := range part {
+			d := uint64(c - '0')
+			if u > (1<<64-1-d)/10 {
+				return neg, 0, strconv.ErrRange
+			}
+			u = u*10 + d
+		}
+	}
+	if u == 0 {
+		return neg, 0, nil
+	}
+	if scale < 0 {
+		return neg, 0, ErrNotInteger
+	}
+	for ; scale > 0; scale-- {
+		if u > (1<<64-1)/10 {
+			return neg, 0, strconv.ErrRange
+		}
+		u *= 10
+	}
+	return neg, u, nil
+}
+
+// decimal is the exact value of a JSON number, digits × 10^exp, with the
+// digits stripped of leading and trailing zeros. Zero has no digits and is
+// never negative.
+type decimal struct {
+	neg    bool
+	digits []byte
+	exp    int
+}
+
+// sign returns -1, 0 or +1 depending on the sign of d.
+func (d decimal) sign() int {
+	switch {
+	case len(d.digits) == 0:
+		return 0
+	case d.neg:
+		return -1
+	}
+	return 1
+}
+
+// parseDecimal returns the exact value of the syntactically valid JSON
+// number b. The digits are appended to buf.
+func parseDecimal(buf, b []byte) decimal {
+	var d decimal
+	if len(b) > 0 && b[0] == '-' {
+		d.neg = true
+		b = b[1:]
+	}
+
+	i := 0
+	for ; i < len(b) && isDigit(b[i]); i++ {
+		buf = append(buf, b[i])
+	}
+	if i < len(b) && b[i] == '.' {
+		for i++; i < len(b) && isDigit(b[i]); i++ {
+			buf = append(buf, b[i])
+			d.exp--
+		}
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		esign := 1
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			if b[i] == '-' {
+				esign = -1
+			}
+			i++
+		}
+		scale := 0
+		for ; i < len(b); i++ {
+			if scale < 1<<20 {
+				scale = scale*10 + int(b[i]-'0')
+			}
+		}
+		d.exp += scale * esign
+	}
+
+	for len(buf) > 0 && buf[0] == '0' {
+		buf = buf[1:]
+	}
+	for len(buf) > 0 && buf[len(buf)-1] == '0' {
+		buf = buf[:len(buf)-1]
+		d.exp++
+	}
+	if len(buf) == 0 {
+		return decimal{}
+	}
+	d.digits = buf
+	return d
+}
+
+// equalNumbers returns true if the syntactically valid JSON numbers a and b
+// have the same value.
+func equalNumbers(a, b []byte) bool {
+	var bufa, bufb [32]byte
+	da, db := parseDecimal(bufa[:0], a), parseDecimal(bufb[:0], b)
+	return da.neg == db.neg && da.exp == db.exp && bytes.Equal(da.digits, db.digits)
+}
+
+// NumberFlag is a flag that changes how NormalizeNumber formats a number.
+type NumberFlag int
+
+const (
+	// PreserveNegativeZero keeps the sign of a negative zero, which is
+	// otherwise normalized to 0.
+	PreserveNegativeZero NumberFlag = 1 << iota
+)
+
+// NormalizeNumber returns the canonical representation of the raw JSON
+// number b, so that numbers with the same value, such as 1.0, 1e0 and
+// 10e-1, have the same representation. The number is formatted with the
+// shortest digits that represent its exact decimal value, without
+// exponent if it is at least 1e-6 and less than 1e21, and without decimal
+// point if it is an integer, otherwise like 1.5e+21 or 1e-7. Unlike
+// Canonicalize, no precision is lost to a float64 conversion. It returns a
+// *NumberSyntaxError if b is not a valid JSON number.
+func NormalizeNumber(b []byte, flags ...NumberFlag) ([]byte, error) {
+	if !isNumber(b) {
+		return nil, &NumberSyntaxError{Num: string(b)}
+	}
+	var f NumberFlag
+	for _, flag := range flags {
+		f |= flag
+	}
+
+	var buf [32]byte
+	d := parseDecimal(buf[:0], b)
+	if len(d.digits) == 0 {
+		if f&PreserveNegativeZero != 0 && b[0] == '-' {
+			return []byte("-0"), nil
+		}
+		return []byte("0"), nil
+	}
+
+	var dst []byte
+	if d.neg {
+		dst = append(dst, '-')
+	}
+	// the value is 0.digits × 10^pos
+	n := len(d.digits)
+	switch pos := n + d.exp; {
+	case d.exp >= 0 && pos <= 21:
+		dst = append(dst, d.digits...)
+		for i := 0; i < d.exp; i++ {
+			dst = append(dst, '0')
+		}
+	case 0 < pos && pos <= 21:
+		dst = append(dst, d.digits[:pos]...)
+		dst = append(dst, '.')
+		dst = append(dst, d.digits[pos:]...)
+	case -6 < pos && pos <= 0:
+		dst = append(dst, "0."...)
+		for i := pos; i < 0; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, d.digits...)
+	default:
+		dst = append(dst, d.digits[0])
+		if n > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, d.digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if pos > 0 {
+			dst = append(dst, '+')
+		}
+		dst = strconv.AppendInt(dst, int64(pos-1), 10)
+	}
+	return dst, nil
+}
+
+// CompareNumbers compares the raw JSON numbers a and b by their exact
+// decimal value, without the loss of precision of a float64 conversion,
+// and returns -1 if a < b, 0 if a == b and +1 if a > b. It returns a
+// *NumberSyntaxError if a or b is not a valid JSON number.
+func CompareNumbers(a, b []byte) (int, error) {
+	if !isNumber(a) {
+		return 0, &NumberSyntaxError{Num: string(a)}
+	}
+	if !isNumber(b) {
+		return 0, &NumberSyntaxError{Num: string(b)}
+	}
+	var bufa, bufb [32]byte
+	return compareDecimals(parseDecimal(bufa[:0], a), parseDecimal(bufb[:0], b)), nil
+}
+
+// compareDecimals returns -1, 0 or +1 depending on whether a is less than,
+// equal to or greater than b.
+func compareDecimals(a, b decimal) int {
+	sa, sb := a.sign(), b.sign()
+	switch {
+	case sa < sb:
+		return -1
+	case sa > sb:
+		return 1
+	case sa == 0:
+		return 0
+	}
+
+	// the magnitudes are 0.digits × 10^pos, with no leading zero digit
+	c := 0
+	pa, pb := len(a.digits)+a.exp, len(b.digits)+b.exp
+	switch {
+	case pa < pb:
+		c = -1
+	case pa > pb:
+		c = 1
+	default:
+		c = bytes.Compare(a.digits, b.digits)
+	}
+	return c * sa
+}
+
+// isRangeError returns true if err is a *strconv.NumError caused by a value
+// out of range.
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
+
+// unsafeString returns a string that shares its memory with b, so that
+// b can be used with the strconv functions without being copied. The
+// string must not be retained.
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// isDigit returns true if c is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,344 @@
+package jsonb
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInt64(t *testing.T) {
+	cases := []struct {
+		in     string
+		i64    int64
+		i64Err error
+		u64    uint64
+		u64Err error
+		try    int64
+		tryErr error
+	}{
+		{in: `0`},
+		{in: `-0`},
+		{in: `42`, i64: 42, u64: 42, try: 42},
+		{in: `-42`, i64: -42, u64Err: strconv.ErrRange, try: -42},
+		{in: `9223372036854775807`, i64: 1<<63 - 1, u64: 1<<63 - 1, try: 1<<63 - 1},
+		{in: `-9223372036854775808`, i64: -1 << 63, u64Err: strconv.ErrRange, try: -1 << 63},
+		{in: `9223372036854775808`, i64Err: strconv.ErrRange, u64: 1 << 63, tryErr: strconv.ErrRange},
+		{in: `-9223372036854775809`, i64Err: strconv.ErrRange, u64Err: strconv.ErrRange, tryErr: strconv.ErrRange},
+		{in: `18446744073709551615`, i64Err: strconv.ErrRange, u64: 1<<64 - 1, tryErr: strconv.ErrRange},
+		{in: `18446744073709551616`, i64Err: strconv.ErrRange, u64Err: strconv.ErrRange, tryErr: strconv.ErrRange},
+		{in: `1.0`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, try: 1},
+		{in: `1.5`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, tryErr: ErrNotInteger},
+		{in: `1e2`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, try: 100},
+		{in: `-1.50E+1`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, try: -15},
+		{in: `1500e-2`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, try: 15},
+		{in: `1501e-2`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, tryErr: ErrNotInteger},
+		{in: `0.0e-5`, i64Err: ErrNotInteger, u64Err: ErrNotInteger},
+		{in: `1e19`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, tryErr: strconv.ErrRange},
+		{in: `100000000000000000000e-5`, i64Err: ErrNotInteger, u64Err: ErrNotInteger, try: 1000000000000000},
+		{in: `true`, i64Err: ErrNotNumber, u64Err: ErrNotNumber, tryErr: ErrNotNumber},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+		if !p.Next() {
+			t.Errorf("%d (%s): Next returned false: %v", i, c.in, p.Err())
+			continue
+		}
+
+		i64, err := p.Int64()
+		if unwrapNumError(err) != c.i64Err || i64 != c.i64 {
+			t.Errorf("%d (%s): Int64: want %d (%v), got %d (%v)", i, c.in, c.i64, c.i64Err, i64, err)
+		}
+		u64, err := p.Uint64()
+		if unwrapNumError(err) != c.u64Err || u64 != c.u64 {
+			t.Errorf("%d (%s): Uint64: want %d (%v), got %d (%v)", i, c.in, c.u64, c.u64Err, u64, err)
+		}
+		try, err := p.TryInt64()
+		if unwrapNumError(err) != c.tryErr || try != c.try {
+			t.Errorf("%d (%s): TryInt64: want %d (%v), got %d (%v)", i, c.in, c.try, c.tryErr, try, err)
+		}
+	}
+}
+
+func unwrapNumError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+func TestNumErrorFunc(t *testing.T) {
+	p := NewParser(strings.NewReader(`18446744073709551616`))
+	if !p.Next() {
+		t.Fatal(p.Err())
+	}
+	cases := []struct {
+		fn   string
+		call func() error
+	}{
+		{"ParseInt", func() error { _, err := p.Int64(); return err }},
+		{"ParseInt", func() error { _, err := p.TryInt64(); return err }},
+		{"ParseUint", func() error { _, err := p.Uint64(); return err }},
+	}
+	for i, c := range cases {
+		ne, ok := c.call().(*strconv.NumError)
+		if !ok || ne.Func != c.fn {
+			t.Errorf("%d: want *strconv.NumError from %s, got %#v", i, c.fn, ne)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	cases := []struct {
+		in  string
+		out float64
+		err error
+	}{
+		{in: `0`, out: 0},
+		{in: `-0`, out: math.Copysign(0, -1)},
+		{in: `42`, out: 42},
+		{in: `-1.5`, out: -1.5},
+		{in: `-0.123E-001`, out: -0.0123},
+		{in: `123E+2`, out: 12300},
+		{in: `1e400`, out: math.Inf(1)},
+		{in: `-1e400`, out: math.Inf(-1)},
+		{in: `1e-400`, out: 0},
+		{in: `"1"`, err: ErrNotNumber},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+		if !p.Next() {
+			t.Errorf("%d (%s): Next returned false: %v", i, c.in, p.Err())
+			continue
+		}
+
+		f, err := p.Float64()
+		if err != c.err {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.in, c.err, err)
+			continue
+		}
+		if f != c.out || math.Signbit(f) != math.Signbit(c.out) {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.out, f)
+		}
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	cases := []struct {
+		in       string
+		opts     []ParserOption
+		bigInt   string
+		intErr   error
+		bigFloat string
+		floatErr error
+	}{
+		{in: `0`, bigInt: "0", bigFloat: "0"},
+		{in: `-42`, bigInt: "-42", bigFloat: "-42"},
+		{in: `99999999999999999999999999999`, bigInt: "99999999999999999999999999999", bigFloat: "9.9999999999999999999999999999e+28"},
+		{in: `-123456789012345678901234567890.125`, intErr: ErrNotInteger, bigFloat: "-1.23456789012345678901234567890125e+29"},
+		{in: `1e2`, intErr: ErrNotInteger, bigFloat: "100"},
+		{in: `1E-3`, intErr: ErrNotInteger, bigFloat: "0.001"},
+		{in: `1e400`, intErr: ErrNotInteger, bigFloat: "1e+400"},
+		{in: `Infinity`, opts: []ParserOption{WithAllowSpecialFloats()}, intErr: ErrNotInteger, bigFloat: "+Inf"},
+		{in: `-Infinity`, opts: []ParserOption{WithAllowSpecialFloats()}, intErr: ErrNotInteger, bigFloat: "-Inf"},
+		{in: `NaN`, opts: []ParserOption{WithAllowSpecialFloats()}, intErr: ErrNotInteger, floatErr: ErrNaN},
+		{in: `"1"`, intErr: ErrNotNumber, floatErr: ErrNotNumber},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), c.opts...)
+		if !p.Next() {
+			t.Errorf("%d (%s): Next returned false: %v", i, c.in, p.Err())
+			continue
+		}
+
+		n, err := p.BigInt()
+		if err != c.intErr {
+			t.Errorf("%d (%s): BigInt: want error %v, got %v", i, c.in, c.intErr, err)
+		} else if err == nil && n.String() != c.bigInt {
+			t.Errorf("%d (%s): BigInt: want %s, got %s", i, c.in, c.bigInt, n)
+		}
+		f, err := p.BigFloat()
+		if err != c.floatErr {
+			t.Errorf("%d (%s): BigFloat: want error %v, got %v", i, c.in, c.floatErr, err)
+		} else if err == nil && f.Text('g', -1) != c.bigFloat {
+			t.Errorf("%d (%s): BigFloat: want %s, got %s", i, c.in, c.bigFloat, f.Text('g', -1))
+		}
+	}
+}
+
+func TestNumberKind(t *testing.T) {
+	cases := []struct {
+		in   string
+		kind NumberKind
+	}{
+		{in: `0`, kind: NumberInt},
+		{in: `-42`, kind: NumberInt},
+		{in: `1.5`, kind: NumberFloat},
+		{in: `-0.0`, kind: NumberFloat},
+		{in: `1e3`, kind: NumberExponent},
+		{in: `1.5E-3`, kind: NumberExponent},
+		{in: `NaN`, kind: NumberFloat},
+		{in: `-Infinity`, kind: NumberFloat},
+		{in: `"1"`, kind: NumberInvalid},
+		{in: `[1]`, kind: NumberInvalid},
+	}
+
+	p := NewParserOptions(nil, WithAllowSpecialFloats())
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+		p.Next()
+		if kind := p.NumberKind(); kind != c.kind {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.kind, kind)
+		}
+	}
+}
+
+func BenchmarkFloat64(b *testing.B) {
+	p := NewParser(strings.NewReader(`-1234.5678e-3`))
+	p.Next()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Float64(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		in     string
+		f      float64
+		fErr   error
+		i      int64
+		iErr   error
+		syntax bool
+	}{
+		{in: `0`},
+		{in: `-0`},
+		{in: `42`, f: 42, i: 42},
+		{in: `-1.5e3`, f: -1500, iErr: ErrNotInteger},
+		{in: `0.25`, f: 0.25, iErr: ErrNotInteger},
+		{in: `1E2`, f: 100, iErr: ErrNotInteger},
+		{in: `9223372036854775808`, f: 1 << 63, iErr: strconv.ErrRange},
+		{in: `1e400`, f: math.Inf(1), iErr: ErrNotInteger},
+		{in: ``, syntax: true},
+		{in: `-`, syntax: true},
+		{in: `+1`, syntax: true},
+		{in: `01`, syntax: true},
+		{in: `1.`, syntax: true},
+		{in: `.5`, syntax: true},
+		{in: `1e`, syntax: true},
+		{in: `0x10`, syntax: true},
+		{in: `Infinity`, syntax: true},
+		{in: `NaN`, syntax: true},
+		{in: ` 1`, syntax: true},
+		{in: `1_000`, syntax: true},
+	}
+
+	for i, c := range cases {
+		f, ferr := ParseNumber([]byte(c.in))
+		n, ierr := ParseInt([]byte(c.in))
+		if c.syntax {
+			want := &NumberSyntaxError{Num: c.in}
+			if !reflect.DeepEqual(ferr, want) || !reflect.DeepEqual(ierr, want) {
+				t.Errorf("%d (%s): want %v, got %v and %v", i, c.in, want, ferr, ierr)
+			}
+			continue
+		}
+		if ferr != c.fErr || f != c.f {
+			t.Errorf("%d (%s): ParseNumber: want %g (%v), got %g (%v)", i, c.in, c.f, c.fErr, f, ferr)
+		}
+		if unwrapNumError(ierr) != c.iErr || n != c.i {
+			t.Errorf("%d (%s): ParseInt: want %d (%v), got %d (%v)", i, c.in, c.i, c.iErr, n, ierr)
+		}
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	cases := []struct {
+		in    string
+		flags []NumberFlag
+		out   string
+		err   error
+	}{
+		{in: `1`, out: `1`},
+		{in: `1.0`, out: `1`},
+		{in: `1e0`, out: `1`},
+		{in: `1.000`, out: `1`},
+		{in: `10e-1`, out: `1`},
+		{in: `-12.50`, out: `-12.5`},
+		{in: `0.001E3`, out: `1`},
+		{in: `1.5e2`, out: `150`},
+		{in: `0.000001`, out: `0.000001`},
+		{in: `1e-7`, out: `1e-7`},
+		{in: `-123.45e-10`, out: `-1.2345e-8`},
+		{in: `1e20`, out: `100000000000000000000`},
+		{in: `1e21`, out: `1e+21`},
+		{in: `12345678901234567890123`, out: `1.2345678901234567890123e+22`},
+		{in: `9007199254740993`, out: `9007199254740993`},
+		{in: `0.1000000000000000000001`, out: `0.1000000000000000000001`},
+		{in: `0`, out: `0`},
+		{in: `0.0e10`, out: `0`},
+		{in: `-0`, out: `0`},
+		{in: `-0.0`, out: `0`},
+		{in: `-0.0`, flags: []NumberFlag{PreserveNegativeZero}, out: `-0`},
+		{in: `0`, flags: []NumberFlag{PreserveNegativeZero}, out: `0`},
+		{in: `01`, err: &NumberSyntaxError{Num: `01`}},
+		{in: ``, err: &NumberSyntaxError{Num: ``}},
+	}
+
+	for i, c := range cases {
+		out, err := NormalizeNumber([]byte(c.in), c.flags...)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+func TestCompareNumbers(t *testing.T) {
+	cases := []struct {
+		a, b string
+		c    int
+		err  error
+	}{
+		{a: `1`, b: `1.0`, c: 0},
+		{a: `1e2`, b: `100`, c: 0},
+		{a: `0`, b: `-0.0e5`, c: 0},
+		{a: `1`, b: `2`, c: -1},
+		{a: `2`, b: `1`, c: 1},
+		{a: `-1`, b: `1`, c: -1},
+		{a: `0`, b: `-1e-100`, c: 1},
+		{a: `0`, b: `1e-100`, c: -1},
+		{a: `-2`, b: `-10`, c: 1},
+		{a: `0.12`, b: `0.123`, c: -1},
+		{a: `99`, b: `1e2`, c: -1},
+		{a: `9007199254740993`, b: `9007199254740992`, c: 1},
+		{a: `-9007199254740993`, b: `-9007199254740992`, c: -1},
+		{a: `0.10000000000000000001`, b: `0.1`, c: 1},
+		{a: `1e400`, b: `1e399`, c: 1},
+		{a: `1.`, b: `1`, err: &NumberSyntaxError{Num: `1.`}},
+		{a: `1`, b: `"1"`, err: &NumberSyntaxError{Num: `"1"`}},
+	}
+
+	for i, c := range cases {
+		got, err := CompareNumbers([]byte(c.a), []byte(c.b))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if got != c.c {
+			t.Errorf("%d: %s <=> %s: want %d, got %d", i, c.a, c.b, c.c, got)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package jsonb
+
+import (
+	"context"
+	"io"
+)
+
+// ParserOption configures a Parser created with NewParserOptions.
+type ParserOption func(*Parser)
+
+// NewParserOptions returns a parser that reads from r, configured with the
+// provided options.
+func NewParserOptions(r io.Reader, opts ...ParserOption) *Parser {
+	p := newParser(opts)
+	p.setReader(r)
+	return p
+}
+
+// NewParserContext returns a parser that reads from r and stops with the
+// error of ctx once ctx is done. The context is checked periodically while
+// reading, see WithContextCheckInterval.
+func NewParserContext(ctx context.Context, r io.Reader, opts ...ParserOption) *Parser {
+	p := NewParserOptions(r, opts...)
+	p.ctx = ctx
+	return p
+}
+
+// NewNDJSONParser returns a parser for a stream of newline-delimited JSON
+// documents, as if created with the WithMultiValue option.
+func NewNDJSONParser(r io.Reader) *Parser {
+	return NewParserOptions(r, WithMultiValue())
+}
+
+// WithChunkSize sets the maximum size of a chunk of raw value. Sizes below
+// the minimum of 5 bytes are raised to that minimum.
+func WithChunkSize(n int64) ParserOption {
+	return func(p *Parser) {
+		if n < minChunkSize {
+			n = minChunkSize
+		}
+		p.size = n
+	}
+}
+
+// WithStackCapacity pre-allocates the internal stack so that documents nested
+// up to n levels deep are parsed without growing it. By default, the stack is
+// not pre-allocated. A capacity of 8 is enough for most API payloads, deeply
+// nested configuration documents may need 64.
+func WithStackCapacity(n int) ParserOption {
+	return func(p *Parser) {
+		p.stack = make([]state, 0, n)
+		p.path = make([]pathFrame, 0, n)
+	}
+}
+
+// WithMaxDepth limits the nesting depth of arrays and objects to n. Going
+// deeper fails with a *DepthLimitError. A limit of 0, the default, means no
+// limit.
+func WithMaxDepth(n int) ParserOption {
+	return func(p *Parser) {
+		p.maxDepth = n
+	}
+}
+
+// WithDepthLimit is the same as WithMaxDepth.
+//
+// Deprecated: use WithMaxDepth, named like the other limits.
+func WithDepthLimit(n int) ParserOption {
+	return WithMaxDepth(n)
+}
+
+// WithMaxStringLen limits the size in bytes of a string token, including its
+// double-quotes, to n. A longer string fails with a *StringLenError. A limit
+// of 0 means no limit.
+func WithMaxStringLen(n int) ParserOption {
+	return func(p *Parser) {
+		p.maxStringLen = n
+	}
+}
+
+// WithMaxNumberLen limits the size in bytes of a number token to n. A longer
+// number fails with a *NumberLenError. A limit of 0 means no limit.
+func WithMaxNumberLen(n int) ParserOption {
+	return func(p *Parser) {
+		p.maxNumberLen = n
+	}
+}
+
+// WithMaxTokens limits the number of tokens parsed since the parser was
+// created or reset, as reported by TokenCount, to n. Parsing more tokens
+// fails with a *TokenLimitError. A limit of 0 means no limit.
+func WithMaxTokens(n int64) ParserOption {
+	return func(p *Parser) {
+		p.maxTokens = n
+	}
+}
+
+// WithFloatOverflowError makes Parser.Float64 return an error for numbers
+// that are too large to be represented, instead of an infinity.
+func WithFloatOverflowError() ParserOption {
+	return func(p *Parser) {
+		p.floatOverflow = true
+	}
+}
+
+// WithMultiValue makes the parser accept a sequence of top-level values
+// separated by whitespace, such as newline-delimited JSON (NDJSON), instead
+// of a single one. Parser.Document returns the index of the current value.
+func WithMultiValue() ParserOption {
+	return func(p *Parser) {
+		p.multi = true
+	}
+}
+
+// WithSkipBadDocuments implies WithMultiValue and makes the parser recover
+// from invalid documents: on a syntax error, Next returns true with an
+// Invalid token and Err returns the error. The following call to Next skips
+// the rest of the line, clears the error and resumes with the next document.
+func WithSkipBadDocuments() ParserOption {
+	return func(p *Parser) {
+		p.multi = true
+		p.skipBad = true
+	}
+}
+
+// WithCollectErrors makes the parser recover from the content that follows
+// the top-level value, instead of failing on it: a *SyntaxError is collected
+// for each run of bytes separated by whitespace, and the parser continues
+// up to the end of the input. Parser.Errs returns all the errors and
+// Parser.Err the first one. It has no effect in multi-value mode.
+func WithCollectErrors() ParserOption {
+	return func(p *Parser) {
+		p.collect = true
+	}
+}
+
+// WithRejectDuplicateKeys makes the parser fail with a *DuplicateKeyError
+// when an object has the same key more than once. Keys are compared once
+// decoded, so "a" and "\u0061" are the same key.
+func WithRejectDuplicateKeys() ParserOption {
+	return func(p *Parser) {
+		p.dupKeys = true
+	}
+}
+
+// WithStrictSurrogates makes the parser fail with a *SurrogatePairError when
+// a \u escape of a UTF-16 surrogate is not part of a valid pair, that is a
+// high surrogate immediately followed by a low surrogate. By default, such
+// escapes are accepted and decoded as the Unicode replacement character.
+func WithStrictSurrogates() ParserOption {
+	return func(p *Parser) {
+		p.strictSurrogates = true
+	}
+}
+
+// WithStripBOM makes the parser skip a UTF-8 byte order mark (U+FEFF) at the
+// start of the input.
+func WithStripBOM() ParserOption {
+	return func(p *Parser) {
+		p.stripBOM = true
+	}
+}
+
+// WithRejectBOM makes the parser fail with a *SyntaxError when the input
+// starts with a UTF-8 byte order mark, as required by RFC 8259. This is the
+// default behaviour, the option exists to override WithStripBOM.
+func WithRejectBOM() ParserOption {
+	return func(p *Parser) {
+		p.stripBOM = false
+	}
+}
+
+// WithAllowComments makes the parser accept comments wherever whitespace is
+// allowed, as in JSONC. Comments are either line comments, from // to the end
+// of the line, or block comments between /* and */, which may be nested.
+// An unterminated block comment fails with an *InvalidCommentError.
+func WithAllowComments() ParserOption {
+	return func(p *Parser) {
+		p.comments = true
+	}
+}
+
+// WithAllowTrailingCommas makes the parser accept a comma after the last
+// element of an array or object, as in `[1, 2,]` or `{"a": 1,}`. A comma
+// that does not follow an element is still invalid.
+func WithAllowTrailingCommas() ParserOption {
+	return func(p *Parser) {
+		p.trailingCommas = true
+	}
+}
+
+// WithAllowSpecialFloats makes the parser accept the NaN, Infinity and
+// -Infinity literals as Number tokens. The bytes of the token are kept as-is
+// so that they can be distinguished from regular numbers, and Float64
+// returns the corresponding NaN or infinite value.
+func WithAllowSpecialFloats() ParserOption {
+	return func(p *Parser) {
+		p.specialFloats = true
+	}
+}
+
+// WithAllowSingleQuotes makes the parser accept string literals and object
+// keys delimited by single-quotes, as in JavaScript. In a single-quoted
+// string, a single-quote must be escaped as \' and a double-quote is a
+// literal character. The bytes of the tokens are converted to a
+// double-quoted string literal, so that 'a"b' is returned as "a\"b". The
+// \' escape sequence is also accepted in double-quoted strings.
+func WithAllowSingleQuotes() ParserOption {
+	return func(p *Parser) {
+		p.singleQuotes = true
+	}
+}
+
+// WithAllowUnquotedKeys makes the parser accept object keys that are
+// identifiers, as in JavaScript: a letter, an underscore or a dollar sign,
+// followed by letters, digits, underscores and dollar signs, as in {a: 1}.
+// The bytes of the ObjectKey token are converted to a double-quoted string
+// literal.
+func WithAllowUnquotedKeys() ParserOption {
+	return func(p *Parser) {
+		p.unquotedKeys = true
+	}
+}
+
+// WithAllowHexNumbers makes the parser accept hexadecimal integers prefixed
+// by 0x or 0X, optionally negative, as in 0xFF or -0x1a. The bytes of the
+// Number token are kept as-is, and the numeric methods of the parser such
+// as Int64 and Float64 decode them.
+func WithAllowHexNumbers() ParserOption {
+	return func(p *Parser) {
+		p.hexNumbers = true
+	}
+}
+
+// WithAllowOctalNumbers makes the parser accept octal integers prefixed by
+// 0o or 0O, optionally negative, as in 0o755. The bytes of the Number token
+// are kept as-is, and the numeric methods of the parser such as Int64 and
+// Float64 decode them.
+func WithAllowOctalNumbers() ParserOption {
+	return func(p *Parser) {
+		p.octalNumbers = true
+	}
+}
+
+// WithWarnOnExtensions makes the parser record a Warning for each use of a
+// non-standard feature allowed by its options, such as a comment, a trailing
+// comma or a hexadecimal number. Parser.Warnings returns them, parsing is
+// not affected.
+func WithWarnOnExtensions() ParserOption {
+	return func(p *Parser) {
+		p.warnExt = true
+	}
+}
+
+// WithContextCheckInterval sets the number of runes read between checks of
+// the context of a parser created with NewParserContext. It defaults to 1024,
+// values below 1 check the context before every rune.
+func WithContextCheckInterval(n int) ParserOption {
+	return func(p *Parser) {
+		if n < 1 {
+			n = 1
+		}
+		p.interval = n
+	}
+}
+
+// WithErrorContext sets the number of bytes of input surrounding an invalid
+// character that are stored in the Near field of a *SyntaxError or
+// *LiteralError: up to n/2 bytes preceding the character, and the
+// character and the bytes following it for the rest. The default is 40. If
+// n is 0, Near is nil.
+func WithErrorContext(n int) ParserOption {
+	return func(p *Parser) {
+		if n < 0 {
+			n = 0
+		}
+		p.near = n
+	}
+}
+
+// WithKeyValidator sets a function that validates the object keys. It is
+// called with the raw bytes of each ObjectKey token, including the
+// double-quotes, before the token is returned, and the bytes must not be
+// retained after it returns. If it returns an error, parsing stops with a
+// *KeyValidationError that wraps that error.
+func WithKeyValidator(fn func(key []byte) error) ParserOption {
+	return func(p *Parser) {
+		p.keyValidator = fn
+	}
+}
+
+// WithOnToken sets a function that is called with each token and its bytes
+// once it is parsed, before Next returns it. When Peek is used, it is called
+// when the token is parsed by Peek. The bytes are only valid for the
+// duration of the call and must not be retained.
+func WithOnToken(fn func(Token, []byte)) ParserOption {
+	return func(p *Parser) {
+		p.onToken = fn
+	}
+}
+
+// WithOnError sets a function that is called with each error that stops the
+// parser, as returned by Err. It is not called at the end of the input.
+func WithOnError(fn func(error)) ParserOption {
+	return func(p *Parser) {
+		p.onError = fn
+	}
+}
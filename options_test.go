@@ -0,0 +1,652 @@
+package jsonb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParserOptions(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts []ParserOption
+		toks []Token
+		err  error
+	}{
+		{in: `[[1]]`, toks: []Token{ArrayStart, ArrayStart, Number, ArrayEnd, ArrayEnd}},
+		{in: `[[1]]`, opts: []ParserOption{WithMaxDepth(2)}, toks: []Token{ArrayStart, ArrayStart, Number, ArrayEnd, ArrayEnd}},
+		{in: `[[1]]`, opts: []ParserOption{WithMaxDepth(1)}, toks: []Token{ArrayStart}, err: &DepthLimitError{Limit: 1}},
+		{in: `{"a": {}}`, opts: []ParserOption{WithMaxDepth(1)}, toks: []Token{ObjectStart, ObjectKey}, err: &DepthLimitError{Limit: 1}},
+		{in: `[[[1]]]`, opts: []ParserOption{WithDepthLimit(2)}, toks: []Token{ArrayStart, ArrayStart}, err: &DepthLimitError{Limit: 2}},
+		{in: `["abc"]`, opts: []ParserOption{WithMaxStringLen(5)}, toks: []Token{ArrayStart, String, ArrayEnd}},
+		{in: `["abcd"]`, opts: []ParserOption{WithMaxStringLen(5)}, toks: []Token{ArrayStart, Invalid}, err: &StringLenError{Limit: 5, Len: 6}},
+		{in: `{"abcd": 1}`, opts: []ParserOption{WithMaxStringLen(5)}, toks: []Token{ObjectStart, Invalid}, err: &StringLenError{Limit: 5, Len: 6}},
+		{in: `["abcdefgh"]`, opts: []ParserOption{WithMaxStringLen(5)}, toks: []Token{ArrayStart, Invalid}, err: &StringLenError{Limit: 5, Len: 6}},
+		{in: `[-1.5e10, 1]`, opts: []ParserOption{WithMaxNumberLen(7)}, toks: []Token{ArrayStart, Number, Number, ArrayEnd}},
+		{in: `[-1.5e100]`, opts: []ParserOption{WithMaxNumberLen(7)}, toks: []Token{ArrayStart, Invalid}, err: &NumberLenError{Limit: 7, Len: 8}},
+		{in: `[123456789]`, opts: []ParserOption{WithMaxNumberLen(7)}, toks: []Token{ArrayStart, Invalid}, err: &NumberLenError{Limit: 7, Len: 8}},
+		{in: `[0.0000001]`, opts: []ParserOption{WithMaxNumberLen(7)}, toks: []Token{ArrayStart, Invalid}, err: &NumberLenError{Limit: 7, Len: 8}},
+		{in: `[1, 2]`, opts: []ParserOption{WithMaxTokens(4)}, toks: []Token{ArrayStart, Number, Number, ArrayEnd}},
+		{in: `[1, 2, 3]`, opts: []ParserOption{WithMaxTokens(4)}, toks: []Token{ArrayStart, Number, Number, Number}, err: &TokenLimitError{Limit: 4}},
+		{in: `{"a": 1, "b": {"a": 2}, "c": [{"a": 3}, {"a": 4}]}`, opts: []ParserOption{WithRejectDuplicateKeys()},
+			toks: []Token{ObjectStart, ObjectKey, Number, ObjectKey, ObjectStart, ObjectKey, Number, ObjectEnd, ObjectKey,
+				ArrayStart, ObjectStart, ObjectKey, Number, ObjectEnd, ObjectStart, ObjectKey, Number, ObjectEnd, ArrayEnd, ObjectEnd}},
+		{in: `{"a": 1, "b": 2, "a": 3}`, toks: []Token{ObjectStart, ObjectKey, Number, ObjectKey, Number, ObjectKey, Number, ObjectEnd}},
+		{in: `{"a": 1, "b": 2, "a": 3}`, opts: []ParserOption{WithRejectDuplicateKeys()},
+			toks: []Token{ObjectStart, ObjectKey, Number, ObjectKey, Number, Invalid}, err: &DuplicateKeyError{Key: "a", Offset: 17}},
+		{in: `[{"a": {"b": 1, "\u0062": 2}}]`, opts: []ParserOption{WithRejectDuplicateKeys()},
+			toks: []Token{ArrayStart, ObjectStart, ObjectKey, ObjectStart, ObjectKey, Number, Invalid}, err: &DuplicateKeyError{Key: "b", Offset: 16}},
+		{in: `["\ud83d\ude00", "\uDBFF\uDFFF", "\ud83d"]`, toks: []Token{ArrayStart, String, String, String, ArrayEnd}},
+		{in: `["\ud83d\ude00", "\uDBFF\uDFFF"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, String, String, ArrayEnd}},
+		{in: `["\ud83d"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, Invalid}, err: &SurrogatePairError{Rune: 0xd83d, Offset: 2}},
+		{in: `["a\ud83db"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, Invalid}, err: &SurrogatePairError{Rune: 0xd83d, Offset: 3}},
+		{in: `["\ud83d\n"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, Invalid}, err: &SurrogatePairError{Rune: 0xd83d, Offset: 2}},
+		{in: `["\ud83d\ud83d"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, Invalid}, err: &SurrogatePairError{Rune: 0xd83d, Offset: 2}},
+		{in: `["\ude00\ud83d"]`, opts: []ParserOption{WithStrictSurrogates()}, toks: []Token{ArrayStart, Invalid}, err: &SurrogatePairError{Rune: 0xde00, Offset: 2}},
+		{in: "\uFEFF[1]", toks: []Token{Invalid}, err: &SyntaxError{Char: '\uFEFF', Line: 1, Column: 1, typ: bomLit}},
+		{in: "\uFEFF[1]", opts: []ParserOption{WithStripBOM(), WithRejectBOM()}, toks: []Token{Invalid}, err: &SyntaxError{Char: '\uFEFF', Line: 1, Column: 1, typ: bomLit}},
+		{in: "\uFEFF[1]", opts: []ParserOption{WithStripBOM()}, toks: []Token{ArrayStart, Number, ArrayEnd}},
+		{in: "\uFEFF \n [1]", opts: []ParserOption{WithStripBOM()}, toks: []Token{ArrayStart, Number, ArrayEnd}},
+		{in: " [1]", opts: []ParserOption{WithStripBOM()}, toks: []Token{ArrayStart, Number, ArrayEnd}},
+		{in: "1", opts: []ParserOption{WithStripBOM()}, toks: []Token{Number}},
+		{in: "", opts: []ParserOption{WithStripBOM()}},
+		{in: "[\uFEFF]", opts: []ParserOption{WithStripBOM()}, toks: []Token{ArrayStart, Invalid}, err: &SyntaxError{Char: '\uFEFF', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `[1 /* a */]`, toks: []Token{ArrayStart, Number, Invalid}, err: &SyntaxError{Char: '/', Line: 1, Column: 4, Offset: 3, typ: begVal}},
+		{in: "// head\n[1, /* one */ 2 // two\n, true/**/, null// x\n]// end", opts: []ParserOption{WithAllowComments()},
+			toks: []Token{ArrayStart, Number, Number, True, Null, ArrayEnd}},
+		{in: `/* a /* nested */ comment */ {/**/"a"/**/:/**/1/**/}/***/`, opts: []ParserOption{WithAllowComments()},
+			toks: []Token{ObjectStart, ObjectKey, Number, ObjectEnd}},
+		{in: `["/* not a comment */"]`, opts: []ParserOption{WithAllowComments()}, toks: []Token{ArrayStart, String, ArrayEnd}},
+		{in: `[1, /* a /* b */ 2]`, opts: []ParserOption{WithAllowComments()}, toks: []Token{ArrayStart, Number, Invalid},
+			err: &InvalidCommentError{Offset: 4, Unterminated: true}},
+		{in: `[1] /`, opts: []ParserOption{WithAllowComments()}, toks: []Token{ArrayStart, Number, Invalid},
+			err: &InvalidCommentError{Offset: 4, Unterminated: true}},
+		{in: `[1, /x 2]`, opts: []ParserOption{WithAllowComments()}, toks: []Token{ArrayStart, Number, Invalid},
+			err: &InvalidCommentError{Offset: 4}},
+		{in: "\uFEFF// c\n1", opts: []ParserOption{WithAllowComments(), WithStripBOM()}, toks: []Token{Number}},
+		{in: `[1,]`, toks: []Token{ArrayStart, Number}, err: &SyntaxError{Char: ']', Line: 1, Column: 4, Offset: 3, typ: begVal}},
+		{in: `[1, 2,]`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ArrayStart, Number, Number, ArrayEnd}},
+		{in: `{"a": 1,}`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ObjectStart, ObjectKey, Number, ObjectEnd}},
+		{in: `[1,[2,],]`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ArrayStart, Number, ArrayStart, Number, ArrayEnd, ArrayEnd}},
+		{in: `[,]`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ArrayStart}, err: &SyntaxError{Char: ',', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `{,}`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ObjectStart}, err: &SyntaxError{Char: ',', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `[1,,]`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ArrayStart, Number}, err: &SyntaxError{Char: ',', Line: 1, Column: 4, Offset: 3, typ: begVal}},
+		{in: `{"a":}`, opts: []ParserOption{WithAllowTrailingCommas()}, toks: []Token{ObjectStart, ObjectKey}, err: &SyntaxError{Char: '}', Line: 1, Column: 6, Offset: 5, typ: begVal}},
+		{in: `[NaN]`, toks: []Token{ArrayStart, Invalid}, err: &SyntaxError{Char: 'N', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `[NaN, Infinity, -Infinity]`, opts: []ParserOption{WithAllowSpecialFloats()}, toks: []Token{ArrayStart, Number, Number, Number, ArrayEnd}},
+		{in: `[Nan]`, opts: []ParserOption{WithAllowSpecialFloats()}, toks: []Token{ArrayStart, Invalid},
+			err: &LiteralError{Line: 1, Column: 4, Offset: 3, want: 'N', got: 'n', tok: Number}},
+		{in: `[-Inf]`, opts: []ParserOption{WithAllowSpecialFloats()}, toks: []Token{ArrayStart, Invalid},
+			err: &LiteralError{Line: 1, Column: 6, Offset: 5, want: 'i', got: ']', tok: Number}},
+		{in: `[1Infinity]`, opts: []ParserOption{WithAllowSpecialFloats()}, toks: []Token{ArrayStart, Invalid},
+			err: &SyntaxError{Char: 'I', Line: 1, Column: 3, Offset: 2, typ: endLit}},
+		{in: `[NaNa]`, opts: []ParserOption{WithAllowSpecialFloats()}, toks: []Token{ArrayStart, Invalid},
+			err: &SyntaxError{Char: 'a', Line: 1, Column: 5, Offset: 4, typ: endLit}},
+		{in: `[1, 2]`, opts: []ParserOption{WithStackCapacity(8), WithChunkSize(1)}, toks: []Token{ArrayStart, Number, Number, ArrayEnd}},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), append(c.opts, WithErrorContext(0))...)
+
+		var toks []Token
+		for p.Next() {
+			toks = append(toks, p.Token())
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d (%s): want tokens %v, got %v", i, c.in, c.toks, toks)
+		}
+		if err := p.Err(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want %v, got error %v", i, c.in, c.err, err)
+		}
+	}
+}
+
+func TestChunkSizeOption(t *testing.T) {
+	cases := []struct {
+		size int64
+		want int64
+	}{
+		{size: 0, want: minChunkSize},
+		{size: 4, want: minChunkSize},
+		{size: 5, want: 5},
+		{size: 1024, want: 1024},
+	}
+	for i, c := range cases {
+		if p := NewParserOptions(nil, WithChunkSize(c.size)); p.size != c.want {
+			t.Errorf("%d: want size %d, got %d", i, c.want, p.size)
+		}
+	}
+	if p := NewParser(nil); p.size != DefaultChunkSize {
+		t.Errorf("want default size %d, got %d", DefaultChunkSize, p.size)
+	}
+}
+
+func TestFloatOverflowOption(t *testing.T) {
+	p := NewParserOptions(strings.NewReader(`1e400`), WithFloatOverflowError())
+	p.Next()
+	if _, err := p.Float64(); unwrapNumError(err) != strconv.ErrRange {
+		t.Errorf("want range error, got %v", err)
+	}
+}
+
+func TestSpecialFloatsOption(t *testing.T) {
+	cases := []struct {
+		in  string
+		out float64
+	}{
+		{in: `NaN`, out: math.NaN()},
+		{in: `Infinity`, out: math.Inf(1)},
+		{in: `-Infinity`, out: math.Inf(-1)},
+	}
+
+	p := NewParserOptions(nil, WithAllowSpecialFloats())
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+		if !p.Next() {
+			t.Errorf("%d (%s): Next returned false: %v", i, c.in, p.Err())
+			continue
+		}
+		if got := string(p.Bytes()); got != c.in {
+			t.Errorf("%d: want bytes %s, got %s", i, c.in, got)
+		}
+		f, err := p.Float64()
+		if err != nil {
+			t.Errorf("%d (%s): want no error, got %v", i, c.in, err)
+			continue
+		}
+		if math.IsNaN(c.out) != math.IsNaN(f) || !math.IsNaN(f) && f != c.out {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.out, f)
+		}
+		if _, err := p.Int64(); err != ErrNotInteger {
+			t.Errorf("%d (%s): want Int64 error %v, got %v", i, c.in, ErrNotInteger, err)
+		}
+	}
+}
+
+func TestParserContext(t *testing.T) {
+	const in = `[1, 2, 3, 4, 5, 6, 7, 8]`
+
+	p := NewParserContext(context.Background(), strings.NewReader(in), WithContextCheckInterval(1))
+	var n int
+	for p.Next() {
+		n++
+	}
+	if n != 10 || p.Err() != nil {
+		t.Errorf("want 10 tokens and no error, got %d: %v", n, p.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p = NewParserContext(ctx, strings.NewReader(in), WithContextCheckInterval(4))
+	n = 0
+	for p.Next() {
+		if n++; n == 2 {
+			cancel()
+		}
+	}
+	if err := p.Err(); err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+	if n > 4 {
+		t.Errorf("want at most 4 tokens, got %d", n)
+	}
+}
+
+func TestMultiValue(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts []ParserOption
+		toks []Token
+		docs []int
+		errs []error // error after each token
+	}{
+		{in: "1\n2", opts: []ParserOption{WithMultiValue()}, toks: []Token{Number, Number}, docs: []int{0, 1}},
+		{in: "{\"a\": [1]}\n[true]\n\n\"b\"\n", opts: []ParserOption{WithMultiValue()},
+			toks: []Token{ObjectStart, ObjectKey, ArrayStart, Number, ArrayEnd, ObjectEnd, ArrayStart, True, ArrayEnd, String},
+			docs: []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 2}},
+		{in: "[1][2]", opts: []ParserOption{WithMultiValue()}, toks: []Token{ArrayStart, Number, ArrayEnd, ArrayStart, Number, ArrayEnd},
+			docs: []int{0, 0, 0, 1, 1, 1}},
+		{in: "1\n[z, 1]\n2", opts: []ParserOption{WithMultiValue()}, toks: []Token{Number, ArrayStart, Invalid}, docs: []int{0, 1, 1}},
+		{in: "1\n[z, 1]\ntrux\n\n[2]", opts: []ParserOption{WithSkipBadDocuments()},
+			toks: []Token{Number, ArrayStart, Invalid, Invalid, ArrayStart, Number, ArrayEnd},
+			docs: []int{0, 1, 1, 2, 3, 3, 3},
+			errs: []error{nil, nil, &SyntaxError{Char: 'z', Line: 2, Column: 2, Offset: 3, typ: begVal},
+				&LiteralError{Line: 3, Column: 4, Offset: 12, want: 'e', got: 'x', tok: True}, nil, nil, nil}},
+		{in: "\"a\nb\"\n3", opts: []ParserOption{WithSkipBadDocuments()}, toks: []Token{Invalid, Invalid, Number}, docs: []int{0, 1, 2},
+			errs: []error{&SyntaxError{Char: '\n', Line: 1, Column: 3, Offset: 2, typ: strLit}, &SyntaxError{Char: 'b', Line: 2, Column: 1, Offset: 3, typ: begVal}, nil}},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), append(c.opts, WithErrorContext(0))...)
+
+		var toks []Token
+		var docs []int
+		var errs []error
+		for p.Next() {
+			toks = append(toks, p.Token())
+			docs = append(docs, p.Document())
+			errs = append(errs, p.Err())
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d: want tokens %v, got %v", i, c.toks, toks)
+		}
+		if !reflect.DeepEqual(c.docs, docs) {
+			t.Errorf("%d: want documents %v, got %v", i, c.docs, docs)
+		}
+		if c.errs != nil && !reflect.DeepEqual(c.errs, errs) {
+			t.Errorf("%d: want errors %v, got %v", i, c.errs, errs)
+		}
+	}
+}
+
+func TestErrorContextOption(t *testing.T) {
+	long := strings.Repeat("1234567890", 3)
+	cases := []struct {
+		in   string
+		opts []ParserOption
+		near string
+	}{
+		{in: `z`, near: `z`},
+		{in: `[1, 2, z, 3]`, near: `[1, 2, z, 3]`},
+		{in: `[` + long + `, "a", z, "b", ` + long + `]`, near: `8901234567890, "a", z, "b", 123456789012`},
+		{in: `[` + long + `, "a", z`, near: `8901234567890, "a", z`},
+		{in: `[` + long + `, nul1, ` + long + `]`, near: `678901234567890, nul1, 12345678901234567`},
+		{in: `[` + long + `, "ééééééééé", z]`, near: `éééééééé", z]`},
+		{in: `[1, 2, z, 3]`, opts: []ParserOption{WithErrorContext(4)}, near: `, z,`},
+		{in: `[1, 2, z, 3]`, opts: []ParserOption{WithErrorContext(0)}},
+		{in: `[1, 2`},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), c.opts...)
+		for p.Next() {
+		}
+
+		var near []byte
+		switch err := p.Err().(type) {
+		case *SyntaxError:
+			near = err.Near
+		case *LiteralError:
+			near = err.Near
+		}
+		if string(near) != c.near || (c.near == "") != (near == nil) {
+			t.Errorf("%d: want %q, got %q", i, c.near, near)
+		}
+	}
+
+	// the context does not read past the end of the line of an invalid document
+	p := NewParserOptions(strings.NewReader("[1, z]\n[2]"), WithMultiValue(), WithSkipBadDocuments())
+	var toks []Token
+	for p.Next() {
+		toks = append(toks, p.Token())
+	}
+	want := []Token{ArrayStart, Number, Invalid, ArrayStart, Number, ArrayEnd}
+	if !reflect.DeepEqual(want, toks) || p.Err() != nil {
+		t.Errorf("want %v, got %v (%v)", want, toks, p.Err())
+	}
+}
+
+func TestLimitErrors(t *testing.T) {
+	opts := []ParserOption{WithMaxDepth(2), WithMaxStringLen(4), WithMaxNumberLen(3), WithMaxTokens(6)}
+	cases := []struct {
+		in     string
+		target interface{}
+	}{
+		{in: `[[[]]]`, target: new(*DepthLimitError)},
+		{in: `["abcd"]`, target: new(*StringLenError)},
+		{in: `[1234]`, target: new(*NumberLenError)},
+		{in: `[1, 2, 3, 4, 5, 6]`, target: new(*TokenLimitError)},
+	}
+
+	targets := []interface{}{new(*DepthLimitError), new(*StringLenError), new(*NumberLenError), new(*TokenLimitError)}
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), opts...)
+		for p.Next() {
+		}
+		err := p.Err()
+		if errors.Is(err, ErrSyntax) || errors.Is(err, ErrLiteral) {
+			t.Errorf("%d: want a limit error, got %v", i, err)
+		}
+		for _, target := range targets {
+			want := reflect.TypeOf(target) == reflect.TypeOf(c.target)
+			if got := errors.As(err, target); got != want {
+				t.Errorf("%d: want errors.As(%T) = %t, got %t (%v)", i, target, want, got, err)
+			}
+		}
+	}
+}
+
+func TestKeyValidatorOption(t *testing.T) {
+	errDollar := errors.New("key starts with $")
+	var raw []string
+	validate := func(key []byte) error {
+		raw = append(raw, string(key))
+		if len(key) > 1 && key[1] == '$' {
+			return errDollar
+		}
+		return nil
+	}
+
+	p := NewParserOptions(strings.NewReader(`{"a": {"b\u0024": 1, "$c": 2}}`), WithKeyValidator(validate))
+	var toks []Token
+	for p.Next() {
+		toks = append(toks, p.Token())
+	}
+	if want := []Token{ObjectStart, ObjectKey, ObjectStart, ObjectKey, Number, Invalid}; !reflect.DeepEqual(want, toks) {
+		t.Errorf("want %v, got %v", want, toks)
+	}
+	if want := []string{`"a"`, `"b\u0024"`, `"$c"`}; !reflect.DeepEqual(want, raw) {
+		t.Errorf("want keys %v, got %v", want, raw)
+	}
+	want := &KeyValidationError{Key: "$c", Offset: 21, Cause: errDollar}
+	if err := p.Err(); !reflect.DeepEqual(want, err) || !errors.Is(err, errDollar) {
+		t.Errorf("want %v, got %v", want, err)
+	}
+}
+
+func TestOnTokenOption(t *testing.T) {
+	var got []string
+	onToken := func(tok Token, b []byte) {
+		got = append(got, tok.String()+" "+string(b))
+	}
+	var errs []error
+	onError := func(err error) {
+		errs = append(errs, err)
+	}
+
+	p := NewParserOptions(strings.NewReader(`{"a": [1, true]} 2`), WithOnToken(onToken), WithOnError(onError))
+	var toks []Token
+	for p.Next() {
+		toks = append(toks, p.Token())
+	}
+	want := []string{"{ {", `key "a"`, "[ [", "number 1", "true true", "] ]", "} }"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if len(toks) != len(got) {
+		t.Errorf("want %d tokens, got %d", len(got), len(toks))
+	}
+	if len(errs) != 1 || errs[0] != p.Err() {
+		t.Errorf("want error %v, got %v", p.Err(), errs)
+	}
+
+	// no error at the end of the input
+	errs = nil
+	p = NewParserOptions(strings.NewReader(`[]`), WithOnError(onError))
+	for p.Next() {
+	}
+	if errs != nil {
+		t.Errorf("want no error, got %v", errs)
+	}
+}
+
+func TestResetOptions(t *testing.T) {
+	p := NewParserOptions(strings.NewReader(`[[[1]]]`), WithChunkSize(64))
+	for p.Next() {
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	stack := cap(p.stack)
+
+	p.Reset(strings.NewReader(`[[[1]]]`), WithChunkSize(1024), WithMaxDepth(2), WithErrorContext(0))
+	if p.size != 1024 {
+		t.Errorf("want chunk size 1024, got %d", p.size)
+	}
+	if p.ring != nil {
+		t.Error("want no error context buffer")
+	}
+	if len(p.stack) != 0 || cap(p.stack) != stack {
+		t.Errorf("want empty stack with capacity %d, got %d/%d", stack, len(p.stack), cap(p.stack))
+	}
+	for p.Next() {
+	}
+	if want := (&DepthLimitError{Limit: 2}); !reflect.DeepEqual(want, p.Err()) {
+		t.Errorf("want %v, got %v", want, p.Err())
+	}
+
+	// the options remain in effect
+	p.ResetString(`[[[1]]]`, WithErrorContext(10))
+	for p.Next() {
+	}
+	if want := (&DepthLimitError{Limit: 2}); !reflect.DeepEqual(want, p.Err()) {
+		t.Errorf("want %v, got %v", want, p.Err())
+	}
+	if p.size != 1024 || len(p.ring) != 10/2+utf8.UTFMax {
+		t.Errorf("want chunk size 1024 and error context, got %d and %d", p.size, len(p.ring))
+	}
+}
+
+func BenchmarkStackCapacity(b *testing.B) {
+	doc := []byte(strings.Repeat("[", 100) + strings.Repeat("]", 100))
+	for _, c := range []struct {
+		name string
+		opts []ParserOption
+	}{
+		{"Default", nil},
+		{"Capacity100", []ParserOption{WithStackCapacity(100)}},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := NewParserOptions(bytes.NewReader(doc), c.opts...)
+				for p.Next() {
+				}
+				if err := p.Err(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestQuotesOptions(t *testing.T) {
+	single := []ParserOption{WithAllowSingleQuotes(), WithErrorContext(0)}
+	unquoted := []ParserOption{WithAllowUnquotedKeys(), WithErrorContext(0)}
+	both := []ParserOption{WithAllowSingleQuotes(), WithAllowUnquotedKeys(), WithErrorContext(0)}
+	cases := []struct {
+		in    string
+		opts  []ParserOption
+		bytes []string
+		err   error
+	}{
+		{in: `'a'`, bytes: []string{``}, err: &SyntaxError{Char: '\'', Line: 1, Column: 1, Offset: 0, typ: begVal}},
+		{in: `'a'`, opts: single, bytes: []string{`"a"`}},
+		{in: `''`, opts: single, bytes: []string{`""`}},
+		{in: `['a"b', 'c\'d', "e\'f", 'g\né']`, opts: single, bytes: []string{`[`, `"a\"b"`, `"c'd"`, `"e'f"`, `"g\né"`, `]`}},
+		{in: `{'a': 'b', "c": 'd'}`, opts: single, bytes: []string{`{`, `"a"`, `"b"`, `"c"`, `"d"`, `}`}},
+		{in: `'a`, opts: single, bytes: []string{`"a`}, err: io.ErrUnexpectedEOF},
+		{in: `"a\'"`, bytes: []string{`"a\`}, err: &SyntaxError{Char: '\'', Line: 1, Column: 4, Offset: 3, typ: chrEsc}},
+		{in: `['a' 'b']`, opts: single, bytes: []string{`[`, `"a"`, ``}, err: &SyntaxError{Char: '\'', Line: 1, Column: 6, Offset: 5, typ: comExp}},
+
+		{in: `{a: 1}`, bytes: []string{`{`, ``}, err: &SyntaxError{Char: 'a', Line: 1, Column: 2, Offset: 1, typ: begKey}},
+		{in: `{a: 1, _b$2 :2, true: 3, "c": 4, été: 5}`, opts: unquoted,
+			bytes: []string{`{`, `"a"`, `1`, `"_b$2"`, `2`, `"true"`, `3`, `"c"`, `4`, `"été"`, `5`, `}`}},
+		{in: `{a:{b:[c]}}`, opts: unquoted, bytes: []string{`{`, `"a"`, `{`, `"b"`, `[`, ``}, err: &SyntaxError{Char: 'c', Line: 1, Column: 8, Offset: 7, typ: begVal}},
+		{in: `{1a: 1}`, opts: unquoted, bytes: []string{`{`, ``}, err: &SyntaxError{Char: '1', Line: 1, Column: 2, Offset: 1, typ: begKey}},
+		{in: `{a-b: 1}`, opts: unquoted, bytes: []string{`{`, `"a"`, ``}, err: &SyntaxError{Char: '-', Line: 1, Column: 3, Offset: 2, typ: colExp}},
+		{in: `{a: 1 b: 2}`, opts: unquoted, bytes: []string{`{`, `"a"`, `1`, ``}, err: &SyntaxError{Char: 'b', Line: 1, Column: 7, Offset: 6, typ: begVal}},
+		{in: `{a`, opts: unquoted, bytes: []string{`{`, `"a"`}, err: io.ErrUnexpectedEOF},
+		{in: `{a: 'b', 'c': d}`, opts: both, bytes: []string{`{`, `"a"`, `"b"`, `"c"`, ``}, err: &SyntaxError{Char: 'd', Line: 1, Column: 15, Offset: 14, typ: begVal}},
+	}
+
+	for i, c := range cases {
+		opts := c.opts
+		if opts == nil {
+			opts = []ParserOption{WithErrorContext(0)}
+		}
+		p := NewParserOptions(strings.NewReader(c.in), opts...)
+		var got []string
+		for p.Next() && p.Token() != Invalid {
+			got = append(got, string(p.Bytes()))
+
+			// the bytes are valid JSON strings
+			if p.Token() == String || p.Token() == ObjectKey {
+				if _, err := p.String(); err != nil {
+					t.Errorf("%d (%s): invalid string %s: %v", i, c.in, p.Bytes(), err)
+				}
+			}
+		}
+		if p.Token() == Invalid {
+			got = append(got, string(p.Bytes()))
+		}
+		if !reflect.DeepEqual(c.bytes, got) {
+			t.Errorf("%d (%s): want %q, got %q", i, c.in, c.bytes, got)
+		}
+		if err := p.Err(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want %v, got error %v", i, c.in, c.err, err)
+		}
+	}
+
+	// the path uses the decoded keys
+	p := NewParserOptions(strings.NewReader(`{a: {'b\'': 1}}`), WithAllowSingleQuotes(), WithAllowUnquotedKeys())
+	for p.Next() && p.Token() != Number {
+	}
+	if want := []string{"a", "b'"}; !reflect.DeepEqual(want, p.Path()) {
+		t.Errorf("want path %q, got %q", want, p.Path())
+	}
+}
+
+func TestRadixNumbersOptions(t *testing.T) {
+	hex := []ParserOption{WithAllowHexNumbers(), WithErrorContext(0)}
+	octal := []ParserOption{WithAllowOctalNumbers(), WithErrorContext(0)}
+	cases := []struct {
+		in   string
+		opts []ParserOption
+		i    int64
+		f    float64
+		kind NumberKind
+		err  error
+	}{
+		{in: `0xFF`, err: &SyntaxError{Char: 'x', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `0xFF`, opts: hex, i: 255, f: 255, kind: NumberInt},
+		{in: `-0x1e`, opts: hex, i: -30, f: -30, kind: NumberInt},
+		{in: `0XdeAD`, opts: hex, i: 0xdead, f: 0xdead, kind: NumberInt},
+		{in: ` 0x7fffffffffffffff `, opts: hex, i: 1<<63 - 1, f: 1 << 63, kind: NumberInt},
+		{in: `0x`, opts: hex, err: &SyntaxError{Char: -1, Line: 1, Column: 3, Offset: 2, typ: endLit}},
+		{in: `0xG`, opts: hex, err: &SyntaxError{Char: 'G', Line: 1, Column: 3, Offset: 2, typ: endLit}},
+		{in: `1x2`, opts: hex, err: &SyntaxError{Char: 'x', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `0.0x2`, opts: hex, err: &SyntaxError{Char: 'x', Line: 1, Column: 4, Offset: 3, typ: endLit}},
+		{in: `0o17`, opts: hex, err: &SyntaxError{Char: 'o', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+
+		{in: `0o17`, opts: octal, i: 15, f: 15, kind: NumberInt},
+		{in: `-0O755`, opts: octal, i: -0755, f: -0755, kind: NumberInt},
+		{in: `0o8`, opts: octal, err: &SyntaxError{Char: '8', Line: 1, Column: 3, Offset: 2, typ: endLit}},
+		{in: `0xF`, opts: octal, err: &SyntaxError{Char: 'x', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+	}
+
+	for i, c := range cases {
+		opts := c.opts
+		if opts == nil {
+			opts = []ParserOption{WithErrorContext(0)}
+		}
+		p := NewParserOptions(strings.NewReader(c.in), opts...)
+		p.Next()
+		if err := p.Err(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want %v, got error %v", i, c.in, c.err, err)
+		}
+		if c.err != nil {
+			continue
+		}
+		if got := string(p.Bytes()); got != strings.TrimSpace(c.in) {
+			t.Errorf("%d (%s): want bytes %s, got %s", i, c.in, c.in, got)
+		}
+		if n, err := p.Int64(); err != nil || n != c.i {
+			t.Errorf("%d (%s): want Int64 %d, got %d (%v)", i, c.in, c.i, n, err)
+		}
+		if f, err := p.Float64(); err != nil || f != c.f {
+			t.Errorf("%d (%s): want Float64 %g, got %g (%v)", i, c.in, c.f, f, err)
+		}
+		if k := p.NumberKind(); k != c.kind {
+			t.Errorf("%d (%s): want kind %s, got %s", i, c.in, c.kind, k)
+		}
+		if n, err := p.BigInt(); err != nil || n.Int64() != c.i {
+			t.Errorf("%d (%s): want BigInt %d, got %v (%v)", i, c.in, c.i, n, err)
+		}
+	}
+
+	// out of range
+	p := NewParserOptions(strings.NewReader(`[0x8000000000000000, -0x1]`), WithAllowHexNumbers())
+	p.Next()
+	p.Next()
+	if _, err := p.Int64(); !isRangeError(err) {
+		t.Errorf("want range error, got %v", err)
+	}
+	if u, err := p.Uint64(); err != nil || u != 1<<63 {
+		t.Errorf("want Uint64 %d, got %d (%v)", uint64(1<<63), u, err)
+	}
+	p.Next()
+	if _, err := p.Uint64(); !isRangeError(err) {
+		t.Errorf("want range error, got %v", err)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	cases := []struct {
+		in   string
+		toks []Token
+		errs []error
+	}{
+		{in: `{"a": 1}`, toks: []Token{ObjectStart, ObjectKey, Number, ObjectEnd}},
+		{in: `{"a": 1} x`, toks: []Token{ObjectStart, ObjectKey, Number, ObjectEnd},
+			errs: []error{&SyntaxError{Char: 'x', Line: 1, Column: 10, Offset: 9, typ: endLit}}},
+		{in: "1 }x\n\t2  ", toks: []Token{Number},
+			errs: []error{
+				&SyntaxError{Char: '}', Line: 1, Column: 3, Offset: 2, typ: endLit},
+				&SyntaxError{Char: '2', Line: 2, Column: 2, Offset: 6, typ: endLit},
+			}},
+		{in: `[1,]`, toks: []Token{ArrayStart, Number},
+			errs: []error{&SyntaxError{Char: ']', Line: 1, Column: 4, Offset: 3, typ: begVal}}},
+		{in: `true [`, toks: []Token{True},
+			errs: []error{&SyntaxError{Char: '[', Line: 1, Column: 6, Offset: 5, typ: endLit}}},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), WithCollectErrors(), WithErrorContext(0))
+		var toks []Token
+		for p.Next() && p.Token() != Invalid {
+			toks = append(toks, p.Token())
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d (%q): want tokens %v, got %v", i, c.in, c.toks, toks)
+		}
+		if errs := p.Errs(); !reflect.DeepEqual(c.errs, errs) {
+			t.Errorf("%d (%q): want errors %v, got %v", i, c.in, c.errs, errs)
+		}
+		var first error
+		if len(c.errs) > 0 {
+			first = c.errs[0]
+		}
+		if err := p.Err(); !reflect.DeepEqual(first, err) {
+			t.Errorf("%d (%q): want error %v, got %v", i, c.in, first, err)
+		}
+	}
+
+	// the context of an error stops at the next whitespace
+	var collected []error
+	p := NewParserOptions(strings.NewReader(`1 ab cd`), WithCollectErrors(), WithOnError(func(err error) {
+		collected = append(collected, err)
+	}))
+	for p.Next() {
+	}
+	if len(collected) != 2 {
+		t.Fatalf("want 2 errors reported, got %d", len(collected))
+	}
+	if near := string(p.Errs()[0].(*SyntaxError).Near); near != "1 ab " {
+		t.Errorf("want context %q, got %q", "1 ab ", near)
+	}
+	errs := p.Errs()
+	if want, got := errs[0].Error()+"\n"+errs[1].Error(), MultiError(errs).Error(); want != got {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if !errors.Is(MultiError(collected), collected[1]) {
+		t.Errorf("want MultiError to wrap its errors")
+	}
+}
@@ -3,16 +3,28 @@ package jsonb
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const DefaultChunkSize = 32 << 10 // 32K
 
 const minChunkSize = 5
 
+// defaultCheckInterval is the default number of runes read between checks
+// of the context of a parser.
+const defaultCheckInterval = 1024
+
+// defaultErrorContext is the default number of bytes of input surrounding
+// an invalid character that are stored in the error.
+const defaultErrorContext = 40
+
 const (
 	begVal = iota
 	strLit
@@ -21,11 +33,36 @@ const (
 	endLit
 	zroLit
 	comExp
+	begKey
+	colExp
+	bomLit
+)
+
+// bom is the byte order mark, only allowed at the start of the input when
+// the parser strips it.
+const bom = '\uFEFF'
+
+var (
+	// ErrSyntax matches any *SyntaxError with errors.Is.
+	ErrSyntax = errors.New("jsonb: syntax error")
+
+	// ErrLiteral matches any *LiteralError with errors.Is.
+	ErrLiteral = errors.New("jsonb: invalid literal")
+
+	// ErrNotSeekable is returned by Rewind when the reader of the parser
+	// does not implement io.Seeker.
+	ErrNotSeekable = errors.New("jsonb: reader is not seekable")
+
+	errInvalidCodePoint = errors.New("jsonb: invalid unicode code point")
 )
 
 type SyntaxError struct {
-	Char rune
-	typ  int
+	Char   rune
+	Line   int    // 1-based line of the invalid character
+	Column int    // 1-based column of the invalid character
+	Offset int64  // 0-based byte offset of the invalid character
+	Near   []byte // input surrounding the invalid character, see WithErrorContext
+	typ    int
 }
 
 func (s *SyntaxError) Error() string {
@@ -46,17 +83,56 @@ func (s *SyntaxError) Error() string {
 		suffix = " after top-level value 0"
 	case comExp:
 		suffix = " looking for a comma"
+	case begKey:
+		suffix = " looking for beginning of object key string"
+	case colExp:
+		suffix = " after object key"
+	case bomLit:
+		suffix = " looking for beginning of value (byte order mark)"
+	}
+	return fmt.Sprintf("%d:%d: invalid character %q at offset %d"+suffix, s.Line, s.Column, s.Char, s.Offset)
+}
+
+// Is returns true if target is ErrSyntax, or a *SyntaxError of the same
+// type for the same character. If the character of target is 0, it matches
+// any character.
+func (s *SyntaxError) Is(target error) bool {
+	if target == ErrSyntax {
+		return true
+	}
+	t, ok := target.(*SyntaxError)
+	if !ok || t.typ != s.typ {
+		return false
 	}
-	return fmt.Sprintf("invalid character %q"+suffix, s.Char)
+	return t.Char == 0 || t.Char == s.Char
 }
 
 type LiteralError struct {
+	Line   int    // 1-based line of the invalid character
+	Column int    // 1-based column of the invalid character
+	Offset int64  // 0-based byte offset of the invalid character
+	Near   []byte // input surrounding the invalid character, see WithErrorContext
+
 	want, got rune
 	tok       Token
 }
 
 func (l *LiteralError) Error() string {
-	return fmt.Sprintf("invalid character %q in literal %s (expecting %q)", l.got, l.tok, l.want)
+	return fmt.Sprintf("%d:%d: invalid character %q at offset %d in literal %s (expecting %q)", l.Line, l.Column, l.got, l.Offset, l.tok, l.want)
+}
+
+// Is returns true if target is ErrLiteral, or a *LiteralError in the same
+// literal for the same characters. If the characters of target are 0, it
+// matches any character in that literal.
+func (l *LiteralError) Is(target error) bool {
+	if target == ErrLiteral {
+		return true
+	}
+	t, ok := target.(*LiteralError)
+	if !ok || t.tok != l.tok {
+		return false
+	}
+	return t.want == 0 && t.got == 0 || t.want == l.want && t.got == l.got
 }
 
 type Token int
@@ -72,6 +148,7 @@ const (
 	ArrayEnd
 	ArrayStart
 	ObjectStart
+	ObjectKey
 )
 
 var (
@@ -86,6 +163,7 @@ var (
 		ArrayEnd:    "]",
 		ObjectStart: "{",
 		ObjectEnd:   "}",
+		ObjectKey:   "key",
 	}
 )
 
@@ -93,10 +171,51 @@ func (t Token) String() string {
 	return tokenString[t]
 }
 
+// IsValue returns true for the tokens of scalar values: Null, False, True,
+// String and Number.
+func (t Token) IsValue() bool {
+	return t >= Null && t <= Number
+}
+
+// IsContainer returns true for the tokens that start or end an array or an
+// object.
+func (t Token) IsContainer() bool {
+	return t >= ObjectEnd && t <= ObjectStart
+}
+
+// IsStart returns true for ArrayStart and ObjectStart.
+func (t Token) IsStart() bool {
+	return t == ArrayStart || t == ObjectStart
+}
+
+// IsEnd returns true for ArrayEnd and ObjectEnd.
+func (t Token) IsEnd() bool {
+	return t == ArrayEnd || t == ObjectEnd
+}
+
+// Opposite returns the end token that matches a start token and the start
+// token that matches an end token. It returns Invalid for other tokens.
+func (t Token) Opposite() Token {
+	switch t {
+	case ArrayStart:
+		return ArrayEnd
+	case ArrayEnd:
+		return ArrayStart
+	case ObjectStart:
+		return ObjectEnd
+	case ObjectEnd:
+		return ObjectStart
+	}
+	return Invalid
+}
+
 var (
 	nullLiteral  = []byte{'u', 'l', 'l'}
 	trueLiteral  = []byte{'r', 'u', 'e'}
 	falseLiteral = []byte{'a', 'l', 's', 'e'}
+
+	nanLiteral      = []byte{'a', 'N'}
+	infinityLiteral = []byte{'n', 'f', 'i', 'n', 'i', 't', 'y'}
 )
 
 type state byte
@@ -111,55 +230,353 @@ type Parser struct {
 	// "JSON text is a sequence of Unicode code points."
 	// Therefore, the parser uses a rune reader. If it finds
 	// an invalid rune, it is a syntax error in the JSON document.
-	r io.RuneReader
+	r    io.RuneReader
+	src  io.Reader      // the reader set by Reset, used by Rewind
+	br   bytes.Reader   // used as reader when parsing a byte slice
+	sr   strings.Reader // used as reader when parsing a string
+	bufr *bufio.Reader  // used to wrap readers that are not io.RuneReader
 
-	// If a single raw value spans more than the specified size,
-	// the value is parsed in multiple chunks of at most size bytes.
-	// The minimum size allowed is 5 bytes, so that true, false and null
-	// can be parsed without chunks.
-	size int64
+	closer    io.Closer // the reader, if it implements io.Closer
+	autoClose bool      // close the reader when the input is exhausted
 
 	ch    rune         // current rune
 	err   error        // first error encountered
+	errs  MultiError   // errors collected before err, if collect
 	buf   bytes.Buffer // internal buffer
 	tok   Token        // current token
 	chunk bool         // in a chunk
 	stack []state
+
+	line  int   // line of the current rune
+	col   int   // column of the current rune
+	nl    bool  // current rune is a newline
+	off   int64 // byte offset of the current rune
+	width int   // size in bytes of the current rune
+	start int64 // byte offset of the current token
+
+	path []pathFrame // path segments, one per stack level
+	keys []byte      // object keys of the path, stacked in order
+
+	keyBuf []byte // raw bytes of the last object key
+	keyed  int    // > 0 while the last object key applies to the current token
+
+	ntok int64 // number of tokens emitted
+	docs int   // number of top-level values started
+	bad  bool  // the current document is invalid and must be skipped
+	done bool  // Next returned false at the end of the input
+
+	warnings []Warning // uses of non-standard features, if warnExt
+
+	// counters of the tokens of the current document, see Stats, with the
+	// number of tokens and bytes at its start in Tokens and Bytes
+	stats ParseStats
+
+	config // set by the options
+
+	seen []map[string]struct{} // keys of the objects, by depth, if dupKeys
+
+	ctx       context.Context // cancels the parsing, if set
+	unchecked int             // number of runes read since the last check of ctx
+
+	peeked     bool     // the next token has been parsed by Peek
+	cur, ahead snapshot // current and next tokens while peeked
+
+	ring  []byte // last bytes read, for the context of errors
+	nring int64  // number of bytes written to ring
+
+	parent *Parser // parser that reads the tokens of a sub-parser
+	sub    *Parser // active sub-parser, the parser is blocked until it is done
+	root   int     // depth in the parent of the root container of a sub-parser
+}
+
+// config is the configuration of a parser, set by the options.
+type config struct {
+	// If a single raw value spans more than the specified size,
+	// the value is parsed in multiple chunks of at most size bytes.
+	// The minimum size allowed is 5 bytes, so that true, false and null
+	// can be parsed without chunks.
+	size int64
+
+	// limits and behaviour
+	maxDepth         int
+	maxStringLen     int
+	maxNumberLen     int
+	maxTokens        int64
+	floatOverflow    bool
+	multi            bool // allow multiple top-level values
+	skipBad          bool // skip invalid documents in multi-value mode
+	collect          bool // collect the errors of trailing content
+	warnExt          bool // record the use of non-standard features
+	discard          bool // do not store the bytes of the tokens
+	dupKeys          bool // reject duplicate object keys
+	strictSurrogates bool // reject unpaired surrogates in \u escapes
+	stripBOM         bool // skip a leading byte order mark
+	comments         bool // allow comments where whitespace is allowed
+	trailingCommas   bool // allow a comma before the end of a container
+	specialFloats    bool // allow NaN, Infinity and -Infinity numbers
+	singleQuotes     bool // allow single-quoted strings
+	unquotedKeys     bool // allow identifiers as object keys
+	hexNumbers       bool // allow 0x-prefixed hexadecimal integers
+	octalNumbers     bool // allow 0o-prefixed octal integers
+
+	keyValidator func(key []byte) error // validates the raw object keys, if set
+	onToken      func(Token, []byte)    // called for each token, if set
+	onError      func(error)            // called for each error, if set
+
+	interval int // number of runes read between checks of ctx
+	near     int // number of bytes of context stored in errors
+}
+
+// pathFrame holds the current path segment of an array or object.
+type pathFrame struct {
+	idx int // index of the current element or key, -1 if none yet
+	key int // start of the object key in the keys buffer
 }
 
 func NewParser(r io.Reader) *Parser {
-	return NewParserSize(r, DefaultChunkSize)
+	return NewParserOptions(r)
 }
 
 func NewParserSize(r io.Reader, size int64) *Parser {
-	if size < minChunkSize {
-		size = minChunkSize
-	}
-	return &Parser{
-		r:    getRuneReader(r),
-		size: size,
+	return NewParserOptions(r, WithChunkSize(size))
+}
+
+// NewParserBytes returns a parser that reads from b. It is equivalent to
+// NewParser(bytes.NewReader(b)), but the bytes.Reader is embedded in the
+// parser instead of being allocated separately.
+func NewParserBytes(b []byte) *Parser {
+	p := newParser(nil)
+	p.ResetBytes(b)
+	return p
+}
+
+// NewParserString returns a parser that reads from s. Like NewParserBytes,
+// the strings.Reader is embedded in the parser and the string is not copied.
+func NewParserString(s string) *Parser {
+	p := newParser(nil)
+	p.ResetString(s)
+	return p
+}
+
+// newParser returns a parser without reader, configured with opts.
+func newParser(opts []ParserOption) *Parser {
+	p := &Parser{
 		ch:   -1,
 		tok:  Invalid,
+		line: 1,
+
+		config: config{
+			size:     DefaultChunkSize,
+			interval: defaultCheckInterval,
+			near:     defaultErrorContext,
+		},
+	}
+	p.configure(opts)
+	return p
+}
+
+// configure applies opts to the parser and allocates the buffers that
+// depend on its configuration.
+func (p *Parser) configure(opts []ParserOption) {
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.near <= 0 {
+		p.ring = nil
+	} else if n := p.near/2 + utf8.UTFMax; len(p.ring) != n {
+		// the preceding bytes and the current rune
+		p.ring = make([]byte, n)
+	}
+}
+
+// Reset resets the parser to read from r. The opts are applied on top of
+// the current configuration of the parser, and remain in effect for the
+// subsequent resets. If the previous reader of the parser implements
+// io.Closer and is not already closed, it is closed first, see Close.
+func (p *Parser) Reset(r io.Reader, opts ...ParserOption) {
+	p.reset()
+	p.configure(opts)
+	p.setReader(r)
 }
 
-func (p *Parser) Reset(r io.Reader) {
-	p.r = getRuneReader(r)
+// ResetBytes is like Reset, but the parser reads from b using its embedded
+// bytes.Reader.
+func (p *Parser) ResetBytes(b []byte, opts ...ParserOption) {
+	p.reset()
+	p.configure(opts)
+	p.br.Reset(b)
+	p.r = &p.br
+	p.src = &p.br
+}
+
+// ResetString is like Reset, but the parser reads from s using its embedded
+// strings.Reader.
+func (p *Parser) ResetString(s string, opts ...ParserOption) {
+	p.reset()
+	p.configure(opts)
+	p.sr.Reset(s)
+	p.r = &p.sr
+	p.src = &p.sr
+}
+
+// reset clears the parsing state, keeping the internal buffers. The reader
+// is closed if it implements io.Closer.
+func (p *Parser) reset() {
+	p.closeReader()
 	p.ch = -1
 	p.err = nil
+	p.errs = p.errs[:0]
+	p.warnings = p.warnings[:0]
 	p.buf.Reset()
 	p.tok = Invalid
 	p.chunk = false
+	p.line, p.col, p.nl = 1, 0, false
+	p.off, p.width, p.start = 0, 0, 0
+	p.stack = p.stack[:0]
+	p.path = p.path[:0]
+	p.keys = p.keys[:0]
+	p.keyed = 0
+	p.ntok = 0
+	p.stats = ParseStats{}
+	p.docs = 0
+	p.bad = false
+	p.done = false
+	p.unchecked = 0
+	p.peeked = false
+	p.nring = 0
+	p.sub = nil
+	p.closer = nil
+	p.autoClose = false
+}
+
+// Rewind seeks the reader of the parser back to the start of the input and
+// resets the parser to parse the input again, keeping its configuration.
+// It returns ErrNotSeekable if the reader does not implement io.Seeker,
+// and the error of its Seek method if it fails, in which case the parser
+// is unchanged. The file opened by ParseFile can only be rewound until the
+// input is exhausted, as it is closed at that point.
+func (p *Parser) Rewind() error {
+	s, ok := p.src.(io.Seeker)
+	if !ok {
+		return ErrNotSeekable
+	}
+	if _, err := s.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// the reader remains open
+	closer, autoClose := p.closer, p.autoClose
+	p.closer = nil
+	p.reset()
+	p.setReader(p.src)
+	p.closer, p.autoClose = closer, autoClose
+	return nil
+}
+
+// Close closes the reader of the parser if it implements io.Closer and is
+// not already closed, and returns the error of its Close method. The file
+// opened by ParseFile and the gzip reader of NewGzipParser are closed
+// automatically once the input is exhausted. After Close, Next returns false
+// and Err returns io.ErrClosedPipe. Calling Close more than once does
+// nothing. Parser implements io.Closer, so that it can be closed with
+// defer p.Close().
+func (p *Parser) Close() error {
+	if p.err == io.ErrClosedPipe {
+		return nil
+	}
+	err := p.closeReader()
+	p.err = io.ErrClosedPipe
+	p.ch = -1
+	p.bad = false
+	p.peeked = false
+	return err
+}
+
+// closeReader closes the reader of the parser, if it is not already closed.
+func (p *Parser) closeReader() error {
+	if p.closer == nil {
+		return nil
+	}
+	c := p.closer
+	p.closer = nil
+	return c.Close()
 }
 
 func (p *Parser) Next() bool {
+	if p.sub != nil {
+		return p.blocked()
+	}
+	return p.step()
+}
+
+// step returns the token parsed by Peek, if any, or parses the next token.
+func (p *Parser) step() bool {
+	if p.peeked {
+		p.peeked = false
+		p.restore(&p.ahead)
+		return p.ahead.ok
+	}
+	return p.advance()
+}
+
+// advance parses the next token.
+func (p *Parser) advance() bool {
+	if p.parent != nil {
+		return p.advanceSub()
+	}
+	if p.bad {
+		p.resume()
+	}
 	if p.err == nil && p.ch == -1 {
-		// initial call, position the parser on the first non-whitespace rune
-		p.next(true)
+		if p.off == 0 && p.width == 0 {
+			// very first call, check for a byte order mark
+			p.skipBOM()
+		} else {
+			// position the parser on the first non-whitespace rune
+			p.next(true)
+		}
 	}
 	return p.parseValue()
 }
 
+// skipBOM positions the parser on the first non-whitespace rune of the input,
+// skipping a leading UTF-8 byte order mark if the parser strips it.
+func (p *Parser) skipBOM() {
+	if !p.stripBOM {
+		p.next(true)
+		return
+	}
+	if p.next(false) {
+		if p.ch == bom {
+			p.next(true)
+		} else {
+			p.skipWhite()
+		}
+	}
+}
+
+// Skip skips the current value. If the current token is an ArrayStart or
+// an ObjectStart, the parser is advanced up to and including the matching
+// ArrayEnd or ObjectEnd token. For other tokens, the value has already been
+// consumed and Skip does nothing. It returns false if no value can be skipped
+// or if an error is encountered, in which case Err returns the error.
+func (p *Parser) Skip() bool {
+	if p.err != nil && (p.err != io.EOF || p.done) || p.tok == Invalid {
+		return false
+	}
+	if !p.tok.IsStart() {
+		return true
+	}
+
+	depth := len(p.stack) - 1
+	for len(p.stack) > depth {
+		if !p.Next() || p.tok == Invalid {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *Parser) Token() Token {
 	return p.tok
 }
@@ -168,92 +585,440 @@ func (p *Parser) Bytes() []byte {
 	return p.buf.Bytes()
 }
 
+// Offset returns the byte offset in the input of the first byte of the
+// current token.
+func (p *Parser) Offset() int64 {
+	return p.start
+}
+
+// AppendBytes appends the bytes of the current token to dst and returns the
+// extended slice. Unlike the slice returned by Bytes, dst is not invalidated
+// by the next call to Next.
+func (p *Parser) AppendBytes(dst []byte) []byte {
+	return append(dst, p.buf.Bytes()...)
+}
+
+// Key returns the raw bytes, including the double-quotes, of the object key
+// when the current token is that key or the value that immediately follows
+// it. It returns nil otherwise. The bytes are kept in a separate buffer from
+// the one returned by Bytes and remain valid until the next object key.
+func (p *Parser) Key() []byte {
+	if p.keyed == 0 {
+		return nil
+	}
+	return p.keyBuf
+}
+
+// Depth returns the current nesting level of the parser, that is the number
+// of arrays and objects that are currently open. It is 0 at the root of the
+// document, and the ArrayEnd and ObjectEnd tokens are reported at the depth
+// of their enclosing container.
+func (p *Parser) Depth() int {
+	return len(p.stack)
+}
+
+// ContainerKind is the kind of the innermost container of the parser.
+type ContainerKind int
+
+const (
+	NoContainer     ContainerKind = iota // at the root of the document
+	ArrayContainer                       // in an array
+	ObjectContainer                      // in an object
+)
+
+// Container returns the kind of the innermost array or object that is
+// currently open, with the same semantics as Depth.
+func (p *Parser) Container() ContainerKind {
+	l := len(p.stack)
+	switch {
+	case l == 0:
+		return NoContainer
+	case p.stack[l-1] == stArray:
+		return ArrayContainer
+	default:
+		return ObjectContainer
+	}
+}
+
+// InObject returns true if the innermost container is an object.
+func (p *Parser) InObject() bool {
+	return p.Container() == ObjectContainer
+}
+
+// InArray returns true if the innermost container is an array.
+func (p *Parser) InArray() bool {
+	return p.Container() == ArrayContainer
+}
+
+// IsAtRoot returns true if no array or object is currently open.
+func (p *Parser) IsAtRoot() bool {
+	return len(p.stack) == 0
+}
+
+// Path returns the path from the root of the document to the current token.
+// Object keys are returned decoded, and array indices are returned as decimal
+// strings. The returned slice is a copy
+// that the caller may retain.
+func (p *Parser) Path() []string {
+	var path []string
+	for i, f := range p.path {
+		if f.idx < 0 {
+			continue
+		}
+		if p.stack[i] == stArray {
+			path = append(path, strconv.Itoa(f.idx))
+			continue
+		}
+		end := len(p.keys)
+		if i+1 < len(p.path) {
+			end = p.path[i+1].key
+		}
+		path = append(path, string(p.keys[f.key:end]))
+	}
+	return path
+}
+
+// Document returns the 0-based index of the top-level value being parsed.
+// It is always 0 unless the parser accepts multiple top-level values, see
+// WithMultiValue.
+func (p *Parser) Document() int {
+	if p.docs == 0 {
+		return 0
+	}
+	return p.docs - 1
+}
+
+// TokenCount returns the number of valid tokens emitted since the parser was
+// created or reset.
+func (p *Parser) TokenCount() int64 {
+	return p.ntok
+}
+
+// BytesProcessed returns the number of bytes read from the input since the
+// parser was created or reset. The parser reads one rune past the current
+// token, so it may include the first byte of the next token.
+func (p *Parser) BytesProcessed() int64 {
+	return p.off + int64(p.width)
+}
+
 func (p *Parser) Err() error {
+	if len(p.errs) > 0 {
+		return p.errs[0]
+	}
 	if p.err == io.EOF {
 		return nil
 	}
 	return p.err
 }
 
-// getRuneReader makes sure the parser has a RuneReader at his disposition,
-// creating a bufio.Reader if required.
-func getRuneReader(r io.Reader) io.RuneReader {
+// Errs returns all the errors encountered by the parser: the errors
+// collected with WithCollectErrors, followed by the error that stopped the
+// parser, if any. It returns nil if there is no error. The errors can be
+// combined in a single error with MultiError(p.Errs()).
+func (p *Parser) Errs() []error {
+	var errs []error
+	errs = append(errs, p.errs...)
+	if p.err != nil && p.err != io.EOF {
+		errs = append(errs, p.err)
+	}
+	return errs
+}
+
+// setReader makes sure the parser has a RuneReader at his disposition,
+// wrapping r in a bufio.Reader if required. The bufio.Reader is kept
+// and reused by subsequent calls.
+func (p *Parser) setReader(r io.Reader) {
+	p.src = r
+	p.closer, _ = r.(io.Closer)
 	if rr, ok := r.(io.RuneReader); ok {
-		return rr
+		p.r = rr
+		return
 	}
-	return bufio.NewReader(r)
+	if p.bufr == nil {
+		p.bufr = bufio.NewReader(r)
+	} else {
+		p.bufr.Reset(r)
+	}
+	p.r = p.bufr
 }
 
-func (p *Parser) push(st state) {
+func (p *Parser) push(st state) bool {
+	if p.maxDepth > 0 && len(p.stack) >= p.maxDepth {
+		p.error(&DepthLimitError{Limit: p.maxDepth})
+		return false
+	}
 	p.stack = append(p.stack, st)
+	p.path = append(p.path, pathFrame{idx: -1, key: len(p.keys)})
+	if p.dupKeys && st == stObjKey {
+		p.resetSeen(len(p.stack) - 1)
+	}
+	return true
+}
+
+// resetSeen prepares the empty set of keys of the object at depth i,
+// reusing the set of a previous object at the same depth if possible.
+func (p *Parser) resetSeen(i int) {
+	for len(p.seen) <= i {
+		p.seen = append(p.seen, nil)
+	}
+	if p.seen[i] == nil {
+		p.seen[i] = make(map[string]struct{})
+		return
+	}
+	for k := range p.seen[i] {
+		delete(p.seen[i], k)
+	}
 }
 
 func (p *Parser) pop(st state) bool {
 	l := len(p.stack)
 	if l == 0 {
-		p.error(&SyntaxError{Char: p.ch, typ: begVal})
+		p.syntaxError(begVal)
 		return false
 	}
 
 	got := p.stack[l-1]
+	if got == stObjKey {
+		// both object states are closed by the same token
+		got = stObjVal
+	}
 	if got != st {
 		// TODO : better error reporting, see what stdlib does
-		p.error(&SyntaxError{Char: p.ch, typ: begVal})
+		p.syntaxError(begVal)
 		return false
 	}
 	p.stack = p.stack[:l-1]
+	p.keys = p.keys[:p.path[l-1].key]
+	p.path = p.path[:l-1]
 	return true
 }
 
+// setState replaces the state of the innermost container.
+func (p *Parser) setState(st state) {
+	p.stack[len(p.stack)-1] = st
+}
+
+// element records the start of a new value in the innermost container,
+// if that container is an array.
+func (p *Parser) element() {
+	l := len(p.stack)
+	if l > 0 && p.stack[l-1] == stArray {
+		p.path[l-1].idx++
+	}
+}
+
+// key records the decoded object key in the internal buffer as the current
+// path segment of the innermost object.
+func (p *Parser) key() {
+	l := len(p.stack)
+	f := &p.path[l-1]
+	f.idx++
+	// the parser already validated the string literal, it cannot fail
+	p.keys, _ = appendUnquote(p.keys[:f.key], p.buf.Bytes())
+
+	p.keyBuf = append(p.keyBuf[:0], p.buf.Bytes()...)
+	p.keyed = 2 // the key itself and the following value
+
+	if p.dupKeys {
+		seen := p.seen[l-1]
+		k := string(p.keys[f.key:])
+		if _, ok := seen[k]; ok {
+			p.error(&DuplicateKeyError{Key: k, Offset: p.start})
+			return
+		}
+		seen[k] = struct{}{}
+	}
+}
+
+// validateKey calls the key validator for the current ObjectKey token,
+// setting the error if the key is rejected.
+func (p *Parser) validateKey() {
+	if err := p.keyValidator(p.buf.Bytes()); err != nil {
+		f := p.path[len(p.path)-1]
+		p.error(&KeyValidationError{Key: string(p.keys[f.key:]), Offset: p.start, Cause: err})
+	}
+}
+
+// badDocument returns true if the parser failed on an invalid document,
+// as opposed to a failure to read the input.
+func (p *Parser) badDocument() bool {
+	switch p.err.(type) {
+	case *SyntaxError, *LiteralError:
+		return true
+	}
+	return false
+}
+
+// resume skips the rest of the line of an invalid document and clears
+// the error so that parsing can continue with the next document.
+func (p *Parser) resume() {
+	p.bad = false
+	p.err = nil
+	p.tok = Invalid
+	p.stack = p.stack[:0]
+	p.path = p.path[:0]
+	p.keys = p.keys[:0]
+	p.keyed = 0
+
+	for !p.nl && p.next(false) {
+	}
+	if p.err == nil {
+		p.ch = -1 // position the parser on the next value
+	}
+}
+
+// parseValue parses the next token, enforcing the limit on the number
+// of tokens.
 func (p *Parser) parseValue() bool {
+	ok := p.parseToken()
+	if p.skipBad && p.badDocument() {
+		// report the invalid document, parsing resumes on the next line
+		p.tok = Invalid
+		p.bad = true
+		ok = true
+	} else if ok && p.tok != Invalid {
+		p.count()
+		if p.maxTokens > 0 && p.TokenCount() > p.maxTokens {
+			p.error(&TokenLimitError{Limit: p.maxTokens})
+			ok = false
+		}
+	}
+	if ok && p.onToken != nil {
+		p.onToken(p.tok, p.buf.Bytes())
+	}
+	return ok
+}
+
+func (p *Parser) parseToken() bool {
 	if p.err != nil {
+		if p.err == io.EOF && len(p.stack) > 0 {
+			// end of input in the middle of an array or object
+			p.err = io.ErrUnexpectedEOF
+		}
+		p.done = true
 		return false
 	}
 
 	p.buf.Reset()
+	if p.keyed > 0 {
+		p.keyed--
+	}
+	if len(p.stack) == 0 && p.docs > 0 && !p.multi && p.collect {
+		p.collectTrailing()
+		return false
+	}
+	if len(p.stack) == 0 && p.ch != ',' {
+		// start of a top-level value, a comma is reported below
+		if p.docs > 0 && !p.multi {
+			p.syntaxError(endLit)
+			return false
+		}
+		if p.docs > 0 {
+			// the statistics are those of the last document
+			p.stats = ParseStats{Tokens: p.ntok, Bytes: p.off}
+		}
+		p.docs++
+	}
 	comma := false
+	var commaOff int64
 	wantComma := p.wantComma()
+	wantColon := p.wantColon()
 	wantValue := false
 
 try:
+	if wantColon && p.ch != ':' {
+		p.syntaxError(colExp)
+		return false
+	}
+	wantKey := p.wantKey(wantColon)
+	p.start = p.off
+
+	if wantKey && p.unquotedKeys && isIdentifierStart(p.ch) {
+		return p.parseStringToken(wantComma, wantKey)
+	}
+
 	switch p.ch {
 	case '{':
 		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+			p.syntaxError(comExp)
+			return false
+		}
+		if wantKey {
+			p.syntaxError(begKey)
 			return false
 		}
 
+		p.tok = ObjectStart
+		p.element()
+		if !p.push(stObjKey) {
+			return false
+		}
+		p.store()
+		p.next(true) // always make progress
+		return true
+
 	case '}':
-		if wantValue {
-			p.error(&SyntaxError{Char: p.ch, typ: begVal})
+		if wantValue && !(comma && p.trailingCommas) {
+			typ := begVal
+			if wantKey {
+				typ = begKey
+			} else {
+				p.element()
+			}
+			p.syntaxError(typ)
 			return false
 		}
 
-	case ':':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+		p.tok = ObjectEnd
+		if !p.pop(stObjVal) {
 			return false
 		}
-		if wantValue {
-			p.error(&SyntaxError{Char: p.ch, typ: begVal})
+		if comma {
+			p.warn("trailing comma", commaOff)
+		}
+		p.store()
+		p.next(true) // always make progress
+		return true
+
+	case ':':
+		if !wantColon {
+			typ := begVal
+			if wantComma {
+				typ = comExp
+			}
+			p.syntaxError(typ)
 			return false
 		}
 
+		p.setState(stObjVal)
+		wantColon = false
+		wantValue = true // a value must follow the colon
+		p.next(true)
+		goto try
+
 	case '[':
 		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+			p.syntaxError(comExp)
+			return false
+		}
+		if wantKey {
+			p.syntaxError(begKey)
 			return false
 		}
 
 		p.tok = ArrayStart
-		p.push(stArray)
+		p.element()
+		if !p.push(stArray) {
+			return false
+		}
 		p.store()
 		p.next(true) // always make progress
 		return true
 
 	case ']':
-		if wantValue {
-			p.error(&SyntaxError{Char: p.ch, typ: begVal})
+		if wantValue && !(comma && p.trailingCommas) {
+			p.element()
+			p.syntaxError(begVal)
 			return false
 		}
 
@@ -261,71 +1026,138 @@ try:
 		if !p.pop(stArray) {
 			return false
 		}
+		if comma {
+			p.warn("trailing comma", commaOff)
+		}
 		p.store()
 		p.next(true) // always make progress
 		return true
 
 	case ',':
 		if comma || !wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: begVal})
+			p.syntaxError(begVal)
 			return false
 		}
 
+		if p.stack[len(p.stack)-1] == stObjVal {
+			// a key must follow the comma in an object
+			p.setState(stObjKey)
+		}
 		comma = true
+		commaOff = p.off
 		wantComma = false
 		wantValue = true // a value must follow the comma
 		p.next(true)
 		goto try
 
 	case 't':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+		if !p.canStartValue(wantComma, wantKey) {
 			return false
 		}
 
 		p.tok = True
+		p.element()
 		p.parseLiteral(trueLiteral)
 
 	case 'f':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+		if !p.canStartValue(wantComma, wantKey) {
 			return false
 		}
 
 		p.tok = False
+		p.element()
 		p.parseLiteral(falseLiteral)
 
 	case 'n':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+		if !p.canStartValue(wantComma, wantKey) {
 			return false
 		}
 
 		p.tok = Null
+		p.element()
 		p.parseLiteral(nullLiteral)
 
 	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
+		if !p.canStartValue(wantComma, wantKey) {
 			return false
 		}
 
 		p.tok = Number
+		p.element()
 		p.parseNumber()
 
 	case '"':
-		if wantComma {
-			p.error(&SyntaxError{Char: p.ch, typ: comExp})
-			return false
+		return p.parseStringToken(wantComma, wantKey)
+
+	default:
+		if p.singleQuotes && p.ch == '\'' {
+			return p.parseStringToken(wantComma, wantKey)
+		}
+		if p.specialFloats && (p.ch == 'N' || p.ch == 'I') {
+			if !p.canStartValue(wantComma, wantKey) {
+				return false
+			}
+
+			p.tok = Number
+			p.element()
+			p.parseSpecialFloat()
+			break
 		}
 
+		typ := begVal
+		if wantKey {
+			typ = begKey
+		} else if !wantComma {
+			p.element()
+		}
+		if p.ch == bom && p.off == 0 {
+			typ = bomLit
+		}
+		p.syntaxError(typ)
+	}
+
+	return true
+}
+
+// parseStringToken parses a String or an ObjectKey token, depending on
+// wantKey, returning false if it cannot start at the current position.
+func (p *Parser) parseStringToken(wantComma, wantKey bool) bool {
+	if wantComma {
+		p.syntaxError(comExp)
+		return false
+	}
+
+	if wantKey {
+		p.tok = ObjectKey
+	} else {
 		p.tok = String
+		p.element()
+	}
+	if p.ch == '"' || p.ch == '\'' {
 		p.parseString()
-
-	default:
-		p.error(&SyntaxError{Char: p.ch, typ: begVal})
+	} else {
+		p.parseIdentifier()
+	}
+	if p.tok == ObjectKey && !p.discard {
+		p.key()
+		if p.keyValidator != nil && p.err == nil {
+			p.validateKey()
+		}
 	}
+	return true
+}
 
+// canStartValue returns true if a literal value can start at the current
+// position, setting the error otherwise.
+func (p *Parser) canStartValue(wantComma, wantKey bool) bool {
+	if wantComma {
+		p.syntaxError(comExp)
+		return false
+	}
+	if wantKey {
+		p.syntaxError(begKey)
+		return false
+	}
 	return true
 }
 
@@ -334,7 +1166,9 @@ func (p *Parser) parseLiteral(exp []byte) {
 	for _, r := range exp {
 		p.next(false)
 		if rune(r) != p.ch {
-			p.error(&LiteralError{want: rune(r), got: p.ch, tok: p.tok})
+			err := &LiteralError{want: rune(r), got: p.ch, tok: p.tok, Line: p.line, Column: p.col, Offset: p.off}
+			err.Near = p.errorContext()
+			p.error(err)
 			return
 		}
 		p.store()
@@ -342,17 +1176,17 @@ func (p *Parser) parseLiteral(exp []byte) {
 
 	// check if next rune is a separator
 	p.next(false)
-	if !isSeparator(p.ch) {
-		p.error(&SyntaxError{Char: p.ch, typ: endLit})
+	if !p.isSeparator(p.ch) {
+		p.syntaxError(endLit)
 		return
 	}
 
-	if isWhitespace(p.ch) {
-		p.next(true)
-	}
+	p.skipWhite()
 }
 
-func (p *Parser) parseEscape() bool {
+// parseEscape parses an escape sequence. It returns the code unit of a \u
+// escape, or -1 for other escape sequences.
+func (p *Parser) parseEscape() (rune, bool) {
 	p.store() // reverse solidus
 	p.next(false)
 
@@ -360,56 +1194,188 @@ func (p *Parser) parseEscape() bool {
 	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
 		p.store()
 
+	case '\'':
+		if !p.singleQuotes {
+			p.syntaxError(chrEsc)
+			return -1, false
+		}
+		// a single-quote needs no escape in the stored double-quoted string
+		p.unstore()
+		p.store()
+
 	case 'u':
 		p.store()
+		var r rune
 		for i := 0; i < 4; i++ {
 			p.next(false)
 			if !isHexadecimal(p.ch) {
-				p.error(&SyntaxError{Char: p.ch, typ: hexEsc})
-				return false
+				p.syntaxError(hexEsc)
+				return -1, false
 			}
 			p.store()
+			r = r<<4 | hexValue(p.ch)
 		}
+		return r, true
 
 	default:
-		p.error(&SyntaxError{Char: p.ch, typ: chrEsc})
-		return false
+		p.syntaxError(chrEsc)
+		return -1, false
 	}
 
+	return -1, true
+}
+
+// checkSurrogate checks the pairing of UTF-16 surrogates in \u escapes, given
+// the code unit r of the escape at offset off, or -1 for anything else in the
+// string literal. A pending high surrogate is stored in high and highOff.
+func (p *Parser) checkSurrogate(r rune, off int64, high *rune, highOff *int64) bool {
+	if *high != 0 {
+		if isLowSurrogate(r) {
+			*high = 0
+			return true
+		}
+		p.error(&SurrogatePairError{Rune: *high, Offset: *highOff})
+		return false
+	}
+	if isHighSurrogate(r) {
+		*high, *highOff = r, off
+	} else if isLowSurrogate(r) {
+		p.error(&SurrogatePairError{Rune: r, Offset: off})
+		return false
+	}
 	return true
 }
 
+// parseString parses a string literal delimited by the current rune, which
+// is a double-quote, or a single-quote if the parser allows them, in which
+// case the string is stored as a double-quoted string.
 func (p *Parser) parseString() {
-	p.store() // starting double-quote
+	quote := p.ch
+	if quote == '\'' {
+		p.warn("single-quoted string", p.off)
+	}
+	p.storeRune('"') // starting quote
+	closed := false
+	var high rune // pending high surrogate
+	var highOff int64
 
 loop:
 	for p.next(false) {
+		if high != 0 && p.ch != '\\' && !p.checkSurrogate(-1, p.off, &high, &highOff) {
+			return
+		}
+
 		switch p.ch {
+		case quote:
+			// unescaped quote, end of the string literal
+			p.storeRune('"')
+			if !p.checkStringLen() {
+				return
+			}
+			closed = true
+			break loop
+
 		case '"':
-			// unescaped double-quote, end of the string literal
+			// double-quote in a single-quoted string
+			p.storeRune('\\')
 			p.store()
-			break loop
 
 		case '\\':
 			// parse escape sequence
-			if !p.parseEscape() {
+			off := p.off
+			r, ok := p.parseEscape()
+			if !ok {
 				return
 			}
+			if p.strictSurrogates && !p.checkSurrogate(r, off, &high, &highOff) {
+				return
+			}
+			if p.ch == '\'' && quote == '"' {
+				p.warn("single-quote escape", off)
+			}
 
 		default:
 			// check if the rune is valid in a string literal
 			if isInvalidInString(p.ch) {
-				p.error(&SyntaxError{Char: p.ch, typ: strLit})
+				p.syntaxError(strLit)
 				return
 			}
 			p.store()
 		}
+
+		if !p.checkStringLen() {
+			return
+		}
+	}
+
+	if !closed {
+		// end of input in the string literal
+		p.error(io.ErrUnexpectedEOF)
+		return
 	}
 
 	// position the parser on the next rune
 	p.next(true)
 }
 
+// parseIdentifier parses an unquoted object key, which is stored as a
+// double-quoted string.
+func (p *Parser) parseIdentifier() {
+	p.warn("unquoted key", p.off)
+	p.storeRune('"')
+	p.store()
+	for p.next(false) && isIdentifierPart(p.ch) {
+		p.store()
+		if !p.checkStringLen() {
+			return
+		}
+	}
+	if p.err != nil && p.err != io.EOF {
+		return
+	}
+	p.storeRune('"')
+	p.skipWhite()
+}
+
+// isIdentifierStart returns true if the rune can start an unquoted object
+// key, as for JavaScript identifiers.
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// isIdentifierPart returns true if the rune can be part of an unquoted
+// object key.
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r)
+}
+
+// checkStringLen checks that the string literal being parsed does not exceed
+// the maximum length, setting the error otherwise.
+func (p *Parser) checkStringLen() bool {
+	if n := p.tokenLen(); p.maxStringLen > 0 && n > p.maxStringLen {
+		p.error(&StringLenError{Limit: p.maxStringLen, Len: n})
+		return false
+	}
+	return true
+}
+
+// checkNumberLen checks that the number being parsed does not exceed the
+// maximum length, setting the error otherwise.
+func (p *Parser) checkNumberLen() bool {
+	if n := p.tokenLen(); p.maxNumberLen > 0 && n > p.maxNumberLen {
+		p.error(&NumberLenError{Limit: p.maxNumberLen, Len: n})
+		return false
+	}
+	return true
+}
+
+// tokenLen returns the size in bytes of the current token up to and
+// including the current rune. Unlike the length of the buffer, it is also
+// available when the bytes are not stored.
+func (p *Parser) tokenLen() int {
+	return int(p.off + int64(p.width) - p.start)
+}
+
 func (p *Parser) parseMantissa() {
 	p.store() // the 'e' or 'E'
 	sign := false
@@ -420,7 +1386,7 @@ loop:
 		switch p.ch {
 		case '+', '-':
 			if sign {
-				p.error(&SyntaxError{Char: p.ch, typ: endLit})
+				p.syntaxError(endLit)
 				return
 			}
 			sign = true
@@ -430,88 +1396,172 @@ loop:
 			lastIsDigit = true
 
 		default:
-			if isSeparator(p.ch) {
+			if p.isSeparator(p.ch) {
 				break loop
 			}
-			p.error(&SyntaxError{Char: p.ch, typ: endLit})
+			p.syntaxError(endLit)
 			return
 		}
 		p.store()
+		if !p.checkNumberLen() {
+			return
+		}
 	}
 
 	if !lastIsDigit {
-		p.error(&SyntaxError{Char: p.ch, typ: endLit})
+		p.syntaxError(endLit)
 	}
 
-	if isWhitespace(p.ch) {
-		p.next(true)
-	}
+	p.skipWhite()
 }
 
 func (p *Parser) parseNumber() {
 	p.store() // starting char (negative sign or digit)
 	digit0 := p.ch
+	digits := 0 // number of digits in the integer part
+	if digit0 != '-' {
+		digits = 1
+	}
 	dot := false
 	lastIsDigit := digit0 != '-'
 
 loop:
 	for p.next(false) {
 		switch p.ch {
-		case '0':
-			switch digit0 {
-			case '-':
-				// this is the first digit
-				digit0 = p.ch
-			case '0':
-				// 00, invalid
-				p.error(&SyntaxError{Char: p.ch, typ: zroLit})
-				return
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if !dot {
+				if digits == 0 {
+					// this is the first digit
+					digit0 = p.ch
+				} else if digit0 == '0' {
+					// leading 0, invalid
+					p.syntaxError(zroLit)
+					return
+				}
+				digits++
 			}
 			p.store()
-			lastIsDigit = true
-
-		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			if digit0 == '0' && !dot {
-				p.error(&SyntaxError{Char: p.ch, typ: zroLit})
+			if !p.checkNumberLen() {
 				return
 			}
-			p.store()
 			lastIsDigit = true
 
 		case '.':
-			if dot {
-				p.error(&SyntaxError{Char: p.ch, typ: endLit})
+			if dot || !lastIsDigit {
+				p.syntaxError(endLit)
 				return
 			}
 			dot = true
 			p.store()
+			if !p.checkNumberLen() {
+				return
+			}
 			lastIsDigit = false
 
 		case 'e', 'E':
+			if !lastIsDigit {
+				p.syntaxError(endLit)
+				return
+			}
 			p.parseMantissa()
 			return
 
+		case 'I':
+			if !p.specialFloats || digits > 0 {
+				p.syntaxError(endLit)
+				return
+			}
+			p.parseSpecialFloat()
+			return
+
+		case 'x', 'X', 'o', 'O':
+			hex := p.ch == 'x' || p.ch == 'X'
+			if digits != 1 || digit0 != '0' || dot || hex && !p.hexNumbers || !hex && !p.octalNumbers {
+				p.syntaxError(endLit)
+				return
+			}
+			p.parseRadixInteger(hex)
+			return
+
 		default:
-			if isSeparator(p.ch) {
+			if p.isSeparator(p.ch) {
 				break loop
 			}
-			p.error(&SyntaxError{Char: p.ch, typ: endLit})
+			p.syntaxError(endLit)
 			return
 		}
 	}
 
 	if !lastIsDigit {
-		p.error(&SyntaxError{Char: p.ch, typ: endLit})
+		p.syntaxError(endLit)
 	}
 
-	if isWhitespace(p.ch) {
-		p.next(true)
+	p.skipWhite()
+}
+
+// parseRadixInteger parses the digits of a hexadecimal integer if hex is
+// true, of an octal integer otherwise, that follow the 0x or 0o prefix
+// starting at the current rune.
+func (p *Parser) parseRadixInteger(hex bool) {
+	if hex {
+		p.warn("hex number", p.start)
+	} else {
+		p.warn("octal number", p.start)
+	}
+	p.store() // the 'x' or 'o'
+	digits := 0
+
+loop:
+	for p.next(false) {
+		switch {
+		case p.ch >= '0' && p.ch <= '7',
+			p.ch >= '8' && p.ch <= '9' && hex,
+			(p.ch|0x20) >= 'a' && (p.ch|0x20) <= 'f' && hex:
+			digits++
+
+		default:
+			if p.isSeparator(p.ch) {
+				break loop
+			}
+			p.syntaxError(endLit)
+			return
+		}
+		p.store()
+		if !p.checkNumberLen() {
+			return
+		}
+	}
+
+	if digits == 0 {
+		p.syntaxError(endLit)
+	}
+
+	p.skipWhite()
+}
+
+// parseSpecialFloat parses the NaN or Infinity literal that starts at the
+// current rune.
+func (p *Parser) parseSpecialFloat() {
+	p.warn("special float", p.start)
+	if p.ch == 'N' {
+		p.parseLiteral(nanLiteral)
+		return
 	}
+	p.parseLiteral(infinityLiteral)
 }
 
-// store saves the current rune in the internal buffer.
+// store saves the current rune in the internal buffer, unless the parser
+// discards the bytes of the tokens.
 func (p *Parser) store() bool {
-	_, err := p.buf.WriteRune(p.ch)
+	return p.storeRune(p.ch)
+}
+
+// storeRune is like store, but it stores r in place of the current rune.
+func (p *Parser) storeRune(r rune) bool {
+	if p.discard {
+		return true
+	}
+	_, err := p.buf.WriteRune(r)
 	if err != nil {
 		p.error(err)
 		return false
@@ -519,6 +1569,83 @@ func (p *Parser) store() bool {
 	return true
 }
 
+// unstore removes the last stored byte, which must be a single-byte rune.
+func (p *Parser) unstore() {
+	if !p.discard {
+		p.buf.Truncate(p.buf.Len() - 1)
+	}
+}
+
+// syntaxError sets a SyntaxError of the specified type for the current rune.
+func (p *Parser) syntaxError(typ int) {
+	err := &SyntaxError{Char: p.ch, Line: p.line, Column: p.col, Offset: p.off, typ: typ}
+	err.Near = p.errorContext()
+	p.error(err)
+}
+
+// collectTrailing collects a syntax error for each whitespace-separated
+// run of bytes that follows the top-level value, up to the end of the input.
+func (p *Parser) collectTrailing() {
+	for p.err == nil {
+		err := &SyntaxError{Char: p.ch, Line: p.line, Column: p.col, Offset: p.off, typ: endLit}
+		err.Near = p.errorContext()
+		p.errs = append(p.errs, err)
+		if p.onError != nil {
+			p.onError(err)
+		}
+
+		for !isWhitespace(p.ch) && p.next(false) {
+		}
+		p.skipWhite()
+	}
+}
+
+// errorContext returns the bytes of input surrounding the current rune,
+// for an error on that rune. The preceding bytes come from the ring buffer
+// and the following bytes are read ahead, as the parser stops anyway. In
+// multi-value mode with invalid documents skipped, it does not read past
+// the end of the line, so that parsing can resume on the next one, and
+// when collecting errors, past the next whitespace.
+func (p *Parser) errorContext() []byte {
+	if p.near == 0 {
+		return nil
+	}
+
+	var cur int64
+	if p.ch >= 0 {
+		cur = int64(utf8.RuneLen(p.ch))
+	}
+	end := p.nring - cur
+	n := int64(p.near / 2)
+	if end < n {
+		n = end
+	}
+	near := make([]byte, 0, p.near)
+	for i := end - n; i < end; i++ {
+		near = append(near, p.ring[i%int64(len(p.ring))])
+	}
+	for len(near) > 0 && !utf8.RuneStart(near[0]) {
+		near = near[1:]
+	}
+
+	if p.ch >= 0 {
+		max := len(near) + p.near - p.near/2
+		err := p.err
+		for r := p.ch; len(near)+utf8.RuneLen(r) <= max; r = p.ch {
+			near = utf8.AppendRune(near, r)
+			if p.skipBad && p.nl || p.collect && isWhitespace(p.ch) || !p.next(false) {
+				break
+			}
+		}
+		p.err = err
+	}
+
+	if len(near) == 0 {
+		return nil
+	}
+	return near
+}
+
 // error sets the error on the parser, if it is the first error encountered.
 func (p *Parser) error(err error) {
 	if p.err == nil || (p.err == io.EOF && err != io.EOF) {
@@ -526,6 +1653,9 @@ func (p *Parser) error(err error) {
 		p.ch = -1
 		if err != io.EOF {
 			p.tok = Invalid
+			if p.onError != nil {
+				p.onError(err)
+			}
 		}
 	}
 }
@@ -540,14 +1670,34 @@ func (p *Parser) next(skipWhite bool) bool {
 	var err error
 
 	for {
-		r, _, err = p.r.ReadRune()
+		// move the position past the previous rune
+		if p.nl {
+			p.line++
+			p.col = 0
+		}
+		p.col++
+		p.off += int64(p.width)
+
+		if p.ctx != nil && !p.checkContext() {
+			return false
+		}
+		r, p.width, err = p.r.ReadRune()
+		p.nl = r == '\n'
+		if err == io.EOF && p.autoClose {
+			if cerr := p.closeReader(); cerr != nil {
+				err = cerr
+			}
+		}
 		if err != nil {
 			p.error(err)
 			return false
 		}
-		if r == unicode.ReplacementChar {
-			// invalid unicode code point
-			p.error(errors.New("jsonb: invalid unicde code point"))
+		if p.ring != nil {
+			p.record(r)
+		}
+		if r == utf8.RuneError && p.width == 1 {
+			// invalid UTF-8 encoding, a valid U+FFFD is 3 bytes long
+			p.error(errInvalidCodePoint)
 			return false
 		}
 
@@ -556,9 +1706,112 @@ func (p *Parser) next(skipWhite bool) bool {
 		}
 	}
 	p.ch = r
+	if skipWhite && r == '/' && p.comments {
+		return p.skipComment()
+	}
 	return true
 }
 
+// record writes the bytes of r to the ring buffer of the last bytes read.
+func (p *Parser) record(r rune) {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	for _, c := range b[:n] {
+		p.ring[p.nring%int64(len(p.ring))] = c
+		p.nring++
+	}
+}
+
+// checkContext checks the context of the parser once every interval runes,
+// setting the error of the context if it is done.
+func (p *Parser) checkContext() bool {
+	if p.unchecked++; p.unchecked < p.interval {
+		return true
+	}
+	p.unchecked = 0
+	select {
+	case <-p.ctx.Done():
+		p.error(p.ctx.Err())
+		return false
+	default:
+		return true
+	}
+}
+
+// skipWhite positions the parser on the next non-whitespace rune if the
+// current rune is a whitespace or the start of a comment.
+func (p *Parser) skipWhite() {
+	if isWhitespace(p.ch) {
+		p.next(true)
+	} else if p.ch == '/' && p.comments {
+		p.skipComment()
+	}
+}
+
+// skipComment skips the comment that starts at the current rune and
+// positions the parser on the next non-whitespace rune. Block comments
+// may be nested.
+func (p *Parser) skipComment() bool {
+	start := p.off
+	if !p.next(false) {
+		if p.err == io.EOF {
+			p.error(&InvalidCommentError{Offset: start, Unterminated: true})
+		}
+		return false
+	}
+
+	switch p.ch {
+	case '/':
+		p.warn("comment", start)
+		for p.next(false) {
+			if p.ch == '\n' {
+				return p.next(true)
+			}
+		}
+		// the end of input terminates a line comment
+		return false
+
+	case '*':
+		p.warn("comment", start)
+		depth := 1
+		prev := rune(-1)
+		for p.next(false) {
+			if prev == '*' && p.ch == '/' {
+				if depth--; depth == 0 {
+					return p.next(true)
+				}
+				prev = -1
+				continue
+			}
+			if prev == '/' && p.ch == '*' {
+				depth++
+				prev = -1
+				continue
+			}
+			prev = p.ch
+		}
+		if p.err == io.EOF {
+			p.error(&InvalidCommentError{Offset: start, Unterminated: true})
+		}
+		return false
+	}
+
+	p.error(&InvalidCommentError{Offset: start})
+	return false
+}
+
+// wantColon returns true if an object key has just been parsed, so that
+// a colon must follow.
+func (p *Parser) wantColon() bool {
+	return p.tok == ObjectKey
+}
+
+// wantKey returns true if an object key is expected at the current position.
+func (p *Parser) wantKey(wantColon bool) bool {
+	l := len(p.stack)
+	return l > 0 && p.stack[l-1] == stObjKey && !wantColon
+}
+
 func (p *Parser) wantComma() bool {
 	l := len(p.stack)
 	if l == 0 {
@@ -603,6 +1856,33 @@ func isHexadecimal(r rune) bool {
 		('A' <= r && r <= 'F')
 }
 
+// hexValue returns the value of the hexadecimal character r.
+func hexValue(r rune) rune {
+	switch {
+	case r <= '9':
+		return r - '0'
+	case r <= 'F':
+		return r - 'A' + 10
+	}
+	return r - 'a' + 10
+}
+
+// isHighSurrogate returns true if r is a UTF-16 high (leading) surrogate.
+func isHighSurrogate(r rune) bool {
+	return 0xD800 <= r && r <= 0xDBFF
+}
+
+// isLowSurrogate returns true if r is a UTF-16 low (trailing) surrogate.
+func isLowSurrogate(r rune) bool {
+	return 0xDC00 <= r && r <= 0xDFFF
+}
+
+// isSeparator returns true if the rune is a valid value separator, including
+// the start of a comment if comments are allowed.
+func (p *Parser) isSeparator(r rune) bool {
+	return isSeparator(r) || r == '/' && p.comments
+}
+
 // isSeparator returns true if the rune is a valid value separator.
 func isSeparator(r rune) bool {
 	return isWhitespace(r) ||
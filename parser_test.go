@@ -2,6 +2,8 @@ package jsonb
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -17,20 +19,20 @@ func TestParser(t *testing.T) {
 		{in: ""},
 
 		// true, false and null literal names
-		{in: "z", toks: []Token{Invalid}, bytes: []string{""}, err: &SyntaxError{Char: 'z', typ: begVal}},
+		{in: "z", toks: []Token{Invalid}, bytes: []string{""}, err: &SyntaxError{Char: 'z', Line: 1, Column: 1, Offset: 0, typ: begVal}},
 		{in: "null", toks: []Token{Null}, bytes: []string{"null"}},
-		{in: "nall", toks: []Token{Invalid}, bytes: []string{"n"}, err: &LiteralError{want: 'u', got: 'a', tok: Null}},
-		{in: "t", toks: []Token{Invalid}, bytes: []string{"t"}, err: &LiteralError{want: 'r', got: -1, tok: True}},
-		{in: "tue", toks: []Token{Invalid}, bytes: []string{"t"}, err: &LiteralError{want: 'r', got: 'u', tok: True}},
+		{in: "nall", toks: []Token{Invalid}, bytes: []string{"n"}, err: &LiteralError{Line: 1, Column: 2, Offset: 1, want: 'u', got: 'a', tok: Null}},
+		{in: "t", toks: []Token{Invalid}, bytes: []string{"t"}, err: &LiteralError{Line: 1, Column: 2, Offset: 1, want: 'r', got: -1, tok: True}},
+		{in: "tue", toks: []Token{Invalid}, bytes: []string{"t"}, err: &LiteralError{Line: 1, Column: 2, Offset: 1, want: 'r', got: 'u', tok: True}},
 		{in: "true", toks: []Token{True}, bytes: []string{"true"}},
-		{in: "fa", toks: []Token{Invalid}, bytes: []string{"fa"}, err: &LiteralError{want: 'l', got: -1, tok: False}},
-		{in: "fz", toks: []Token{Invalid}, bytes: []string{"f"}, err: &LiteralError{want: 'a', got: 'z', tok: False}},
-		{in: "fals", toks: []Token{Invalid}, bytes: []string{"fals"}, err: &LiteralError{want: 'e', got: -1, tok: False}},
+		{in: "fa", toks: []Token{Invalid}, bytes: []string{"fa"}, err: &LiteralError{Line: 1, Column: 3, Offset: 2, want: 'l', got: -1, tok: False}},
+		{in: "fz", toks: []Token{Invalid}, bytes: []string{"f"}, err: &LiteralError{Line: 1, Column: 2, Offset: 1, want: 'a', got: 'z', tok: False}},
+		{in: "fals", toks: []Token{Invalid}, bytes: []string{"fals"}, err: &LiteralError{Line: 1, Column: 5, Offset: 4, want: 'e', got: -1, tok: False}},
 		{in: "false", toks: []Token{False}, bytes: []string{"false"}},
-		{in: "falsez", toks: []Token{Invalid}, bytes: []string{"false"}, err: &SyntaxError{Char: 'z', typ: endLit}},
-		{in: "truez", toks: []Token{Invalid}, bytes: []string{"true"}, err: &SyntaxError{Char: 'z', typ: endLit}},
-		{in: "nullz", toks: []Token{Invalid}, bytes: []string{"null"}, err: &SyntaxError{Char: 'z', typ: endLit}},
-		{in: "null,", toks: []Token{Null, Invalid}, bytes: []string{"null", ""}, err: &SyntaxError{Char: ',', typ: begVal}},
+		{in: "falsez", toks: []Token{Invalid}, bytes: []string{"false"}, err: &SyntaxError{Char: 'z', Line: 1, Column: 6, Offset: 5, typ: endLit}},
+		{in: "truez", toks: []Token{Invalid}, bytes: []string{"true"}, err: &SyntaxError{Char: 'z', Line: 1, Column: 5, Offset: 4, typ: endLit}},
+		{in: "nullz", toks: []Token{Invalid}, bytes: []string{"null"}, err: &SyntaxError{Char: 'z', Line: 1, Column: 5, Offset: 4, typ: endLit}},
+		{in: "null,", toks: []Token{Null, Invalid}, bytes: []string{"null", ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 5, Offset: 4, typ: begVal}},
 
 		// string literals
 		{in: `""`, toks: []Token{String}, bytes: []string{`""`}},
@@ -41,50 +43,84 @@ func TestParser(t *testing.T) {
 		{in: `"\u001b"`, toks: []Token{String}, bytes: []string{`"\u001b"`}},
 		{in: `"\uAbC9"`, toks: []Token{String}, bytes: []string{`"\uAbC9"`}},
 		{in: `"\udEfF"`, toks: []Token{String}, bytes: []string{`"\udEfF"`}},
-		{in: `"\z"`, toks: []Token{Invalid}, bytes: []string{`"\`}, err: &SyntaxError{Char: 'z', typ: chrEsc}},
-		{in: `"\uab_e"`, toks: []Token{Invalid}, bytes: []string{`"\uab`}, err: &SyntaxError{Char: '_', typ: hexEsc}},
-		{in: `,"a"`, toks: []Token{Invalid}, bytes: []string{``}, err: &SyntaxError{Char: ',', typ: begVal}},
-		{in: `"a",`, toks: []Token{String, Invalid}, bytes: []string{`"a"`, ""}, err: &SyntaxError{Char: ',', typ: begVal}},
+		{in: `"\z"`, toks: []Token{Invalid}, bytes: []string{`"\`}, err: &SyntaxError{Char: 'z', Line: 1, Column: 3, Offset: 2, typ: chrEsc}},
+		{in: `"\uab_e"`, toks: []Token{Invalid}, bytes: []string{`"\uab`}, err: &SyntaxError{Char: '_', Line: 1, Column: 6, Offset: 5, typ: hexEsc}},
+		{in: `,"a"`, toks: []Token{Invalid}, bytes: []string{``}, err: &SyntaxError{Char: ',', Line: 1, Column: 1, Offset: 0, typ: begVal}},
+		{in: `"ab`, toks: []Token{Invalid}, bytes: []string{`"ab`}, err: io.ErrUnexpectedEOF},
+		{in: "\"a\ufffdb\"", toks: []Token{String}, bytes: []string{"\"a\ufffdb\""}},
+		{in: "\"a\xffb\"", toks: []Token{Invalid}, bytes: []string{`"a`}, err: errInvalidCodePoint},
+		{in: `"a",`, toks: []Token{String, Invalid}, bytes: []string{`"a"`, ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 4, Offset: 3, typ: begVal}},
 
 		// number literals
 		{in: `0`, toks: []Token{Number}, bytes: []string{`0`}},
 		{in: `1234567890`, toks: []Token{Number}, bytes: []string{`1234567890`}},
 		{in: `-1234567890`, toks: []Token{Number}, bytes: []string{`-1234567890`}},
-		{in: `-01`, toks: []Token{Invalid}, bytes: []string{`-0`}, err: &SyntaxError{Char: '1', typ: zroLit}},
-		{in: `01`, toks: []Token{Invalid}, bytes: []string{`0`}, err: &SyntaxError{Char: '1', typ: zroLit}},
-		{in: `0a`, toks: []Token{Invalid}, bytes: []string{`0`}, err: &SyntaxError{Char: 'a', typ: endLit}},
-		{in: `1a`, toks: []Token{Invalid}, bytes: []string{`1`}, err: &SyntaxError{Char: 'a', typ: endLit}},
+		{in: `-01`, toks: []Token{Invalid}, bytes: []string{`-0`}, err: &SyntaxError{Char: '1', Line: 1, Column: 3, Offset: 2, typ: zroLit}},
+		{in: `01`, toks: []Token{Invalid}, bytes: []string{`0`}, err: &SyntaxError{Char: '1', Line: 1, Column: 2, Offset: 1, typ: zroLit}},
+		{in: `0a`, toks: []Token{Invalid}, bytes: []string{`0`}, err: &SyntaxError{Char: 'a', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `1a`, toks: []Token{Invalid}, bytes: []string{`1`}, err: &SyntaxError{Char: 'a', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `-10`, toks: []Token{Number}, bytes: []string{`-10`}},
+		{in: `0.0`, toks: []Token{Number}, bytes: []string{`0.0`}},
+		{in: `-0.005`, toks: []Token{Number}, bytes: []string{`-0.005`}},
+		{in: `-.5`, toks: []Token{Invalid}, bytes: []string{`-`}, err: &SyntaxError{Char: '.', Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `1.e5`, toks: []Token{Invalid}, bytes: []string{`1.`}, err: &SyntaxError{Char: 'e', Line: 1, Column: 3, Offset: 2, typ: endLit}},
+		{in: `-e5`, toks: []Token{Invalid}, bytes: []string{`-`}, err: &SyntaxError{Char: 'e', Line: 1, Column: 2, Offset: 1, typ: endLit}},
 		{in: `1.2`, toks: []Token{Number}, bytes: []string{`1.2`}},
 		{in: `0.2`, toks: []Token{Number}, bytes: []string{`0.2`}},
 		{in: `-0.123`, toks: []Token{Number}, bytes: []string{`-0.123`}},
 		{in: `-4567890.123`, toks: []Token{Number}, bytes: []string{`-4567890.123`}},
-		{in: `1.2.3`, toks: []Token{Invalid}, bytes: []string{`1.2`}, err: &SyntaxError{Char: '.', typ: endLit}},
+		{in: `1.2.3`, toks: []Token{Invalid}, bytes: []string{`1.2`}, err: &SyntaxError{Char: '.', Line: 1, Column: 4, Offset: 3, typ: endLit}},
 		{in: `-0.123e+124`, toks: []Token{Number}, bytes: []string{`-0.123e+124`}},
 		{in: `-0.123E-001`, toks: []Token{Number}, bytes: []string{`-0.123E-001`}},
 		{in: `123E+2`, toks: []Token{Number}, bytes: []string{`123E+2`}},
-		{in: `123E+2e`, toks: []Token{Invalid}, bytes: []string{`123E+2`}, err: &SyntaxError{Char: 'e', typ: endLit}},
-		{in: `123E+-1`, toks: []Token{Invalid}, bytes: []string{`123E+`}, err: &SyntaxError{Char: '-', typ: endLit}},
-		{in: `-`, toks: []Token{Invalid}, bytes: []string{`-`}, err: &SyntaxError{Char: -1, typ: endLit}},
-		{in: `123.`, toks: []Token{Invalid}, bytes: []string{`123.`}, err: &SyntaxError{Char: -1, typ: endLit}},
-		{in: `123.4e`, toks: []Token{Invalid}, bytes: []string{`123.4e`}, err: &SyntaxError{Char: -1, typ: endLit}},
-		{in: `123.4e-`, toks: []Token{Invalid}, bytes: []string{`123.4e-`}, err: &SyntaxError{Char: -1, typ: endLit}},
-		{in: `,0`, toks: []Token{Invalid}, bytes: []string{``}, err: &SyntaxError{Char: ',', typ: begVal}},
-		{in: `0 , `, toks: []Token{Number, Invalid}, bytes: []string{`0`, ""}, err: &SyntaxError{Char: ',', typ: begVal}},
+		{in: `123E+2e`, toks: []Token{Invalid}, bytes: []string{`123E+2`}, err: &SyntaxError{Char: 'e', Line: 1, Column: 7, Offset: 6, typ: endLit}},
+		{in: `123E+-1`, toks: []Token{Invalid}, bytes: []string{`123E+`}, err: &SyntaxError{Char: '-', Line: 1, Column: 6, Offset: 5, typ: endLit}},
+		{in: `-`, toks: []Token{Invalid}, bytes: []string{`-`}, err: &SyntaxError{Char: -1, Line: 1, Column: 2, Offset: 1, typ: endLit}},
+		{in: `123.`, toks: []Token{Invalid}, bytes: []string{`123.`}, err: &SyntaxError{Char: -1, Line: 1, Column: 5, Offset: 4, typ: endLit}},
+		{in: `123.4e`, toks: []Token{Invalid}, bytes: []string{`123.4e`}, err: &SyntaxError{Char: -1, Line: 1, Column: 7, Offset: 6, typ: endLit}},
+		{in: `123.4e-`, toks: []Token{Invalid}, bytes: []string{`123.4e-`}, err: &SyntaxError{Char: -1, Line: 1, Column: 8, Offset: 7, typ: endLit}},
+		{in: `,0`, toks: []Token{Invalid}, bytes: []string{``}, err: &SyntaxError{Char: ',', Line: 1, Column: 1, Offset: 0, typ: begVal}},
+		{in: `0 , `, toks: []Token{Number, Invalid}, bytes: []string{`0`, ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 3, Offset: 2, typ: begVal}},
 
 		// array
 		{in: `[]`, toks: []Token{ArrayStart, ArrayEnd}, bytes: []string{"[", "]"}},
 		{in: `[true]`, toks: []Token{ArrayStart, True, ArrayEnd}, bytes: []string{"[", "true", "]"}},
 		{in: `[true, 1, "a"]`, toks: []Token{ArrayStart, True, Number, String, ArrayEnd}, bytes: []string{"[", "true", "1", `"a"`, "]"}},
-		{in: `[true, , 1]`, toks: []Token{ArrayStart, True, Invalid}, bytes: []string{"[", "true", ""}, err: &SyntaxError{Char: ',', typ: begVal}},
-		{in: `[,1]`, toks: []Token{ArrayStart, Invalid}, bytes: []string{"[", ""}, err: &SyntaxError{Char: ',', typ: begVal}},
-		{in: `true, , 1]`, toks: []Token{True, Invalid}, bytes: []string{"true", ""}, err: &SyntaxError{Char: ',', typ: begVal}},
+		{in: `[true, , 1]`, toks: []Token{ArrayStart, True, Invalid}, bytes: []string{"[", "true", ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 8, Offset: 7, typ: begVal}},
+		{in: `[,1]`, toks: []Token{ArrayStart, Invalid}, bytes: []string{"[", ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `true, , 1]`, toks: []Token{True, Invalid}, bytes: []string{"true", ""}, err: &SyntaxError{Char: ',', Line: 1, Column: 5, Offset: 4, typ: begVal}},
 		{in: `[true, 1, "a",  [  false, 2, "b" ],   null]`, toks: []Token{ArrayStart, True, Number, String,
 			ArrayStart, False, Number, String, ArrayEnd, Null, ArrayEnd}, bytes: []string{"[", "true", "1", `"a"`,
 			"[", "false", "2", `"b"`, "]", "null", "]"}},
-		{in: `[1   , ]`, toks: []Token{ArrayStart, Number, Invalid}, bytes: []string{"[", "1", ""}, err: &SyntaxError{Char: ']', typ: begVal}},
+		{in: `[`, toks: []Token{ArrayStart}, bytes: []string{"["}, err: io.ErrUnexpectedEOF},
+		{in: `[1, [2`, toks: []Token{ArrayStart, Number, ArrayStart, Number}, bytes: []string{"[", "1", "[", "2"}, err: io.ErrUnexpectedEOF},
+		{in: `[1   , ]`, toks: []Token{ArrayStart, Number, Invalid}, bytes: []string{"[", "1", ""}, err: &SyntaxError{Char: ']', Line: 1, Column: 8, Offset: 7, typ: begVal}},
+		{in: `[1] [2]`, toks: []Token{ArrayStart, Number, ArrayEnd}, bytes: []string{"[", "1", "]"}, err: &SyntaxError{Char: '[', Line: 1, Column: 5, Offset: 4, typ: endLit}},
+		{in: `[1}`, toks: []Token{ArrayStart, Number}, bytes: []string{"[", "1"}, err: &SyntaxError{Char: '}', Line: 1, Column: 3, Offset: 2, typ: begVal}},
+		{in: "[1,\n  2,\n  z]", toks: []Token{ArrayStart, Number, Number, Invalid}, bytes: []string{"[", "1", "2", ""}, err: &SyntaxError{Char: 'z', Line: 3, Column: 3, Offset: 11, typ: begVal}},
+		{in: "[\r\n\ttrux]", toks: []Token{ArrayStart, Invalid}, bytes: []string{"[", "tru"}, err: &LiteralError{Line: 2, Column: 5, Offset: 7, want: 'e', got: 'x', tok: True}},
+
+		// object
+		{in: `{}`, toks: []Token{ObjectStart, ObjectEnd}, bytes: []string{"{", "}"}},
+		{in: `{"a":1}`, toks: []Token{ObjectStart, ObjectKey, Number, ObjectEnd}, bytes: []string{"{", `"a"`, "1", "}"}},
+		{in: ` { "a" : true , "b":[null, {}], "c" : {"d":"e"} } `, toks: []Token{ObjectStart, ObjectKey, True, ObjectKey,
+			ArrayStart, Null, ObjectStart, ObjectEnd, ArrayEnd, ObjectKey, ObjectStart, ObjectKey, String, ObjectEnd, ObjectEnd},
+			bytes: []string{"{", `"a"`, "true", `"b"`, "[", "null", "{", "}", "]", `"c"`, "{", `"d"`, `"e"`, "}", "}"}},
+		{in: `{1:2}`, toks: []Token{ObjectStart}, bytes: []string{"{"}, err: &SyntaxError{Char: '1', Line: 1, Column: 2, Offset: 1, typ: begKey}},
+		{in: `{"a" 1}`, toks: []Token{ObjectStart, ObjectKey}, bytes: []string{"{", `"a"`}, err: &SyntaxError{Char: '1', Line: 1, Column: 6, Offset: 5, typ: colExp}},
+		{in: `{"a"}`, toks: []Token{ObjectStart, ObjectKey}, bytes: []string{"{", `"a"`}, err: &SyntaxError{Char: '}', Line: 1, Column: 5, Offset: 4, typ: colExp}},
+		{in: `{"a":}`, toks: []Token{ObjectStart, ObjectKey}, bytes: []string{"{", `"a"`}, err: &SyntaxError{Char: '}', Line: 1, Column: 6, Offset: 5, typ: begVal}},
+		{in: `{"a":1,}`, toks: []Token{ObjectStart, ObjectKey, Number}, bytes: []string{"{", `"a"`, "1"}, err: &SyntaxError{Char: '}', Line: 1, Column: 8, Offset: 7, typ: begKey}},
+		{in: `{"a":1 "b":2}`, toks: []Token{ObjectStart, ObjectKey, Number}, bytes: []string{"{", `"a"`, "1"}, err: &SyntaxError{Char: '"', Line: 1, Column: 8, Offset: 7, typ: comExp}},
+		{in: `{,}`, toks: []Token{ObjectStart}, bytes: []string{"{"}, err: &SyntaxError{Char: ',', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `{:1}`, toks: []Token{ObjectStart}, bytes: []string{"{"}, err: &SyntaxError{Char: ':', Line: 1, Column: 2, Offset: 1, typ: begVal}},
+		{in: `{"a":1:2}`, toks: []Token{ObjectStart, ObjectKey, Number}, bytes: []string{"{", `"a"`, "1"}, err: &SyntaxError{Char: ':', Line: 1, Column: 7, Offset: 6, typ: comExp}},
+		{in: `{"a":1]`, toks: []Token{ObjectStart, ObjectKey, Number}, bytes: []string{"{", `"a"`, "1"}, err: &SyntaxError{Char: ']', Line: 1, Column: 7, Offset: 6, typ: begVal}},
+		{in: `{"a":1`, toks: []Token{ObjectStart, ObjectKey, Number}, bytes: []string{"{", `"a"`, "1"}, err: io.ErrUnexpectedEOF},
+		{in: `[1:2]`, toks: []Token{ArrayStart, Number}, bytes: []string{"[", "1"}, err: &SyntaxError{Char: ':', Line: 1, Column: 3, Offset: 2, typ: comExp}},
 	}
 
-	p := NewParser(nil)
+	p := NewParserOptions(nil, WithErrorContext(0))
 	for i, c := range cases {
 		p.Reset(strings.NewReader(c.in))
 
@@ -110,3 +146,588 @@ func TestParser(t *testing.T) {
 		}
 	}
 }
+
+func TestDepth(t *testing.T) {
+	cases := []struct {
+		in     string
+		depths []int
+	}{
+		{in: ""},
+		{in: `1`, depths: []int{0}},
+		{in: `[]`, depths: []int{1, 0}},
+		{in: `[1, 2]`, depths: []int{1, 1, 1, 0}},
+		{in: `[1, [2, [3]], 4]`, depths: []int{1, 1, 2, 2, 3, 3, 2, 1, 1, 0}},
+		{in: `[[1, ]`, depths: []int{1, 2, 2}},
+		{in: `{"a": {"b": [1]}, "c": 2}`, depths: []int{1, 1, 2, 2, 3, 3, 2, 1, 1, 1, 0}},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		var got []int
+		for p.Next() {
+			got = append(got, p.Depth())
+		}
+		if !reflect.DeepEqual(c.depths, got) {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.depths, got)
+		}
+	}
+}
+
+func TestContainer(t *testing.T) {
+	const (
+		N = NoContainer
+		A = ArrayContainer
+		O = ObjectContainer
+	)
+	cases := []struct {
+		in    string
+		kinds []ContainerKind
+	}{
+		{in: ""},
+		{in: `1`, kinds: []ContainerKind{N}},
+		{in: `[]`, kinds: []ContainerKind{A, N}},
+		{in: `[1, {"a": [2]}]`, kinds: []ContainerKind{A, A, O, O, A, A, O, A, N}},
+		{in: `{"a": 1}`, kinds: []ContainerKind{O, O, O, N}},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		var got []ContainerKind
+		for p.Next() {
+			k := p.Container()
+			if p.InArray() != (k == A) || p.InObject() != (k == O) || p.IsAtRoot() != (k == N) {
+				t.Errorf("%d (%s): inconsistent predicates for %v", i, c.in, k)
+			}
+			got = append(got, k)
+		}
+		if !reflect.DeepEqual(c.kinds, got) {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.kinds, got)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	cases := []struct {
+		in    string
+		paths []string // joined by "/"
+	}{
+		{in: ""},
+		{in: `1`, paths: []string{""}},
+		{in: `[1, 2]`, paths: []string{"", "0", "1", ""}},
+		{in: `{"a": 1, "b": [true, {"c": null}]}`, paths: []string{"", "a", "a", "b", "b", "b/0", "b/1", "b/1/c", "b/1/c", "b/1", "b", ""}},
+		{in: `{"users": [{"name": "x"}, {"name": "y"}]}`, paths: []string{"", "users", "users", "users/0", "users/0/name",
+			"users/0/name", "users/0", "users/1", "users/1/name", "users/1/name", "users/1", "users", ""}},
+		{in: `[[], {}, 3]`, paths: []string{"", "0", "0", "1", "1", "2", ""}},
+		{in: `[1, [2, z]`, paths: []string{"", "0", "1", "1/0", "1/1"}},
+		{in: `{"a\u00e9\/": 1}`, paths: []string{"", "aé/", "aé/", ""}},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		var got []string
+		for p.Next() {
+			got = append(got, strings.Join(p.Path(), "/"))
+		}
+		if !reflect.DeepEqual(c.paths, got) {
+			t.Errorf("%d (%s): want %q, got %q", i, c.in, c.paths, got)
+		}
+	}
+}
+
+func TestPathAfterError(t *testing.T) {
+	p := NewParser(strings.NewReader(`{"a": [1, [2, 3, }`))
+	for p.Next() {
+	}
+	if p.Err() == nil {
+		t.Fatal("want error, got nil")
+	}
+	want := []string{"a", "1", "2"}
+	if got := p.Path(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	cases := []struct {
+		in   string
+		keys []string
+	}{
+		{in: `1`, keys: []string{""}},
+		{in: `[1, "a"]`, keys: []string{"", "", "", ""}},
+		{in: `{"a": 1, "b": "c"}`, keys: []string{"", `"a"`, `"a"`, `"b"`, `"b"`, ""}},
+		{in: `{"a": [1], "b\n": {"c": null}}`, keys: []string{"", `"a"`, `"a"`, "", "", `"b\n"`, `"b\n"`, `"c"`, `"c"`, "", ""}},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		var got []string
+		for p.Next() {
+			got = append(got, string(p.Key()))
+		}
+		if !reflect.DeepEqual(c.keys, got) {
+			t.Errorf("%d (%s): want %q, got %q", i, c.in, c.keys, got)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	cases := []struct {
+		in   string
+		skip int // number of calls to Next before Skip
+		toks []Token
+		ok   bool
+		err  error
+	}{
+		{in: `1`, skip: 1, ok: true},
+		{in: `1`, skip: 2},
+		{in: `[1] `, skip: 4},
+		{in: `[1, 2]`, skip: 1, ok: true},
+		{in: `[1, [2, [3]], 4]`, skip: 1, ok: true},
+		{in: `[1, [2, [3]], 4]`, skip: 3, toks: []Token{Number, ArrayEnd}, ok: true},
+		{in: `[1, [2, [3]], 4]`, skip: 2, toks: []Token{ArrayStart, Number, ArrayStart, Number, ArrayEnd, ArrayEnd, Number, ArrayEnd}, ok: true},
+		{in: `[1, [2, [3]`, skip: 3, err: io.ErrUnexpectedEOF},
+		{in: `[1, [2, , 3], 4]`, skip: 3, err: &SyntaxError{Char: ',', Line: 1, Column: 9, Offset: 8, typ: begVal}},
+		{in: `[1]`, skip: 0, toks: []Token{ArrayStart, Number, ArrayEnd}},
+		{in: `z`, skip: 1, err: &SyntaxError{Char: 'z', Line: 1, Column: 1, Offset: 0, typ: begVal}},
+	}
+
+	p := NewParserOptions(nil, WithErrorContext(0))
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		for j := 0; j < c.skip; j++ {
+			p.Next()
+		}
+		if ok := p.Skip(); ok != c.ok {
+			t.Errorf("%d (%s): want %t, got %t", i, c.in, c.ok, ok)
+		}
+
+		var toks []Token
+		for p.Next() {
+			toks = append(toks, p.Token())
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d (%s): want tokens %v, got %v", i, c.in, c.toks, toks)
+		}
+		if err := p.Err(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want %v, got error %v", i, c.in, c.err, err)
+		}
+	}
+
+	// the last value can be skipped after peeking at the end of the input
+	p.Reset(strings.NewReader(`1`))
+	p.Next()
+	if tok := p.Peek(); tok != Invalid {
+		t.Errorf("want no token after the value, got %v", tok)
+	}
+	if !p.Skip() {
+		t.Errorf("want Skip to skip the last value")
+	}
+	if p.Next() || p.Skip() {
+		t.Errorf("want no value to skip at the end of the input")
+	}
+}
+
+func TestParserBytes(t *testing.T) {
+	in := []byte(`{"a": [1, "b", null]}`)
+	want := []Token{ObjectStart, ObjectKey, ArrayStart, Number, String, Null, ArrayEnd, ObjectEnd}
+
+	p := NewParserBytes(in)
+	for round := 0; round < 2; round++ {
+		var got []Token
+		for p.Next() {
+			got = append(got, p.Token())
+		}
+		if err := p.Err(); err != nil {
+			t.Fatalf("%d: unexpected error %v", round, err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%d: want %v, got %v", round, want, got)
+		}
+		p.ResetBytes(in)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.ResetBytes(in)
+		for p.Next() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("want no allocation, got %v", allocs)
+	}
+}
+
+func TestParserString(t *testing.T) {
+	in := `[true, {"a": -1.5}]`
+	want := []Token{ArrayStart, True, ObjectStart, ObjectKey, Number, ObjectEnd, ArrayEnd}
+
+	p := NewParserString(in)
+	var got []Token
+	for p.Next() {
+		got = append(got, p.Token())
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.ResetString(in)
+		for p.Next() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("want no allocation, got %v", allocs)
+	}
+}
+
+func TestAppendBytes(t *testing.T) {
+	p := NewParserString(`[1, "a", {"b": null}]`)
+	dst := make([]byte, 0, 64)
+	for p.Next() {
+		dst = p.AppendBytes(dst)
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := `[1"a"{"b"null}]`; string(dst) != want {
+		t.Errorf("want %s, got %s", want, dst)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	cases := []struct {
+		in      string
+		offsets []int64
+	}{
+		{in: `1`, offsets: []int64{0}},
+		{in: "  [1,  \"é\" , {\"k\":null}]", offsets: []int64{2, 3, 7, 14, 15, 19, 23, 24}},
+		{in: "[\"中文\", true]", offsets: []int64{0, 1, 11, 15}},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+
+		var got []int64
+		for p.Next() {
+			got = append(got, p.Offset())
+		}
+		if !reflect.DeepEqual(c.offsets, got) {
+			t.Errorf("%d (%s): want %v, got %v", i, c.in, c.offsets, got)
+		}
+	}
+}
+
+func TestTokenPredicates(t *testing.T) {
+	cases := []struct {
+		tok                          Token
+		value, container, start, end bool
+		opposite                     Token
+	}{
+		{tok: Invalid, opposite: Invalid},
+		{tok: Null, value: true, opposite: Invalid},
+		{tok: False, value: true, opposite: Invalid},
+		{tok: True, value: true, opposite: Invalid},
+		{tok: String, value: true, opposite: Invalid},
+		{tok: Number, value: true, opposite: Invalid},
+		{tok: ObjectEnd, container: true, end: true, opposite: ObjectStart},
+		{tok: ArrayEnd, container: true, end: true, opposite: ArrayStart},
+		{tok: ArrayStart, container: true, start: true, opposite: ArrayEnd},
+		{tok: ObjectStart, container: true, start: true, opposite: ObjectEnd},
+		{tok: ObjectKey, opposite: Invalid},
+	}
+
+	for _, c := range cases {
+		got := []interface{}{c.tok.IsValue(), c.tok.IsContainer(), c.tok.IsStart(), c.tok.IsEnd(), c.tok.Opposite()}
+		want := []interface{}{c.value, c.container, c.start, c.end, c.opposite}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%v: want %v, got %v", c.tok, want, got)
+		}
+	}
+}
+
+func TestErrorsIs(t *testing.T) {
+	cases := []struct {
+		in     string
+		target error
+		want   bool
+	}{
+		{in: `z`, target: ErrSyntax, want: true},
+		{in: `z`, target: ErrLiteral},
+		{in: `z`, target: &SyntaxError{typ: begVal}, want: true},
+		{in: `z`, target: &SyntaxError{Char: 'z', typ: begVal}, want: true},
+		{in: `z`, target: &SyntaxError{Char: 'y', typ: begVal}},
+		{in: `z`, target: &SyntaxError{typ: begKey}},
+		{in: `{z`, target: &SyntaxError{Char: 'z', typ: begKey}, want: true},
+		{in: `nul1`, target: ErrLiteral, want: true},
+		{in: `nul1`, target: ErrSyntax},
+		{in: `nul1`, target: &LiteralError{tok: Null}, want: true},
+		{in: `nul1`, target: &LiteralError{tok: True}},
+		{in: `nul1`, target: &LiteralError{want: 'l', got: '1', tok: Null}, want: true},
+		{in: `nul1`, target: &LiteralError{want: 'l', got: '2', tok: Null}},
+	}
+
+	for i, c := range cases {
+		p := NewParserString(c.in)
+		for p.Next() {
+		}
+		err := p.Err()
+		if got := errors.Is(err, c.target); got != c.want {
+			t.Errorf("%d (%s): want errors.Is(%v, %v) = %t, got %t", i, c.in, err, c.target, c.want, got)
+		}
+	}
+
+	p := NewParserString(`[1 2]`)
+	for p.Next() {
+	}
+	var se *SyntaxError
+	if !errors.As(p.Err(), &se) || se.Char != '2' {
+		t.Errorf("want errors.As to return the *SyntaxError, got %v", se)
+	}
+	var le *LiteralError
+	if errors.As(p.Err(), &le) {
+		t.Errorf("want errors.As to fail for *LiteralError, got %v", le)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{in: `z`, out: `1:1: invalid character 'z' at offset 0 looking for beginning of value`},
+		{in: "[1,\n  \"\u00e9\", z]", out: `2:8: invalid character 'z' at offset 12 looking for beginning of value`},
+		{in: `{"a": nul1}`, out: `1:10: invalid character '1' at offset 9 in literal null (expecting 'l')`},
+	}
+
+	for i, c := range cases {
+		p := NewParserString(c.in)
+		for p.Next() {
+		}
+		if err := p.Err(); err == nil || err.Error() != c.out {
+			t.Errorf("%d: want %s, got %v", i, c.out, err)
+		}
+	}
+}
+
+func TestCounters(t *testing.T) {
+	const in = `{"a": [1, "é", null]}  `
+
+	p := NewParserString(in)
+	var n int64
+	for p.Next() {
+		n++
+		if p.TokenCount() != n {
+			t.Errorf("want token count %d, got %d", n, p.TokenCount())
+		}
+		if p.BytesProcessed() < p.Offset()+int64(len(p.Bytes())) {
+			t.Errorf("want at least %d bytes processed, got %d", p.Offset()+int64(len(p.Bytes())), p.BytesProcessed())
+		}
+	}
+	if p.Err() != nil {
+		t.Fatal(p.Err())
+	}
+	if n != 8 || p.TokenCount() != n {
+		t.Errorf("want 8 tokens, got %d", p.TokenCount())
+	}
+	if p.BytesProcessed() != int64(len(in)) {
+		t.Errorf("want %d bytes processed, got %d", len(in), p.BytesProcessed())
+	}
+
+	p.ResetString(`[1]`)
+	if p.TokenCount() != 0 || p.BytesProcessed() != 0 {
+		t.Errorf("want counters reset, got %d and %d", p.TokenCount(), p.BytesProcessed())
+	}
+}
+
+func FuzzParser(f *testing.F) {
+	seeds := []string{
+		``, ` `, `[`, `]`, `{`, `}`, `[[]`, `[]]`, `{"a":`, `{"a" 1}`, `[1,]`, `[1 2]`,
+		`null`, `true`, `false`, `nall`, `truez`, `0`, `-0.5e+10`, `01`, `1.`, `-`,
+		`""`, `"aé\n"`, `"😀"`, `"\ud83d"`, `"\x"`, "\"\x01\"", "\"\xff\"",
+		"\"�\"", "\xef\xbf\xbd", "\xef\xbb\xbf{}",
+		`{"a": [1, 2, {"b": null}], "c": "d"}`, `[[[[[[[[[[]]]]]]]]]]`, `{"a": 1} 2`,
+	}
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := NewParserBytes(data)
+		invalid := false
+		for p.Next() {
+			if p.Token() == Invalid {
+				invalid = true
+			}
+		}
+		if p.Next() {
+			t.Fatal("Next returned true after the end of the tokens")
+		}
+
+		err := p.Err()
+		if invalid && err == nil {
+			t.Fatal("Invalid token without error")
+		}
+		if err != nil && err != io.ErrUnexpectedEOF && p.Token() != Invalid {
+			t.Fatalf("error with %s token: %v", p.Token(), err)
+		}
+		// the validator also rejects a document without value
+		valid := err == nil && p.TokenCount() > 0
+		if verr := ValidateBytes(data); (verr == nil) != valid {
+			t.Fatalf("parser error %v, validator error %v", err, verr)
+		}
+	})
+}
+
+// closeCounter is an io.ReadCloser that counts the calls to Close.
+type closeCounter struct {
+	io.Reader
+	n int
+}
+
+func (c *closeCounter) Close() error {
+	c.n++
+	return nil
+}
+
+type seekCloseCounter struct {
+	*strings.Reader
+	n int
+}
+
+func (c *seekCloseCounter) Close() error {
+	c.n++
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	var _ io.Closer = (*Parser)(nil)
+
+	// the reader is not closed once exhausted, but by Close
+	rc := &closeCounter{Reader: strings.NewReader(`[1, 2]`)}
+	p := NewParser(rc)
+	for p.Next() {
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rc.n != 0 {
+		t.Errorf("want reader to be open, got %d calls to Close", rc.n)
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once, got %d calls to Close", rc.n)
+	}
+
+	// closed before the end of the input
+	rc = &closeCounter{Reader: strings.NewReader(`[1, 2]`)}
+	p = NewParser(rc)
+	if !p.Next() {
+		t.Fatal(p.Err())
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Next() {
+		t.Errorf("want no token after Close, got %v", p.Token())
+	}
+	if err := p.Err(); err != io.ErrClosedPipe {
+		t.Errorf("want %v, got %v", io.ErrClosedPipe, err)
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once, got %d calls to Close", rc.n)
+	}
+
+	// Reset closes the previous reader, and reopens the parser
+	rc = &closeCounter{Reader: strings.NewReader(`1`)}
+	p.Reset(rc)
+	p.ResetString(`true`)
+	if !p.Next() || p.Token() != True {
+		t.Errorf("want true after Reset, got %v (%v)", p.Token(), p.Err())
+	}
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once by Reset, got %d calls to Close", rc.n)
+	}
+	p.ResetString(`false`)
+	if rc.n != 1 {
+		t.Errorf("want reader to be closed once, got %d calls to Close", rc.n)
+	}
+}
+
+func TestRewind(t *testing.T) {
+	const doc = `{"a": [1, 2]} [[3]]`
+	opts := []ParserOption{WithMultiValue(), WithMaxDepth(1)}
+	readers := []struct {
+		name string
+		p    *Parser
+	}{
+		{"bytes", NewParserBytes(nil)},
+		{"string", NewParserString("")},
+		{"RuneReader", NewParserOptions(strings.NewReader(doc), opts...)},
+		{"ReadSeeker", NewParserOptions(struct{ io.ReadSeeker }{strings.NewReader(doc)}, opts...)},
+	}
+	readers[0].p.ResetBytes([]byte(doc), opts...)
+	readers[1].p.ResetString(doc, opts...)
+
+	for _, r := range readers {
+		p := r.p
+		var first []Token
+		for p.Next() {
+			first = append(first, p.Token())
+		}
+		if want := (&DepthLimitError{Limit: 1}); !reflect.DeepEqual(want, p.Err()) {
+			t.Errorf("%s: want %v, got %v", r.name, want, p.Err())
+		}
+
+		// rewind in the middle of the input and at the end
+		for i := 0; i < 2; i++ {
+			if err := p.Rewind(); err != nil {
+				t.Fatalf("%s: %v", r.name, err)
+			}
+			var toks []Token
+			for p.Next() {
+				toks = append(toks, p.Token())
+				if i == 0 && len(toks) == 2 {
+					break
+				}
+			}
+			if i == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(first, toks) {
+				t.Errorf("%s: want %v after Rewind, got %v", r.name, first, toks)
+			}
+			if want := (&DepthLimitError{Limit: 1}); !reflect.DeepEqual(want, p.Err()) {
+				t.Errorf("%s: want %v after Rewind, got %v", r.name, want, p.Err())
+			}
+		}
+	}
+
+	// the reader is not closed by Rewind
+	rc := &seekCloseCounter{Reader: strings.NewReader(doc)}
+	p := NewParser(rc)
+	p.Next()
+	if err := p.Rewind(); err != nil || rc.n != 0 {
+		t.Errorf("want reader to be open after Rewind, got %d calls to Close (%v)", rc.n, err)
+	}
+
+	p = NewParser(struct{ io.Reader }{strings.NewReader(doc)})
+	if err := p.Rewind(); err != ErrNotSeekable {
+		t.Errorf("want %v, got %v", ErrNotSeekable, err)
+	}
+}
@@ -0,0 +1,317 @@
+package jsonb
+
+import "fmt"
+
+// PatchError is returned when an operation of a JSON patch is invalid.
+type PatchError struct {
+	Index  int    // index of the operation in the patch
+	Op     string // name of the operation, if known
+	Reason string
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("jsonb: patch operation %d (%s): %s", e.Index, e.Op, e.Reason)
+}
+
+// TestFailedError is returned when a test operation of a JSON patch fails.
+type TestFailedError struct {
+	Index int    // index of the operation in the patch
+	Path  string // path of the tested value
+}
+
+func (e *TestFailedError) Error() string {
+	return fmt.Sprintf("jsonb: patch operation %d: test failed at %q", e.Index, e.Path)
+}
+
+// operation is an operation of a JSON patch.
+type operation struct {
+	op, path, from string
+	value          *Node
+}
+
+// Apply applies the JSON patch to the JSON document doc, as defined by
+// RFC 6902, and returns the resulting document in compact form. The
+// operations are applied atomically: if an operation fails, the original
+// document is returned along with the error, which is a *TestFailedError
+// if a test operation failed, a *PointerError if a path cannot be resolved
+// and a *PatchError if an operation is invalid.
+func Apply(doc, patch []byte) ([]byte, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return doc, err
+	}
+	root, err := parseTree(doc)
+	if err != nil {
+		return doc, err
+	}
+
+	for i, op := range ops {
+		if root, err = op.apply(root, i); err != nil {
+			return doc, err
+		}
+	}
+	return root.appendJSON(nil), nil
+}
+
+// parsePatch parses the operations of the JSON patch.
+func parsePatch(patch []byte) ([]operation, error) {
+	p := NewParserBytes(patch)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	if p.tok != ArrayStart {
+		return nil, &PatchError{Index: -1, Reason: "patch is not an array"}
+	}
+
+	var ops []operation
+	for i := 0; ; i++ {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		if p.tok == ArrayEnd {
+			break
+		}
+		if p.tok != ObjectStart {
+			return nil, &PatchError{Index: i, Reason: "operation is not an object"}
+		}
+
+		var op operation
+		var hasPath, hasFrom bool
+		for {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if p.tok == ObjectEnd {
+				break
+			}
+			key, err := p.String()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+
+			switch key {
+			case "op", "path", "from":
+				s, err := p.String()
+				if err != nil {
+					return nil, &PatchError{Index: i, Op: op.op, Reason: key + " is not a string"}
+				}
+				switch key {
+				case "op":
+					op.op = s
+				case "path":
+					op.path, hasPath = s, true
+				case "from":
+					op.from, hasFrom = s, true
+				}
+			case "value":
+				if op.value, err = parseNode(p); err != nil {
+					return nil, err
+				}
+			default:
+				if !p.Skip() {
+					return nil, p.valueErr()
+				}
+			}
+		}
+
+		switch {
+		case !hasPath:
+			return nil, &PatchError{Index: i, Op: op.op, Reason: "missing path"}
+		case (op.op == "move" || op.op == "copy") && !hasFrom:
+			return nil, &PatchError{Index: i, Op: op.op, Reason: "missing from"}
+		case (op.op == "add" || op.op == "replace" || op.op == "test") && op.value == nil:
+			return nil, &PatchError{Index: i, Op: op.op, Reason: "missing value"}
+		}
+		ops = append(ops, op)
+	}
+
+	// only whitespace may follow the patch
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// apply applies the operation at index i of the patch to root and returns
+// the new root.
+func (op *operation) apply(root *Node, i int) (*Node, error) {
+	switch op.op {
+	case "add":
+		return root.add(op.path, op.value.clone())
+
+	case "remove":
+		_, root, err := root.remove(op.path)
+		return root, err
+
+	case "replace":
+		return root.replace(op.path, op.value.clone())
+
+	case "move":
+		if op.path != op.from && hasPrefix(op.path, op.from) {
+			return root, &PatchError{Index: i, Op: op.op, Reason: "cannot move a value into itself"}
+		}
+		val, root, err := root.remove(op.from)
+		if err != nil {
+			return root, err
+		}
+		return root.add(op.path, val)
+
+	case "copy":
+		val, err := root.lookup(op.from)
+		if err != nil {
+			return root, err
+		}
+		return root.add(op.path, val.clone())
+
+	case "test":
+		val, err := root.lookup(op.path)
+		if err != nil {
+			return root, err
+		}
+		if !val.equal(op.value) {
+			return root, &TestFailedError{Index: i, Path: op.path}
+		}
+		return root, nil
+	}
+	return root, &PatchError{Index: i, Op: op.op, Reason: "unknown operation"}
+}
+
+// hasPrefix returns true if the JSON pointer from is a prefix of path.
+func hasPrefix(path, from string) bool {
+	return len(path) > len(from) && path[:len(from)] == from && path[len(from)] == '/'
+}
+
+// lookup returns the node addressed by the JSON pointer ptr in n.
+func (n *Node) lookup(ptr string) (*Node, error) {
+	segs, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		i, err := n.child(ptr, seg, false)
+		if err != nil {
+			return nil, err
+		}
+		n = n.elems[i]
+	}
+	return n, nil
+}
+
+// parent returns the node that holds the value addressed by the JSON pointer
+// ptr in n, along with the last segment of ptr. The pointer must not be empty.
+func (n *Node) parent(ptr string) (*Node, string, error) {
+	segs, err := parsePointer(ptr)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, seg := range segs[:len(segs)-1] {
+		i, err := n.child(ptr, seg, false)
+		if err != nil {
+			return nil, "", err
+		}
+		n = n.elems[i]
+	}
+	return n, segs[len(segs)-1], nil
+}
+
+// child returns the index of the element of n addressed by the segment seg.
+// If insert is true, the index may be the length of an array, and the index
+// of a missing key of an object is -1.
+func (n *Node) child(ptr, seg string, insert bool) (int, error) {
+	switch n.tok {
+	case ObjectStart:
+		i := n.index(seg)
+		if i < 0 && !insert {
+			return 0, &PointerError{Pointer: ptr, Segment: seg, Reason: reasonKeyNotFound}
+		}
+		return i, nil
+
+	case ArrayStart:
+		i, ok := arrayIndex(seg)
+		if !ok {
+			return 0, &PointerError{Pointer: ptr, Segment: seg, Reason: reasonInvalidIndex}
+		}
+		if i < 0 && insert {
+			i = len(n.elems)
+		}
+		if i < 0 || i > len(n.elems) || i == len(n.elems) && !insert {
+			return 0, &PointerError{Pointer: ptr, Segment: seg, Reason: reasonOutOfRange}
+		}
+		return i, nil
+	}
+	return 0, &PointerError{Pointer: ptr, Segment: seg, Reason: reasonNotContainer}
+}
+
+// add adds val at the location addressed by the JSON pointer ptr in the
+// root node n, and returns the new root.
+func (n *Node) add(ptr string, val *Node) (*Node, error) {
+	if ptr == "" {
+		return val, nil
+	}
+	parent, seg, err := n.parent(ptr)
+	if err != nil {
+		return n, err
+	}
+	i, err := parent.child(ptr, seg, true)
+	if err != nil {
+		return n, err
+	}
+
+	switch {
+	case parent.tok == ArrayStart:
+		parent.elems = append(parent.elems, nil)
+		copy(parent.elems[i+1:], parent.elems[i:])
+		parent.elems[i] = val
+	case i < 0:
+		parent.keys = append(parent.keys, seg)
+		parent.elems = append(parent.elems, val)
+	default:
+		parent.elems[i] = val
+	}
+	return n, nil
+}
+
+// replace replaces the value addressed by the JSON pointer ptr in the root
+// node n with val, and returns the new root.
+func (n *Node) replace(ptr string, val *Node) (*Node, error) {
+	if ptr == "" {
+		return val, nil
+	}
+	parent, seg, err := n.parent(ptr)
+	if err != nil {
+		return n, err
+	}
+	i, err := parent.child(ptr, seg, false)
+	if err != nil {
+		return n, err
+	}
+	parent.elems[i] = val
+	return n, nil
+}
+
+// remove removes the value addressed by the JSON pointer ptr in the root
+// node n, and returns it along with the new root.
+func (n *Node) remove(ptr string) (*Node, *Node, error) {
+	if ptr == "" {
+		return nil, n, &PointerError{Pointer: ptr, Reason: "cannot remove the root value"}
+	}
+	parent, seg, err := n.parent(ptr)
+	if err != nil {
+		return nil, n, err
+	}
+	i, err := parent.child(ptr, seg, false)
+	if err != nil {
+		return nil, n, err
+	}
+
+	val := parent.elems[i]
+	parent.elems = append(parent.elems[:i], parent.elems[i+1:]...)
+	if parent.tok == ObjectStart {
+		parent.keys = append(parent.keys[:i], parent.keys[i+1:]...)
+	}
+	return val, n, nil
+}
@@ -0,0 +1,64 @@
+package jsonb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	// mostly from the examples of RFC 6902, appendix A
+	cases := []struct {
+		doc   string
+		patch string
+		out   string
+		err   error
+	}{
+		{doc: `{"foo": "bar"}`, patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`, out: `{"foo":"bar","baz":"qux"}`},
+		{doc: `{"foo": ["bar", "baz"]}`, patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, out: `{"foo":["bar","qux","baz"]}`},
+		{doc: `{"baz": "qux", "foo": "bar"}`, patch: `[{"op": "remove", "path": "/baz"}]`, out: `{"foo":"bar"}`},
+		{doc: `{"foo": ["bar", "qux", "baz"]}`, patch: `[{"op": "remove", "path": "/foo/1"}]`, out: `{"foo":["bar","baz"]}`},
+		{doc: `{"baz": "qux", "foo": "bar"}`, patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`, out: `{"baz":"boo","foo":"bar"}`},
+		{doc: `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			out: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{doc: `{"foo": ["all", "grass", "cows", "eat"]}`, patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			out: `{"foo":["all","cows","eat","grass"]}`},
+		{doc: `{"baz": "qux", "foo": ["a", 2, "c"]}`, patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			out: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{doc: `{"baz": "qux"}`, patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`, out: `{"baz": "qux"}`,
+			err: &TestFailedError{Index: 0, Path: "/baz"}},
+		{doc: `{"foo": "bar"}`, patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, out: `{"foo":"bar","child":{"grandchild":{}}}`},
+		{doc: `{"foo": "bar"}`, patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, out: `{"foo":"bar","baz":"qux"}`},
+		{doc: `{"foo": "bar"}`, patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, out: `{"foo": "bar"}`,
+			err: &PointerError{Pointer: "/baz/bat", Segment: "baz", Reason: "key not found"}},
+		{doc: `{"/": 9, "~1": 10}`, patch: `[{"op": "test", "path": "/~01", "value": 10}]`, out: `{"/":9,"~1":10}`},
+		{doc: `{"/": 9, "~1": 10}`, patch: `[{"op": "test", "path": "/~01", "value": "10"}]`, out: `{"/": 9, "~1": 10}`,
+			err: &TestFailedError{Index: 0, Path: "/~01"}},
+		{doc: `{"foo": ["bar"]}`, patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, out: `{"foo":["bar",["abc","def"]]}`},
+		{doc: `{"a": 1}`, patch: `[{"op": "copy", "from": "/a", "path": "/b"}, {"op": "test", "path": "/b", "value": 1.0e0}]`, out: `{"a":1,"b":1}`},
+		{doc: `{"a": {"b": "c"}}`, patch: `[{"op": "test", "path": "/a", "value": {"b": "c"}}]`, out: `{"a":{"b":"c"}}`},
+		{doc: `{"a": 1e9999999}`, patch: `[{"op": "test", "path": "/a", "value": 10e9999998}]`, out: `{"a":1e9999999}`},
+		{doc: `{"a": 1e999999}`, patch: `[{"op": "test", "path": "/a", "value": 2e999999}]`, out: `{"a": 1e999999}`,
+			err: &TestFailedError{Index: 0, Path: "/a"}},
+		{doc: `{"a": 1}`, patch: `[{"op": "replace", "path": "", "value": [true]}]`, out: `[true]`},
+		// atomicity
+		{doc: `{"a": 1}`, patch: `[{"op": "remove", "path": "/a"}, {"op": "remove", "path": "/a"}]`, out: `{"a": 1}`,
+			err: &PointerError{Pointer: "/a", Segment: "a", Reason: "key not found"}},
+		{doc: `{"a": {"b": 1}}`, patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`, out: `{"a": {"b": 1}}`,
+			err: &PatchError{Index: 0, Op: "move", Reason: "cannot move a value into itself"}},
+		{doc: `[]`, patch: `[{"op": "add", "path": "/1", "value": 1}]`, out: `[]`,
+			err: &PointerError{Pointer: "/1", Segment: "1", Reason: "index out of range"}},
+		{doc: `[]`, patch: `[{"op": "add", "value": 1}]`, out: `[]`, err: &PatchError{Index: 0, Op: "add", Reason: "missing path"}},
+		{doc: `[]`, patch: `[{"op": "frob", "path": ""}]`, out: `[]`, err: &PatchError{Index: 0, Op: "frob", Reason: "unknown operation"}},
+		{doc: `[]`, patch: `{}`, out: `[]`, err: &PatchError{Index: -1, Reason: "patch is not an array"}},
+	}
+
+	for i, c := range cases {
+		out, err := Apply([]byte(c.doc), []byte(c.patch))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package jsonb
+
+// snapshot holds the state of the parser that describes its current token,
+// so that it can be restored after looking ahead.
+type snapshot struct {
+	ok     bool // value returned by Next
+	tok    Token
+	buf    []byte
+	err    error
+	start  int64
+	stack  []state
+	path   []pathFrame
+	keys   []byte
+	keyBuf []byte
+	keyed  int
+	ntok   int64
+	stats  ParseStats
+	docs   int
+	bad    bool
+	done   bool
+}
+
+// Peek returns the token that the next call to Next will return, without
+// advancing the parser. The lookahead is buffered until the next call to
+// Next, and the current token, its bytes and the path of the parser are
+// not affected. It returns Invalid if Next would return false.
+func (p *Parser) Peek() Token {
+	if p.sub != nil {
+		p.blocked()
+		return Invalid
+	}
+	if !p.peeked {
+		p.save(&p.cur, false)
+		ok := p.advance()
+		p.save(&p.ahead, ok)
+		p.restore(&p.cur)
+		p.peeked = true
+	}
+	if !p.ahead.ok {
+		return Invalid
+	}
+	return p.ahead.tok
+}
+
+// save stores the state of the current token in s.
+func (p *Parser) save(s *snapshot, ok bool) {
+	s.ok = ok
+	s.tok = p.tok
+	s.buf = append(s.buf[:0], p.buf.Bytes()...)
+	s.err = p.err
+	s.start = p.start
+	s.stack = append(s.stack[:0], p.stack...)
+	s.path = append(s.path[:0], p.path...)
+	s.keys = append(s.keys[:0], p.keys...)
+	s.keyBuf = append(s.keyBuf[:0], p.keyBuf...)
+	s.keyed = p.keyed
+	s.ntok = p.ntok
+	s.stats = p.stats
+	s.docs = p.docs
+	s.bad = p.bad
+	s.done = p.done
+}
+
+// restore sets the state of the current token from s.
+func (p *Parser) restore(s *snapshot) {
+	p.tok = s.tok
+	p.buf.Reset()
+	p.buf.Write(s.buf)
+	p.err = s.err
+	p.start = s.start
+	p.stack = append(p.stack[:0], s.stack...)
+	p.path = append(p.path[:0], s.path...)
+	p.keys = append(p.keys[:0], s.keys...)
+	p.keyBuf = append(p.keyBuf[:0], s.keyBuf...)
+	p.keyed = s.keyed
+	p.ntok = s.ntok
+	p.stats = s.stats
+	p.docs = s.docs
+	p.bad = s.bad
+	p.done = s.done
+}
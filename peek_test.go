@@ -0,0 +1,85 @@
+package jsonb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPeek(t *testing.T) {
+	cases := []string{
+		``,
+		`1`,
+		`[1, "a", true]`,
+		`{"a": [1, {"b": null}], "c": "d"}`,
+		`[1, [2, , 3]]`,
+		`[1, 2`,
+	}
+
+	// describe returns the observable state of the current token.
+	describe := func(p *Parser) string {
+		return fmt.Sprintf("%v %s %s %d %v %d %v", p.Token(), p.Bytes(), p.Key(), p.Offset(), p.Path(), p.Depth(), p.Err())
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		var want []string
+		p.Reset(strings.NewReader(c))
+		for p.Next() {
+			want = append(want, describe(p))
+		}
+		wantErr := p.Err()
+
+		var got []string
+		p.Reset(strings.NewReader(c))
+		for {
+			before := describe(p)
+			tok := p.Peek()
+			if tok2 := p.Peek(); tok2 != tok {
+				t.Errorf("%d (%s): second Peek returned %v, want %v", i, c, tok2, tok)
+			}
+			if after := describe(p); after != before {
+				t.Errorf("%d (%s): Peek changed the current token from %s to %s", i, c, before, after)
+			}
+			if !p.Next() {
+				if tok != Invalid {
+					t.Errorf("%d (%s): Peek returned %v at the end", i, c, tok)
+				}
+				break
+			}
+			if tok != p.Token() {
+				t.Errorf("%d (%s): Peek returned %v, Next %v", i, c, tok, p.Token())
+			}
+			got = append(got, describe(p))
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%d (%s): want %q, got %q", i, c, want, got)
+		}
+		if err := p.Err(); !reflect.DeepEqual(wantErr, err) {
+			t.Errorf("%d (%s): want error %v, got %v", i, c, wantErr, err)
+		}
+	}
+}
+
+func TestPeekSkip(t *testing.T) {
+	p := NewParser(strings.NewReader(`[[1, 2], 3]`))
+	p.Next()
+	p.Next()
+	if tok := p.Peek(); tok != Number {
+		t.Fatalf("want %v, got %v", Number, tok)
+	}
+	if !p.Skip() {
+		t.Fatalf("Skip failed: %v", p.Err())
+	}
+	if !p.Next() || p.Token() != Number || string(p.Bytes()) != "3" {
+		t.Errorf("want number 3, got %v %s", p.Token(), p.Bytes())
+	}
+	p.Next()
+	if tok := p.Peek(); tok != Invalid {
+		t.Errorf("want %v past the end, got %v", Invalid, tok)
+	}
+	if p.Next() || p.Err() != nil {
+		t.Errorf("want end of input, got %v", p.Err())
+	}
+}
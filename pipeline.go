@@ -0,0 +1,183 @@
+package jsonb
+
+import (
+	"bufio"
+	"io"
+)
+
+// Transform transforms a token of a Pipeline. It receives the token and its
+// bytes and returns the token and bytes to write, and false to drop the
+// token. The bytes received are only valid for the duration of the call.
+//
+// After the bytes of an ArrayStart or an ObjectStart token, a transform may
+// return complete elements or members of that container, separated by
+// commas, which are then written at the start of the container.
+type Transform func(Token, []byte) (Token, []byte, bool)
+
+// Pipeline reads a JSON document, applies a chain of transforms to each of
+// its tokens and writes the resulting document in compact form. The
+// transforms returned by the functions of this package keep some state, so
+// a pipeline can only be run once.
+type Pipeline struct {
+	src        io.Reader
+	transforms []Transform
+}
+
+// NewPipeline returns a pipeline that reads from src and applies the
+// transforms, in order, to each token.
+func NewPipeline(src io.Reader, transforms ...Transform) *Pipeline {
+	return &Pipeline{src: src, transforms: transforms}
+}
+
+// Run reads the whole document and writes the transformed document to dst.
+// It inserts the commas and colons between the tokens that are written.
+func (pl *Pipeline) Run(dst io.Writer) error {
+	p := NewParser(pl.src)
+	w := bufio.NewWriter(dst)
+
+	var elems []bool // the container at that depth has elements
+	afterKey := false
+	for p.Next() {
+		tok, b := p.Token(), p.Bytes()
+		if tok == Invalid {
+			break
+		}
+		keep := true
+		for _, t := range pl.transforms {
+			if tok, b, keep = t(tok, b); !keep {
+				break
+			}
+		}
+		if !keep {
+			continue
+		}
+
+		if tok.IsEnd() {
+			elems = elems[:len(elems)-1]
+			w.Write(b)
+			continue
+		}
+		if l := len(elems); afterKey {
+			w.WriteByte(':')
+			afterKey = false
+		} else if l > 0 && elems[l-1] {
+			w.WriteByte(',')
+		}
+		if l := len(elems); l > 0 {
+			elems[l-1] = true
+		}
+		w.Write(b)
+		switch {
+		case tok == ObjectKey:
+			afterKey = true
+		case tok.IsStart():
+			elems = append(elems, len(b) > 1)
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// FilterKeys returns a transform that keeps only the members of the
+// top-level object with one of the specified keys.
+func FilterKeys(keys ...string) Transform {
+	return memberTransform(func(key string) (string, bool) {
+		for _, k := range keys {
+			if k == key {
+				return key, true
+			}
+		}
+		return key, false
+	})
+}
+
+// DeleteField returns a transform that drops the members of the top-level
+// object with the specified key.
+func DeleteField(key string) Transform {
+	return memberTransform(func(k string) (string, bool) {
+		return k, k != key
+	})
+}
+
+// RenameKey returns a transform that renames the members of the top-level
+// object with the key from to the key to.
+func RenameKey(from, to string) Transform {
+	return memberTransform(func(key string) (string, bool) {
+		if key == from {
+			return to, true
+		}
+		return key, true
+	})
+}
+
+// AddField returns a transform that adds a member with the specified key
+// and value at the start of the top-level object. The value must be a valid
+// JSON value and is written as-is.
+func AddField(key string, value []byte) Transform {
+	var depth int
+	return func(tok Token, b []byte) (Token, []byte, bool) {
+		switch {
+		case tok == ObjectStart && depth == 0:
+			b = append([]byte(nil), b...)
+			if len(b) > 1 {
+				// members added by a previous transform
+				b = append(b, ',')
+			}
+			b = append(appendQuote(b, key), ':')
+			b = append(b, value...)
+			depth++
+		case tok.IsStart():
+			depth++
+		case tok.IsEnd():
+			depth--
+		}
+		return tok, b, true
+	}
+}
+
+// memberTransform returns a transform that calls fn with the decoded key of
+// each member of the top-level object, and writes the member with the key
+// returned by fn, or drops the member and its value if fn returns false.
+func memberTransform(fn func(key string) (string, bool)) Transform {
+	var depth int      // depth of the last token
+	var object bool    // the top-level value is an object
+	var skip bool      // dropping the value of a member
+	var renamed []byte // buffer of the renamed key
+	return func(tok Token, b []byte) (Token, []byte, bool) {
+		switch {
+		case tok.IsStart():
+			if depth == 0 {
+				object = tok == ObjectStart
+			}
+			depth++
+		case tok.IsEnd():
+			depth--
+		}
+
+		if skip {
+			// the value ends when the parser is back in the top-level object
+			skip = depth > 1
+			return tok, b, false
+		}
+		if tok != ObjectKey || depth != 1 || !object {
+			return tok, b, true
+		}
+
+		k, err := appendUnquote(nil, b)
+		if err != nil {
+			return tok, b, true
+		}
+		key, keep := fn(string(k))
+		if !keep {
+			skip = true
+			return tok, b, false
+		}
+		if key != string(k) {
+			renamed = appendQuote(renamed[:0], key)
+			b = renamed
+		}
+		return tok, b, true
+	}
+}
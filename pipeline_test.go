@@ -0,0 +1,48 @@
+package jsonb
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	const in = ` {"id": 1, "name": "a", "tags": ["x", {"id": 2}], "meta": {"name": "b"}, "secret": [1, [2]]} `
+
+	cases := []struct {
+		in         string
+		transforms []Transform
+		out        string
+		err        error
+	}{
+		{in: in, out: `{"id":1,"name":"a","tags":["x",{"id":2}],"meta":{"name":"b"},"secret":[1,[2]]}`},
+		{in: in, transforms: []Transform{FilterKeys("id", "meta")}, out: `{"id":1,"meta":{"name":"b"}}`},
+		{in: in, transforms: []Transform{FilterKeys()}, out: `{}`},
+		{in: in, transforms: []Transform{DeleteField("secret"), DeleteField("tags")}, out: `{"id":1,"name":"a","meta":{"name":"b"}}`},
+		{in: in, transforms: []Transform{DeleteField("id")}, out: `{"name":"a","tags":["x",{"id":2}],"meta":{"name":"b"},"secret":[1,[2]]}`},
+		{in: in, transforms: []Transform{RenameKey("name", "Name\n"), FilterKeys("Name\n")}, out: `{"Name\n":"a"}`},
+		{in: in, transforms: []Transform{FilterKeys("id"), AddField("v", []byte(`[true]`))}, out: `{"v":[true],"id":1}`},
+		{in: `{}`, transforms: []Transform{AddField("a", []byte(`1`)), AddField("b", []byte(`2`))}, out: `{"a":1,"b":2}`},
+		{in: `[{"a": 1}, {"b": 2}]`, transforms: []Transform{DeleteField("a"), AddField("c", []byte(`3`))}, out: `[{"a":1},{"b":2}]`},
+		{in: `{"a": 1, "b": 2}`, transforms: []Transform{
+			func(tok Token, b []byte) (Token, []byte, bool) {
+				if tok == Number {
+					b = append([]byte("-"), b...)
+				}
+				return tok, b, true
+			}}, out: `{"a":-1,"b":-2}`},
+		{in: `{"a": 1`, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		var buf bytes.Buffer
+		err := NewPipeline(strings.NewReader(c.in), c.transforms...).Run(&buf)
+		if err != c.err {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if c.err == nil && buf.String() != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, buf.String())
+		}
+	}
+}
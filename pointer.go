@@ -0,0 +1,215 @@
+package jsonb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned by GetPath when the path does not exist in
+	// the document.
+	ErrNotFound = errors.New("jsonb: path not found")
+
+	// ErrIndexOutOfRange is returned by GetPath when an array index of the
+	// path is past the end of the array.
+	ErrIndexOutOfRange = errors.New("jsonb: array index out of range")
+)
+
+// The reasons of the PointerError returned when a path cannot be resolved.
+const (
+	reasonKeyNotFound  = "key not found"
+	reasonOutOfRange   = "index out of range"
+	reasonInvalidIndex = "invalid array index"
+	reasonNotContainer = "value is not an array or an object"
+)
+
+// PointerError is returned when a JSON pointer is invalid or cannot be
+// resolved in a document.
+type PointerError struct {
+	Pointer string // the JSON pointer
+	Segment string // the unescaped segment that failed, if any
+	Reason  string // the reason of the failure
+}
+
+func (e *PointerError) Error() string {
+	if e.Segment == "" {
+		return fmt.Sprintf("jsonb: pointer %q: %s", e.Pointer, e.Reason)
+	}
+	return fmt.Sprintf("jsonb: pointer %q: segment %q: %s", e.Pointer, e.Segment, e.Reason)
+}
+
+// Get returns the raw bytes of the value of the JSON document data that is
+// addressed by the JSON pointer ptr, as defined by RFC 6901. The empty
+// pointer addresses the whole document. Only the part of the document that
+// precedes the value and the value itself are parsed.
+func Get(data []byte, ptr string) ([]byte, error) {
+	segs, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := NewParserBytes(data)
+	if !p.Next() || p.tok == Invalid {
+		return nil, p.valueErr()
+	}
+	for _, seg := range segs {
+		if err := p.descend(ptr, seg); err != nil {
+			return nil, err
+		}
+	}
+
+	start := p.start
+	if !p.Skip() {
+		return nil, p.valueErr()
+	}
+	// the current token is the value itself or the end of the container
+	return data[start : p.start+int64(p.buf.Len())], nil
+}
+
+// GetPath is like Get, but the value is addressed by the segments of path,
+// which are object keys or array indices as decimal strings, without the
+// escaping of JSON pointers. It returns ErrNotFound if the path does not
+// exist in the document, and ErrIndexOutOfRange if an array index is past
+// the end of its array.
+func GetPath(data []byte, path ...string) ([]byte, error) {
+	p := NewParserBytes(data)
+	if !p.Next() || p.tok == Invalid {
+		return nil, p.valueErr()
+	}
+	for _, seg := range path {
+		if err := p.descend("", seg); err != nil {
+			if pe, ok := err.(*PointerError); ok {
+				if pe.Reason == reasonOutOfRange {
+					return nil, ErrIndexOutOfRange
+				}
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+	}
+
+	start := p.start
+	if !p.Skip() {
+		return nil, p.valueErr()
+	}
+	return data[start : p.start+int64(p.buf.Len())], nil
+}
+
+// parsePointer returns the unescaped segments of the JSON pointer ptr.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, &PointerError{Pointer: ptr, Reason: "must start with /"}
+	}
+
+	segs := strings.Split(ptr[1:], "/")
+	for i, seg := range segs {
+		if strings.IndexByte(seg, '~') < 0 {
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(seg); j++ {
+			c := seg[j]
+			if c == '~' {
+				if j+1 == len(seg) || (seg[j+1] != '0' && seg[j+1] != '1') {
+					return nil, &PointerError{Pointer: ptr, Segment: seg, Reason: "invalid escape sequence"}
+				}
+				if j++; seg[j] == '0' {
+					c = '~'
+				} else {
+					c = '/'
+				}
+			}
+			b.WriteByte(c)
+		}
+		segs[i] = b.String()
+	}
+	return segs, nil
+}
+
+// pointerEscaper escapes the segments of a JSON pointer.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// formatPointer returns the JSON pointer made of the unescaped segments.
+func formatPointer(segs []string) string {
+	var b strings.Builder
+	for _, seg := range segs {
+		b.WriteByte('/')
+		pointerEscaper.WriteString(&b, seg)
+	}
+	return b.String()
+}
+
+// descend advances the parser on the value addressed by the segment seg in
+// the current array or object.
+func (p *Parser) descend(ptr, seg string) error {
+	switch p.tok {
+	case ObjectStart:
+		var key []byte
+		for {
+			if !p.Next() || p.tok == Invalid {
+				return p.valueErr()
+			}
+			if p.tok == ObjectEnd {
+				return &PointerError{Pointer: ptr, Segment: seg, Reason: reasonKeyNotFound}
+			}
+
+			var err error
+			if key, err = appendUnquote(key[:0], p.buf.Bytes()); err != nil {
+				return err
+			}
+			if !p.Next() || p.tok == Invalid {
+				return p.valueErr()
+			}
+			if string(key) == seg {
+				return nil
+			}
+			if !p.Skip() {
+				return p.valueErr()
+			}
+		}
+
+	case ArrayStart:
+		idx, ok := arrayIndex(seg)
+		if !ok {
+			return &PointerError{Pointer: ptr, Segment: seg, Reason: reasonInvalidIndex}
+		}
+		for i := 0; ; i++ {
+			if !p.Next() || p.tok == Invalid {
+				return p.valueErr()
+			}
+			if p.tok == ArrayEnd {
+				return &PointerError{Pointer: ptr, Segment: seg, Reason: reasonOutOfRange}
+			}
+			if i == idx {
+				return nil
+			}
+			if !p.Skip() {
+				return p.valueErr()
+			}
+		}
+	}
+	return &PointerError{Pointer: ptr, Segment: seg, Reason: reasonNotContainer}
+}
+
+// arrayIndex returns the array index represented by seg. The "-" index
+// that refers to the element past the end of an array is returned as -1.
+func arrayIndex(seg string) (int, bool) {
+	if seg == "-" {
+		return -1, true
+	}
+	if seg == "" || len(seg) > 1 && seg[0] == '0' || len(seg) > 9 {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(seg); i++ {
+		if !isDigit(seg[i]) {
+			return 0, false
+		}
+		n = n*10 + int(seg[i]-'0')
+	}
+	return n, true
+}
@@ -0,0 +1,124 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	// example document of RFC 6901
+	const doc = `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8,
+		"nested": {"x": [1, {"y": [true, null]}], "a": "b"}
+	}`
+
+	cases := []struct {
+		ptr string
+		out string
+		err error
+	}{
+		{ptr: "", out: doc},
+		{ptr: "/foo", out: `["bar", "baz"]`},
+		{ptr: "/foo/0", out: `"bar"`},
+		{ptr: "/foo/1", out: `"baz"`},
+		{ptr: "/", out: `0`},
+		{ptr: "/a~1b", out: `1`},
+		{ptr: "/c%d", out: `2`},
+		{ptr: "/e^f", out: `3`},
+		{ptr: "/g|h", out: `4`},
+		{ptr: "/i\\j", out: `5`},
+		{ptr: "/k\"l", out: `6`},
+		{ptr: "/ ", out: `7`},
+		{ptr: "/m~0n", out: `8`},
+		{ptr: "/nested/x/1/y", out: `[true, null]`},
+		{ptr: "/nested/x/1/y/1", out: `null`},
+		{ptr: "/nested/a", out: `"b"`},
+		{ptr: "foo", err: &PointerError{Pointer: "foo", Reason: "must start with /"}},
+		{ptr: "/m~2n", err: &PointerError{Pointer: "/m~2n", Segment: "m~2n", Reason: "invalid escape sequence"}},
+		{ptr: "/bar", err: &PointerError{Pointer: "/bar", Segment: "bar", Reason: "key not found"}},
+		{ptr: "/foo/2", err: &PointerError{Pointer: "/foo/2", Segment: "2", Reason: "index out of range"}},
+		{ptr: "/foo/-", err: &PointerError{Pointer: "/foo/-", Segment: "-", Reason: "index out of range"}},
+		{ptr: "/foo/01", err: &PointerError{Pointer: "/foo/01", Segment: "01", Reason: "invalid array index"}},
+		{ptr: "/foo/a", err: &PointerError{Pointer: "/foo/a", Segment: "a", Reason: "invalid array index"}},
+		{ptr: "/foo/0/x", err: &PointerError{Pointer: "/foo/0/x", Segment: "x", Reason: "value is not an array or an object"}},
+	}
+
+	for i, c := range cases {
+		out, err := Get([]byte(doc), c.ptr)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.ptr, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d (%s): want %s, got %s", i, c.ptr, c.out, out)
+		}
+	}
+}
+
+func TestGetInvalid(t *testing.T) {
+	cases := []struct {
+		doc string
+		ptr string
+		err error
+	}{
+		{doc: ``, ptr: "", err: io.ErrUnexpectedEOF},
+		{doc: `{"a": [1, 2}`, ptr: "/a", err: &SyntaxError{Char: '}', Line: 1, Column: 12, Offset: 11, Near: []byte(`{"a": [1, 2}`), typ: begVal}},
+		{doc: `{"a": [1, 2]`, ptr: "/b", err: io.ErrUnexpectedEOF},
+		// the document after the value is not parsed
+		{doc: `{"a": [1, 2], "b": }`, ptr: "/a"},
+	}
+
+	for i, c := range cases {
+		_, err := Get([]byte(c.doc), c.ptr)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.doc, c.err, err)
+		}
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	const doc = `{"a": [1, {"b/c": "d", "~": [true]}], "e": {}, "": null}`
+	cases := []struct {
+		path []string
+		out  string
+		err  error
+	}{
+		{out: doc},
+		{path: []string{"a"}, out: `[1, {"b/c": "d", "~": [true]}]`},
+		{path: []string{"a", "0"}, out: `1`},
+		{path: []string{"a", "1", "b/c"}, out: `"d"`},
+		{path: []string{"a", "1", "~", "0"}, out: `true`},
+		{path: []string{"e"}, out: `{}`},
+		{path: []string{""}, out: `null`},
+		{path: []string{"x"}, err: ErrNotFound},
+		{path: []string{"a", "x"}, err: ErrNotFound},
+		{path: []string{"a", "0", "x"}, err: ErrNotFound},
+		{path: []string{"a", "2"}, err: ErrIndexOutOfRange},
+		{path: []string{"a", "1", "~", "1"}, err: ErrIndexOutOfRange},
+	}
+
+	for i, c := range cases {
+		out, err := GetPath([]byte(doc), c.path...)
+		if err != c.err {
+			t.Errorf("%d (%q): want error %v, got %v", i, c.path, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d (%q): want %s, got %s", i, c.path, c.out, out)
+		}
+	}
+
+	if _, err := GetPath([]byte(`{"a": [1`), "a", "1"); err != io.ErrUnexpectedEOF {
+		t.Errorf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,50 @@
+package jsonb
+
+import (
+	"io"
+	"sync"
+)
+
+// Pool is a pool of parsers that can be reused to parse many documents
+// without allocating a new Parser, along with its internal buffers, for
+// each of them. It is safe for concurrent use.
+type Pool struct {
+	cfg  config // configuration of the parsers returned by Get
+	pool sync.Pool
+}
+
+// NewPool returns a pool of parsers that use the specified chunk size.
+func NewPool(size int64) *Pool {
+	opts := []ParserOption{WithChunkSize(size)}
+	p := &Pool{cfg: newParser(opts).config}
+	p.pool.New = func() interface{} {
+		return newParser(opts)
+	}
+	return p
+}
+
+// Get returns a parser from the pool, reset to read from r. The options
+// passed to Parser.Reset by a previous user of the parser do not apply.
+func (p *Pool) Get(r io.Reader) *Parser {
+	ps := p.pool.Get().(*Parser)
+	ps.config = p.cfg
+	ps.Reset(r)
+	return ps
+}
+
+// Put returns the parser to the pool. It must only be called once the
+// caller is done with the parser, after Parser.Err has been checked, and
+// the parser must not be used after that.
+func (p *Pool) Put(ps *Parser) {
+	// release the references to the input
+	ps.r = nil
+	ps.src = nil
+	ps.closer = nil
+	ps.br.Reset(nil)
+	ps.sr.Reset("")
+	if ps.bufr != nil {
+		ps.bufr.Reset(nil)
+	}
+	ps.ctx = nil
+	p.pool.Put(ps)
+}
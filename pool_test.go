@@ -0,0 +1,74 @@
+package jsonb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	pool := NewPool(64)
+
+	for i, in := range []string{`[1, {"a": true}]`, `{"b": [null]}`, `"c"`} {
+		p := pool.Get(strings.NewReader(in))
+		if p.size != 64 {
+			t.Errorf("%d: want chunk size 64, got %d", i, p.size)
+		}
+
+		var toks []Token
+		for p.Next() {
+			toks = append(toks, p.Token())
+		}
+		if err := p.Err(); err != nil {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+
+		want := tokensOf(in)
+		if !reflect.DeepEqual(want, toks) {
+			t.Errorf("%d: want %v, got %v", i, want, toks)
+		}
+		pool.Put(p)
+	}
+}
+
+func TestPoolResetOptions(t *testing.T) {
+	pool := NewPool(64)
+	p := pool.Get(strings.NewReader(`1`))
+	p.Reset(strings.NewReader(`[1]`), WithMaxDepth(1), WithChunkSize(8))
+	for p.Next() {
+	}
+	pool.Put(p)
+
+	// the options do not carry over to the next user of the parser
+	p = pool.Get(strings.NewReader(`[1,[2]]`))
+	for p.Next() {
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if p.size != 64 {
+		t.Errorf("want chunk size 64, got %d", p.size)
+	}
+	pool.Put(p)
+}
+
+func TestPoolPutReleasesReader(t *testing.T) {
+	pool := NewPool(64)
+	p := pool.Get(&closeCounter{Reader: strings.NewReader(`[1]`)})
+	for p.Next() {
+	}
+	pool.Put(p)
+	if p.r != nil || p.src != nil || p.closer != nil {
+		t.Errorf("want no reference to the reader, got r=%v, src=%v, closer=%v", p.r, p.src, p.closer)
+	}
+}
+
+// tokensOf returns the tokens of in, parsed by a new parser.
+func tokensOf(in string) []Token {
+	var toks []Token
+	p := NewParserString(in)
+	for p.Next() {
+		toks = append(toks, p.Token())
+	}
+	return toks
+}
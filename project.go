@@ -0,0 +1,137 @@
+package jsonb
+
+import "strings"
+
+// Project returns the JSON object data, or each object of the JSON array
+// data, with only the members selected by include and exclude, in compact
+// form. If include is empty, all the members are selected except those in
+// exclude, otherwise only the members in include that are not in exclude
+// are selected.
+//
+// The paths of include and exclude are object keys separated by "/", such
+// as "a/b", to select the members of nested objects. The selection applies
+// to each element of the arrays of the document, so "a/b" selects the "b"
+// member of the objects in the array "a". It returns ErrNotObject if data is
+// neither an object nor an array.
+func Project(data []byte, include, exclude []string) ([]byte, error) {
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	if p.tok != ObjectStart && p.tok != ArrayStart {
+		return nil, ErrNotObject
+	}
+
+	var inc, exc *projection
+	if len(include) > 0 {
+		inc = newProjection(include)
+	}
+	if len(exclude) > 0 {
+		exc = newProjection(exclude)
+	}
+
+	var b Builder
+	if err := project(p, &b, inc, exc); err != nil {
+		return nil, err
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// projection is a tree of the object keys selected by Project.
+type projection struct {
+	leaf  bool // the whole value is selected
+	elems map[string]*projection
+}
+
+// newProjection returns the projection of the "/"-separated paths.
+func newProjection(paths []string) *projection {
+	root := &projection{elems: make(map[string]*projection)}
+	for _, path := range paths {
+		n := root
+		for _, seg := range strings.Split(path, "/") {
+			child := n.elems[seg]
+			if child == nil {
+				child = &projection{elems: make(map[string]*projection)}
+				n.elems[seg] = child
+			}
+			n = child
+		}
+		n.leaf = true
+	}
+	return root
+}
+
+// child returns the projection of the member key of n, or nil. It is nil
+// if n is nil.
+func (n *projection) child(key string) *projection {
+	if n == nil {
+		return nil
+	}
+	return n.elems[key]
+}
+
+// project writes the current value of p to b, keeping the members that are
+// in inc, or all of them if inc is nil, and dropping those in exc.
+func project(p *Parser, b *Builder, inc, exc *projection) error {
+	switch p.tok {
+	case ArrayStart:
+		b.BeginArray()
+		for {
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+			if p.tok == ArrayEnd {
+				break
+			}
+			if err := project(p, b, inc, exc); err != nil {
+				return err
+			}
+		}
+		b.EndArray()
+
+	case ObjectStart:
+		b.BeginObject()
+		for {
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+			if p.tok == ObjectEnd {
+				break
+			}
+			key, err := p.String()
+			if err != nil {
+				return err
+			}
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+
+			ic, ec := inc.child(key), exc.child(key)
+			if inc != nil && ic == nil || ec != nil && ec.leaf {
+				if !p.Skip() {
+					return p.valueErr()
+				}
+				continue
+			}
+			if ic != nil && ic.leaf {
+				// the whole value is included
+				ic = nil
+			}
+			b.Key(key)
+			if err := project(p, b, ic, ec); err != nil {
+				return err
+			}
+		}
+		b.EndObject()
+
+	default:
+		b.rawValue("Raw", p.buf.Bytes())
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	const doc = `{"id": 1, "name": "a", "tags": ["x"], "owner": {"id": 2, "name": "b", "email": "c"}}`
+	cases := []struct {
+		in      string
+		include []string
+		exclude []string
+		out     string
+		err     error
+	}{
+		{in: doc, out: `{"id":1,"name":"a","tags":["x"],"owner":{"id":2,"name":"b","email":"c"}}`},
+		{in: doc, include: []string{"id", "owner"}, out: `{"id":1,"owner":{"id":2,"name":"b","email":"c"}}`},
+		{in: doc, include: []string{"name", "owner/email"}, out: `{"name":"a","owner":{"email":"c"}}`},
+		{in: doc, exclude: []string{"tags", "owner/email", "missing"}, out: `{"id":1,"name":"a","owner":{"id":2,"name":"b"}}`},
+		{in: doc, include: []string{"id", "owner"}, exclude: []string{"id", "owner/id"}, out: `{"owner":{"name":"b","email":"c"}}`},
+		{in: doc, include: []string{"owner/id", "owner/name"}, exclude: []string{"owner/name"}, out: `{"owner":{"id":2}}`},
+		{in: doc, include: []string{"missing"}, out: `{}`},
+		{in: `[{"a": 1, "b": 2}, {"b": 3}, 4, []]`, include: []string{"b"}, out: `[{"b":2},{"b":3},4,[]]`},
+		{in: `{"a": [{"b": 1, "c": 2}, {"c": 3}]}`, exclude: []string{"a/c"}, out: `{"a":[{"b":1},{}]}`},
+		{in: `"a"`, err: ErrNotObject},
+		{in: `{"a": [1}`, err: &SyntaxError{Char: '}', Line: 1, Column: 9, Offset: 8, Near: []byte(`{"a": [1}`), typ: begVal}},
+		{in: `{"a": 1`, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		out, err := Project([]byte(c.in), c.include, c.exclude)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
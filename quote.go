@@ -0,0 +1,328 @@
+package jsonb
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotString is returned when a string value is requested for a token
+	// that is not a String or an ObjectKey.
+	ErrNotString = errors.New("jsonb: token is not a string")
+
+	// ErrInvalidString is returned when decoding bytes that are not a valid
+	// JSON string literal.
+	ErrInvalidString = errors.New("jsonb: invalid string literal")
+)
+
+// String returns the decoded value of the current String or ObjectKey token,
+// with all escape sequences processed.
+func (p *Parser) String() (string, error) {
+	if p.tok != String && p.tok != ObjectKey {
+		return "", ErrNotString
+	}
+	b, err := appendUnquote(nil, p.buf.Bytes())
+	return string(b), err
+}
+
+// UnquoteString decodes the JSON string literal src, including its
+// surrounding double-quotes, processing all escape sequences, and returns
+// the UTF-8 encoded result. It returns ErrInvalidString if src is not a
+// valid string literal.
+func UnquoteString(src []byte) ([]byte, error) {
+	return AppendUnquoted(nil, src)
+}
+
+// AppendUnquoted is like UnquoteString but appends the decoded string to dst
+// and returns the extended slice, like strconv.AppendQuote. The caller can
+// pass dst[:0] to reuse the memory of dst. A string without escape
+// sequences is appended with a single copy. On error, dst is returned
+// unchanged.
+func AppendUnquoted(dst, src []byte) ([]byte, error) {
+	b, err := appendUnquote(dst, src)
+	if err != nil {
+		return dst, err
+	}
+	return b, nil
+}
+
+// AppendDecoded decodes the JSON string literal src, including its
+// surrounding double-quotes, and appends the result to dst. It behaves like
+// AppendUnquoted, and is the counterpart of AppendEncoded.
+func AppendDecoded(dst, src []byte) ([]byte, error) {
+	b, err := appendUnquote(dst, src)
+	if err != nil {
+		return dst, err
+	}
+	return b, nil
+}
+
+// StringEqualFold reports whether the JSON string literals a and b,
+// including their surrounding double-quotes, are equal once decoded under
+// simple Unicode case-folding, as for strings.EqualFold, so that "\u0041"
+// and "a" are equal. It returns ErrInvalidString if a or b is not a valid
+// string literal.
+func StringEqualFold(a, b []byte) (bool, error) {
+	var bufa, bufb [64]byte
+	da, err := appendUnquote(bufa[:0], a)
+	if err != nil {
+		return false, err
+	}
+	db, err := appendUnquote(bufb[:0], b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.EqualFold(da, db), nil
+}
+
+// appendUnquote decodes the JSON string literal src, including its
+// surrounding double-quotes, and appends the result to dst. The runs of
+// bytes without escape sequences are appended with a single copy.
+func appendUnquote(dst, src []byte) ([]byte, error) {
+	if len(src) < 2 || src[0] != '"' || src[len(src)-1] != '"' {
+		return dst, ErrInvalidString
+	}
+	src = src[1 : len(src)-1]
+
+	start := 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\':
+			r, n := unescape(src[i:])
+			if n == 0 {
+				return dst, ErrInvalidString
+			}
+			dst = appendRune(append(dst, src[start:i]...), r)
+			i += n
+			start = i
+
+		case c == '"' || c < 0x20:
+			return dst, ErrInvalidString
+
+		case c < utf8.RuneSelf:
+			i++
+
+		default:
+			r, n := utf8.DecodeRune(src[i:])
+			if r == utf8.RuneError && n == 1 {
+				return dst, ErrInvalidString
+			}
+			i += n
+		}
+	}
+	return append(dst, src[start:]...), nil
+}
+
+// unescape decodes the escape sequence at the start of b and returns the
+// rune and the number of bytes consumed, or 0 if the sequence is invalid.
+// A \u escape of a high surrogate immediately followed by a \u escape of a
+// low surrogate is decoded as a single rune, while unpaired surrogates are
+// decoded as the Unicode replacement character.
+func unescape(b []byte) (rune, int) {
+	if len(b) < 2 {
+		return 0, 0
+	}
+	switch b[1] {
+	case '"', '\\', '/':
+		return rune(b[1]), 2
+	case 'b':
+		return '\b', 2
+	case 'f':
+		return '\f', 2
+	case 'n':
+		return '\n', 2
+	case 'r':
+		return '\r', 2
+	case 't':
+		return '\t', 2
+	case 'u':
+		r, ok := hex4(b[2:])
+		if !ok {
+			return 0, 0
+		}
+		if utf16.IsSurrogate(r) {
+			if len(b) >= 12 && b[6] == '\\' && b[7] == 'u' {
+				if r2, ok := hex4(b[8:]); ok {
+					if c := utf16.DecodeRune(r, r2); c != utf8.RuneError {
+						return c, 12
+					}
+				}
+			}
+			return utf8.RuneError, 6
+		}
+		return r, 6
+	}
+	return 0, 0
+}
+
+// hex4 decodes the four hexadecimal digits at the start of b.
+func hex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// appendRune appends the UTF-8 encoding of r to dst.
+func appendRune(dst []byte, r rune) []byte {
+	if r < utf8.RuneSelf {
+		return append(dst, byte(r))
+	}
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	return append(dst, b[:n]...)
+}
+
+// QuoteFlag is a flag that changes how QuoteString and AppendQuoted encode
+// a string.
+type QuoteFlag int
+
+const (
+	// EscapeNonASCII escapes the characters above U+007E as \uXXXX escape
+	// sequences, with a surrogate pair for characters outside the Basic
+	// Multilingual Plane, so that the result is printable ASCII.
+	EscapeNonASCII QuoteFlag = 1 << iota
+
+	// EscapeHTML escapes the characters <, > and & as \u003c, \u003e and
+	// \u0026, so that the string can be embedded in HTML, and the line and
+	// paragraph separators U+2028 and U+2029, which are invalid in
+	// JavaScript strings, like encoding/json does by default.
+	EscapeHTML
+)
+
+// QuoteString returns the JSON string literal of s, including its
+// surrounding double-quotes. The double-quote, the backslash and the
+// control characters U+0000 to U+001F are escaped, using the short escape
+// sequences when they exist. Invalid UTF-8 bytes are replaced by \ufffd,
+// so that the result is always valid JSON.
+func QuoteString(s string, flags ...QuoteFlag) []byte {
+	return AppendQuoted(nil, s, flags...)
+}
+
+// AppendQuoted is like QuoteString but appends the string literal to dst
+// and returns the extended slice.
+func AppendQuoted(dst []byte, s string, flags ...QuoteFlag) []byte {
+	var f QuoteFlag
+	for _, flag := range flags {
+		f |= flag
+	}
+	return appendQuoteFlags(dst, s, f)
+}
+
+// AppendEncoded appends the JSON string literal of s, including its
+// surrounding double-quotes, to dst and returns the extended slice. Only
+// the double-quote, the backslash and the control characters are escaped,
+// and the runs of characters that need no escaping are appended with a
+// single copy.
+func AppendEncoded(dst []byte, s string) []byte {
+	return appendQuoteFlags(dst, s, 0)
+}
+
+// AppendEncodedHTML is like AppendEncoded, but it also escapes the
+// characters described by EscapeHTML.
+func AppendEncodedHTML(dst []byte, s string) []byte {
+	return appendQuoteFlags(dst, s, EscapeHTML)
+}
+
+// appendQuote appends the JSON string literal of s, including its
+// surrounding double-quotes, to dst. Only the double-quote, the backslash
+// and the control characters are escaped.
+func appendQuote(dst []byte, s string) []byte {
+	return appendQuoteFlags(dst, s, 0)
+}
+
+// appendQuoteFlags is appendQuote with the flags of AppendQuoted.
+func appendQuoteFlags(dst []byte, s string, flags QuoteFlag) []byte {
+	ascii := flags&EscapeNonASCII != 0
+	html := flags&EscapeHTML != 0
+
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && n == 1 {
+				// invalid UTF-8, replaced like encoding/json does
+				dst = append(dst, s[start:i]...)
+				dst = append(dst, `\ufffd`...)
+				start = i + 1
+				continue
+			}
+			if !ascii && (!html || !isHTMLEscaped(s, i)) {
+				i += n - 1
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				dst = appendEscape(dst, r1)
+				r = r2
+			}
+			dst = appendEscape(dst, r)
+			i += n - 1
+			start = i + 1
+			continue
+		}
+
+		if c >= 0x20 && c != '"' && c != '\\' && (c < 0x7f || !ascii) && (!html || !isHTMLEscaped(s, i)) {
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = appendEscape(dst, rune(c))
+		}
+		start = i + 1
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// isHTMLEscaped returns true if the character at index i of s is escaped
+// with the EscapeHTML flag.
+func isHTMLEscaped(s string, i int) bool {
+	switch c := s[i]; c {
+	case '<', '>', '&':
+		return true
+	case 0xe2:
+		// U+2028 and U+2029 are encoded as e2 80 a8 and e2 80 a9
+		return i+2 < len(s) && s[i+1] == 0x80 && s[i+2]&^1 == 0xa8
+	}
+	return false
+}
+
+// appendEscape appends the \uXXXX escape sequence of the UTF-16 code unit r
+// to dst.
+func appendEscape(dst []byte, r rune) []byte {
+	const hex = "0123456789abcdef"
+	return append(dst, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+}
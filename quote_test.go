@@ -0,0 +1,218 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{in: `""`, out: ""},
+		{in: `"abc"`, out: "abc"},
+		{in: `"a b\tc"`, out: "a b\tc"},
+		{in: `"\"\\\/\b\f\n\r\t"`, out: "\"\\/\b\f\n\r\t"},
+		{in: `"Aé中"`, out: "Aé中"},
+		{in: `"héllo, 世界"`, out: "héllo, 世界"},
+		{in: `"\ud83d\ude00"`, out: "😀"},
+		{in: `"𝄞!"`, out: "𝄞!"},
+		{in: `"\ud83d"`, out: "�"},
+		{in: `"\ude00\ud83d"`, out: "��"},
+		{in: `"\ud83dx"`, out: "�x"},
+		{in: `1`, err: ErrNotString},
+		{in: `null`, err: ErrNotString},
+		{in: `[]`, err: ErrNotString},
+		{in: `{"k\u00e9y": 1}`, out: "kéy"},
+	}
+
+	p := NewParser(nil)
+	for i, c := range cases {
+		p.Reset(strings.NewReader(c.in))
+		if p.Next() && p.Token() == ObjectStart {
+			p.Next()
+		}
+		if p.Err() != nil {
+			t.Errorf("%d (%s): unexpected error %v", i, c.in, p.Err())
+			continue
+		}
+		got, err := p.String()
+		if err != c.err {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.in, c.err, err)
+			continue
+		}
+		if got != c.out {
+			t.Errorf("%d (%s): want %q, got %q", i, c.in, c.out, got)
+		}
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{in: `""`, out: ""},
+		{in: `"a b\tc"`, out: "a b\tc"},
+		{in: `"\"\\\/\b\f\n\r\t"`, out: "\"\\/\b\f\n\r\t"},
+		{in: `"h\u00e9llo, 世界"`, out: "héllo, 世界"},
+		{in: `"\ud83d\ude00"`, out: "😀"},
+		{in: `"\ud83d"`, out: "\ufffd"},
+		{in: `"a\nb\u00e9c, 世界\t"`, out: "a\nb\u00e9c, 世界\t"},
+		{in: `abc`, err: ErrInvalidString},
+		{in: `"`, err: ErrInvalidString},
+		{in: `"a`, err: ErrInvalidString},
+		{in: `"a"b"`, err: ErrInvalidString},
+		{in: `"\x"`, err: ErrInvalidString},
+		{in: `"\u12"`, err: ErrInvalidString},
+		{in: "\"a\nb\"", err: ErrInvalidString},
+		{in: "\"\xff\"", err: ErrInvalidString},
+	}
+
+	for i, c := range cases {
+		got, err := UnquoteString([]byte(c.in))
+		if err != c.err {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.in, c.err, err)
+		}
+		if string(got) != c.out {
+			t.Errorf("%d (%s): want %q, got %q", i, c.in, c.out, got)
+		}
+
+		dst := []byte("prefix:")
+		got, err = AppendUnquoted(dst, []byte(c.in))
+		want := "prefix:" + c.out
+		if err != nil {
+			want = "prefix:"
+		}
+		if err != c.err || string(got) != want {
+			t.Errorf("%d (%s): AppendUnquoted: want %q (%v), got %q (%v)", i, c.in, want, c.err, got, err)
+		}
+		got, err = AppendDecoded(dst, []byte(c.in))
+		if err != c.err || string(got) != want {
+			t.Errorf("%d (%s): AppendDecoded: want %q (%v), got %q (%v)", i, c.in, want, c.err, got, err)
+		}
+	}
+
+	dst := make([]byte, 0, 16)
+	for _, src := range [][]byte{[]byte(`"a\tb"`), []byte(`"abc"`)} {
+		if n := testing.AllocsPerRun(10, func() { AppendUnquoted(dst, src) }); n != 0 {
+			t.Errorf("%s: want no allocation, got %f", src, n)
+		}
+		if n := testing.AllocsPerRun(10, func() { AppendDecoded(dst[:0], src) }); n != 0 {
+			t.Errorf("%s: AppendDecoded: want no allocation, got %f", src, n)
+		}
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	cases := []struct {
+		in    string
+		flags []QuoteFlag
+		out   string
+	}{
+		{in: "", out: `""`},
+		{in: "abc", out: `"abc"`},
+		{in: "\"\\/\b\f\n\r\t", out: `"\"\\/\b\f\n\r\t"`},
+		{in: "\x00\x1f\x7f", out: "\"\\u0000\\u001f\x7f\""},
+		{in: "héllo, 世界 😀", out: `"héllo, 世界 😀"`},
+		{in: "héllo, 世界 😀", flags: []QuoteFlag{EscapeNonASCII}, out: `"h\u00e9llo, \u4e16\u754c \ud83d\ude00"`},
+		{in: "a\x7f\xffb\n", flags: []QuoteFlag{EscapeNonASCII}, out: `"a\u007f\ufffdb\n"`},
+		{in: "\xff", out: `"\ufffd"`},
+		{in: "a\xe2\x80b\ufffd", out: "\"a\\ufffd\\ufffdb\ufffd\""},
+		{in: "<\xff", flags: []QuoteFlag{EscapeHTML}, out: `"\u003c\ufffd"`},
+		{in: "<a href=\"x?a=1&b=2\">\u2028\u2029</a>", flags: []QuoteFlag{EscapeHTML}, out: `"\u003ca href=\"x?a=1\u0026b=2\"\u003e\u2028\u2029\u003c/a\u003e"`},
+		{in: "<\u00e9\u2028\xe2\x80", flags: []QuoteFlag{EscapeHTML, EscapeNonASCII}, out: `"\u003c\u00e9\u2028\ufffd\ufffd"`},
+	}
+
+	for i, c := range cases {
+		got := QuoteString(c.in, c.flags...)
+		if string(got) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, got)
+		}
+		if got := AppendQuoted([]byte("prefix:"), c.in, c.flags...); string(got) != "prefix:"+c.out {
+			t.Errorf("%d: AppendQuoted: want prefix:%s, got %s", i, c.out, got)
+		}
+
+		// the result is valid JSON, and decodes to the original string if
+		// it is valid UTF-8
+		if err := ValidateBytes(got); err != nil {
+			t.Errorf("%d: invalid JSON %s: %v", i, got, err)
+		}
+		if u, err := UnquoteString(got); err != nil || string(u) != c.in && utf8.ValidString(c.in) {
+			t.Errorf("%d: want round-trip to %q, got %q (%v)", i, c.in, u, err)
+		}
+	}
+
+	dst := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(10, func() { AppendQuoted(dst, "a\tb\u00e9", EscapeNonASCII) }); n != 0 {
+		t.Errorf("want no allocation, got %f", n)
+	}
+}
+
+func TestStringEqualFold(t *testing.T) {
+	cases := []struct {
+		a, b string
+		eq   bool
+		err  error
+	}{
+		{a: `""`, b: `""`, eq: true},
+		{a: `"abc"`, b: `"ABC"`, eq: true},
+		{a: `"\u0041"`, b: `"a"`, eq: true},
+		{a: `"Content-Type"`, b: `"content-type"`, eq: true},
+		{a: `"\u00c9t\u00e9"`, b: `"\u00e9T\u00c9"`, eq: true},
+		{a: `"\u212a"`, b: `"k"`, eq: true},
+		{a: `"abc"`, b: `"abd"`},
+		{a: `"a\n"`, b: `"a"`},
+		{a: `"abc`, b: `"abc"`, err: ErrInvalidString},
+		{a: `"abc"`, b: `abc`, err: ErrInvalidString},
+	}
+
+	for i, c := range cases {
+		eq, err := StringEqualFold([]byte(c.a), []byte(c.b))
+		if err != c.err {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if eq != c.eq {
+			t.Errorf("%d: want %t, got %t", i, c.eq, eq)
+		}
+	}
+}
+
+func TestAppendEncoded(t *testing.T) {
+	cases := []string{
+		"",
+		"abc",
+		"a\tb\"c\\d\x00\x1f\x7f",
+		"h\u00e9llo, \u4e16\u754c \U0001f600",
+		"<script>a && b</script>",
+		"\u2028\u2029\u2027\xe2\x80",
+	}
+
+	for i, s := range cases {
+		if got, want := AppendEncoded([]byte("prefix:"), s), AppendQuoted([]byte("prefix:"), s); string(got) != string(want) {
+			t.Errorf("%d: AppendEncoded: want %s, got %s", i, want, got)
+		}
+
+		// the HTML variant matches the default of encoding/json
+		want, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.ValidString(s) {
+			continue
+		}
+		if got := AppendEncodedHTML(nil, s); string(got) != string(want) {
+			t.Errorf("%d: AppendEncodedHTML: want %s, got %s", i, want, got)
+		}
+	}
+
+	dst := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(10, func() { AppendEncodedHTML(dst[:0], "a<b>\u2028") }); n != 0 {
+		t.Errorf("want no allocation, got %f", n)
+	}
+}
@@ -0,0 +1,174 @@
+package jsonb
+
+import "errors"
+
+var (
+	// ErrNotObject is returned by ReadObject when the value is not an
+	// object.
+	ErrNotObject = errors.New("jsonb: value is not an object")
+
+	// ErrNotArray is returned by ReadArray when the value is not an array.
+	ErrNotArray = errors.New("jsonb: value is not an array")
+)
+
+// ReadObject reads an object from p and calls fn once for each of its
+// members, with the decoded key. The key is only valid for the duration of
+// the call. If p is not positioned on an ObjectStart token, it is first
+// advanced to the next token, which must be an ObjectStart.
+//
+// When fn is called, p is positioned on the ObjectKey token, and fn may call
+// p.Next to read the value of the member. Any part of the value that fn does
+// not read is skipped once it returns, but fn must not read past the value.
+// ReadObject returns when the ObjectEnd token is reached, with the first
+// error returned by fn or by p.
+func ReadObject(p *Parser, fn func(key []byte) error) error {
+	if err := p.startValue(ObjectStart); err != nil {
+		return err
+	}
+
+	depth := len(p.stack)
+	var key []byte
+	for {
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+		if p.tok == ObjectEnd {
+			return nil
+		}
+
+		var err error
+		if key, err = appendUnquote(key[:0], p.buf.Bytes()); err != nil {
+			return err
+		}
+		n := p.ntok
+		if err := fn(key); err != nil {
+			return err
+		}
+		if p.ntok == n {
+			// fn did not read the value
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+		}
+		if err := p.skipTo(depth); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadArray reads an array from p and calls fn once for each of its
+// elements, with the index of the element. If p is not positioned on an
+// ArrayStart token, it is first advanced to the next token, which must be an
+// ArrayStart.
+//
+// When fn is called, p is positioned on the first token of the element. Any
+// part of the element that fn does not read is skipped once it returns, but
+// fn must not read past the element. ReadArray returns when the ArrayEnd
+// token is reached, with the first error returned by fn or by p.
+func ReadArray(p *Parser, fn func(index int) error) error {
+	if err := p.startValue(ArrayStart); err != nil {
+		return err
+	}
+
+	depth := len(p.stack)
+	for i := 0; ; i++ {
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+		if p.tok == ArrayEnd {
+			return nil
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+		if err := p.skipTo(depth); err != nil {
+			return err
+		}
+	}
+}
+
+// startValue advances p to the next token unless it is positioned on the
+// start token tok, and checks that the current token is tok.
+func (p *Parser) startValue(tok Token) error {
+	if p.tok != tok {
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.tok == tok:
+		return nil
+	case tok == ObjectStart:
+		return ErrNotObject
+	default:
+		return ErrNotArray
+	}
+}
+
+// skipTo advances p until its depth is back to depth, completing the value
+// that was partially read.
+func (p *Parser) skipTo(depth int) error {
+	for len(p.stack) > depth {
+		if err := p.nextToken(); err != nil {
+			return err
+		}
+	}
+	if p.tok == Invalid {
+		return p.valueErr()
+	}
+	return nil
+}
+
+// ReadRaw reads the current value of p and returns its raw bytes. If the
+// current token is an ArrayStart or an ObjectStart, p is advanced up to and
+// including the matching end token and the bytes of the whole array or
+// object are returned, without insignificant whitespace but otherwise
+// verbatim. If the current token is an ObjectKey, p is first advanced to
+// the value of that key. The returned slice is not invalidated by the next
+// call to Next.
+func ReadRaw(p *Parser) ([]byte, error) {
+	if p.tok == ObjectKey {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok == Invalid || p.tok.IsEnd() {
+		return nil, p.valueErr()
+	}
+	b, err := p.appendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NextKeyValue reads the next member of the object of p, which must be
+// positioned inside the object, for example after its ObjectStart token or
+// after the previous call to NextKeyValue. It returns the decoded key and
+// the raw bytes of the value, as returned by ReadRaw, which are not
+// invalidated by the next call to Next. When the ObjectEnd token is
+// reached, it returns nil for the key and value and a nil error. It returns
+// ErrNotObject if the next token is neither an ObjectKey nor an ObjectEnd.
+func NextKeyValue(p *Parser) (key, value []byte, err error) {
+	if err := p.nextToken(); err != nil {
+		return nil, nil, err
+	}
+	switch p.tok {
+	case ObjectEnd:
+		return nil, nil, nil
+	case ObjectKey:
+	default:
+		return nil, nil, ErrNotObject
+	}
+
+	if key, err = appendUnquote(nil, p.buf.Bytes()); err != nil {
+		return nil, nil, err
+	}
+	if err := p.nextToken(); err != nil {
+		return nil, nil, err
+	}
+	if value, err = ReadRaw(p); err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
@@ -0,0 +1,287 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadObject(t *testing.T) {
+	errStop := errors.New("stop")
+
+	cases := []struct {
+		in   string
+		fn   func(p *Parser, key string) error
+		keys []string
+		err  error
+	}{
+		{in: `{}`},
+		{in: ` {"a": 1, "b!": [2, {"c": 3}], "d": {"e": []}} `, keys: []string{"a", "b!", "d"}},
+		// the callback reads the value, in full or in part
+		{in: `{"a": 1, "b": [2, {"c": 3}], "d": {"e": []}}`, keys: []string{"a", "b", "d"},
+			fn: func(p *Parser, key string) error {
+				p.Next()
+				return nil
+			}},
+		{in: `{"a": 1, "b": [2, {"c": 3}], "d": {"e": []}}`, keys: []string{"a", "b", "d"},
+			fn: func(p *Parser, key string) error {
+				p.Next()
+				p.Skip()
+				return nil
+			}},
+		{in: `{"a": 1, "b": [2, {"c": 3}], "d": {"e": []}}`, keys: []string{"a", "b", "d"},
+			fn: func(p *Parser, key string) error {
+				if key == "b" {
+					p.Next() // [
+					p.Next() // 2
+					p.Next() // {
+				}
+				return nil
+			}},
+		{in: `{"a": 1, "b": 2}`, keys: []string{"a"}, err: errStop,
+			fn: func(p *Parser, key string) error {
+				return errStop
+			}},
+		{in: `[]`, err: ErrNotObject},
+		{in: ``, err: io.ErrUnexpectedEOF},
+		{in: `{"a": 1`, keys: []string{"a"}, err: io.ErrUnexpectedEOF},
+		{in: `{"a": [1 2]}`, keys: []string{"a"}, err: ErrSyntax},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.in))
+		var keys []string
+		err := ReadObject(p, func(key []byte) error {
+			keys = append(keys, string(key))
+			if c.fn != nil {
+				return c.fn(p, string(key))
+			}
+			return nil
+		})
+		if !errors.Is(err, c.err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if !reflect.DeepEqual(c.keys, keys) {
+			t.Errorf("%d: want keys %v, got %v", i, c.keys, keys)
+		}
+		if c.err == nil && (p.Next() || p.Err() != nil) {
+			t.Errorf("%d: want end of input, got %s (%v)", i, p.Token(), p.Err())
+		}
+	}
+}
+
+func TestReadObjectNested(t *testing.T) {
+	p := NewParser(strings.NewReader(`{"a": {"b": 1, "c": {"d": true}}, "e": {}}`))
+
+	var got []string
+	var read func(prefix string) error
+	read = func(prefix string) error {
+		return ReadObject(p, func(key []byte) error {
+			path := prefix + "/" + string(key)
+			got = append(got, path)
+			if !p.Next() {
+				return p.Err()
+			}
+			if p.Token() == ObjectStart {
+				return read(path)
+			}
+			return nil
+		})
+	}
+	if err := read(""); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/a", "/a/b", "/a/c", "/a/c/d", "/e"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReadArray(t *testing.T) {
+	errStop := errors.New("stop")
+
+	cases := []struct {
+		in   string
+		fn   func(p *Parser, i int) error
+		toks []Token
+		err  error
+	}{
+		{in: `[]`},
+		{in: ` [1, "a", [2, [3]], {"b": 4}, null] `, toks: []Token{Number, String, ArrayStart, ObjectStart, Null}},
+		// the callback reads the element, in full or in part
+		{in: `[1, [2, [3]], {"b": 4}]`, toks: []Token{Number, ArrayStart, ObjectStart},
+			fn: func(p *Parser, i int) error {
+				p.Skip()
+				return nil
+			}},
+		{in: `[1, [2, [3]], {"b": 4}]`, toks: []Token{Number, ArrayStart, ObjectStart},
+			fn: func(p *Parser, i int) error {
+				if i == 1 {
+					p.Next() // 2
+					p.Next() // [
+				}
+				return nil
+			}},
+		{in: `[1, 2]`, toks: []Token{Number}, err: errStop,
+			fn: func(p *Parser, i int) error {
+				return errStop
+			}},
+		{in: `{}`, err: ErrNotArray},
+		{in: `[1`, toks: []Token{Number}, err: io.ErrUnexpectedEOF},
+		{in: `[1, {"a" 1}]`, toks: []Token{Number, ObjectStart}, err: ErrSyntax},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.in))
+		var toks []Token
+		err := ReadArray(p, func(j int) error {
+			if j != len(toks) {
+				t.Errorf("%d: want index %d, got %d", i, len(toks), j)
+			}
+			toks = append(toks, p.Token())
+			if c.fn != nil {
+				return c.fn(p, j)
+			}
+			return nil
+		})
+		if !errors.Is(err, c.err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if !reflect.DeepEqual(c.toks, toks) {
+			t.Errorf("%d: want tokens %v, got %v", i, c.toks, toks)
+		}
+		if c.err == nil && (p.Next() || p.Err() != nil) {
+			t.Errorf("%d: want end of input, got %s (%v)", i, p.Token(), p.Err())
+		}
+	}
+}
+
+func TestReadArrayObject(t *testing.T) {
+	p := NewParser(strings.NewReader(`[{"id": 1, "tags": ["a", "b"]}, {"tags": [], "id": 2}]`))
+
+	type item struct {
+		id   int64
+		tags []string
+	}
+	var got []item
+	err := ReadArray(p, func(int) error {
+		var it item
+		err := ReadObject(p, func(key []byte) error {
+			switch string(key) {
+			case "id":
+				p.Next()
+				n, err := p.Int64()
+				it.id = n
+				return err
+			case "tags":
+				return ReadArray(p, func(int) error {
+					s, err := p.String()
+					it.tags = append(it.tags, s)
+					return err
+				})
+			}
+			return nil
+		})
+		got = append(got, it)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []item{{id: 1, tags: []string{"a", "b"}}, {id: 2}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReadRaw(t *testing.T) {
+	cases := []struct {
+		in   string
+		next int // number of calls to Next before ReadRaw
+		out  string
+		err  error
+	}{
+		{in: `1`, next: 1, out: `1`},
+		{in: `"a\n"`, next: 1, out: `"a\n"`},
+		{in: ` [ 1 , { "a" : [ ] , "b\"" : null } ] `, next: 1, out: `[1,{"a":[],"b\"":null}]`},
+		{in: `[1, {"a": [], "b": null}]`, next: 3, out: `{"a":[],"b":null}`},
+		{in: `{"a": {"b": [true]}, "c": 1}`, next: 2, out: `{"b":[true]}`},
+		{in: `{"a": [1, 2`, next: 1, err: io.ErrUnexpectedEOF},
+		{in: `[1, 2]`, next: 4, err: io.ErrUnexpectedEOF},
+		{in: `[1, 2]`, next: 0, err: io.ErrUnexpectedEOF},
+		{in: `[1, 2 3]`, next: 1, err: ErrSyntax},
+	}
+
+	for i, c := range cases {
+		p := NewParser(strings.NewReader(c.in))
+		for j := 0; j < c.next; j++ {
+			p.Next()
+		}
+		out, err := ReadRaw(p)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+
+	// capture the values of some keys
+	p := NewParser(strings.NewReader(`{"a": [1, 2], "b": "x", "c": {"d": null}}`))
+	got := make(map[string]string)
+	err := ReadObject(p, func(key []byte) error {
+		if string(key) == "b" {
+			return nil
+		}
+		raw, err := ReadRaw(p)
+		got[string(key)] = string(raw)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": `[1,2]`, "c": `{"d":null}`}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestNextKeyValue(t *testing.T) {
+	cases := []struct {
+		in     string
+		keys   []string
+		values []string
+		err    error
+	}{
+		{in: `{}`},
+		{in: `{"a": 1, "b!": [2, {"c": 3}], "d": {"e": []}}`, keys: []string{"a", "b!", "d"}, values: []string{`1`, `[2,{"c":3}]`, `{"e":[]}`}},
+		{in: `{"a": 1, "b": }`, keys: []string{"a"}, values: []string{`1`},
+			err: &SyntaxError{Char: '}', Line: 1, Column: 15, Offset: 14, Near: []byte(`{"a": 1, "b": }`), typ: begVal}},
+		{in: `{"a": [1`, err: io.ErrUnexpectedEOF},
+		{in: `[1]`, err: ErrNotObject},
+	}
+
+	for i, c := range cases {
+		p := NewParserString(c.in)
+		p.Next()
+
+		var keys, values []string
+		var err error
+		for {
+			var k, v []byte
+			if k, v, err = NextKeyValue(p); err != nil || k == nil {
+				break
+			}
+			keys = append(keys, string(k))
+			values = append(values, string(v))
+		}
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if !reflect.DeepEqual(c.keys, keys) || !reflect.DeepEqual(c.values, values) {
+			t.Errorf("%d: want %q %q, got %q %q", i, c.keys, c.values, keys, values)
+		}
+	}
+}
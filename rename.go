@@ -0,0 +1,125 @@
+package jsonb
+
+import "strings"
+
+// Rename returns the JSON object data, or each object of the JSON array
+// data, with its keys renamed as specified by mapping, in compact form.
+// The keys of mapping are the original keys, and its values the new ones.
+// The keys of nested objects are renamed with "/"-separated paths of
+// original keys, so "a/b" renames the "b" member of the object "a". As for
+// Project, the renaming applies to each element of the arrays of the
+// document. The keys that are not in mapping and the values are unchanged.
+//
+// It returns ErrNotObject if data is neither an object nor an array, and a
+// *DuplicateKeyError if two different keys of an object would have the
+// same name once renamed.
+func Rename(data []byte, mapping map[string]string) ([]byte, error) {
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	if p.tok != ObjectStart && p.tok != ArrayStart {
+		return nil, ErrNotObject
+	}
+
+	root := &renaming{elems: make(map[string]*renaming)}
+	for path, name := range mapping {
+		n := root
+		for _, seg := range strings.Split(path, "/") {
+			child := n.elems[seg]
+			if child == nil {
+				child = &renaming{elems: make(map[string]*renaming)}
+				n.elems[seg] = child
+			}
+			n = child
+		}
+		n.name = name
+		n.renamed = true
+	}
+
+	var b Builder
+	if err := rename(p, &b, root); err != nil {
+		return nil, err
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// renaming is a tree of the object keys renamed by Rename.
+type renaming struct {
+	name    string // the new name of the key
+	renamed bool   // the key is renamed
+	elems   map[string]*renaming
+}
+
+// rename writes the current value of p to b, renaming the keys of its
+// objects as specified by n, which may be nil.
+func rename(p *Parser, b *Builder, n *renaming) error {
+	switch {
+	case n == nil || len(n.elems) == 0 || !p.tok.IsStart():
+		// nothing to rename, the value is copied
+		raw, err := p.appendValue(nil)
+		if err != nil {
+			return err
+		}
+		b.rawValue("Raw", raw)
+
+	case p.tok == ArrayStart:
+		b.BeginArray()
+		for {
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+			if p.tok == ArrayEnd {
+				break
+			}
+			if err := rename(p, b, n); err != nil {
+				return err
+			}
+		}
+		b.EndArray()
+
+	default:
+		// original key of each new key, to detect conflicts
+		seen := make(map[string]string)
+		b.BeginObject()
+		for {
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+			if p.tok == ObjectEnd {
+				break
+			}
+			key, err := p.String()
+			if err != nil {
+				return err
+			}
+			offset := p.start
+
+			child := n.elems[key]
+			name := key
+			if child != nil && child.renamed {
+				name = child.name
+			}
+			if orig, ok := seen[name]; ok && orig != key {
+				return &DuplicateKeyError{Key: name, Offset: offset}
+			}
+			seen[name] = key
+
+			if err := p.nextToken(); err != nil {
+				return err
+			}
+			b.Key(name)
+			if err := rename(p, b, child); err != nil {
+				return err
+			}
+		}
+		b.EndObject()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package jsonb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	cases := []struct {
+		in      string
+		mapping map[string]string
+		out     string
+		err     error
+	}{
+		{in: `{"a": 1, "b": [ 2 ]}`, out: `{"a":1,"b":[2]}`},
+		{in: `{"userId": 1, "userName": "a"}`, mapping: map[string]string{"userId": "user_id", "userName": "user_name"}, out: `{"user_id":1,"user_name":"a"}`},
+		{in: `{"a": {"a": 1, "b": 2}, "b": {"a": 3}}`, mapping: map[string]string{"a/a": "x", "b": "y"}, out: `{"a":{"x":1,"b":2},"y":{"a":3}}`},
+		{in: `{"a": {"b": 1}}`, mapping: map[string]string{"a": "c", "a/b": "d"}, out: `{"c":{"d":1}}`},
+		{in: `[{"a": 1}, {"b": 2}, 3]`, mapping: map[string]string{"a": "b"}, out: `[{"b":1},{"b":2},3]`},
+		{in: `{"a": [{"b": 1}, {"c": 2}]}`, mapping: map[string]string{"a/b": "c"}, out: `{"a":[{"c":1},{"c":2}]}`},
+		{in: `{"a": 1, "b": 2}`, mapping: map[string]string{"a": "b", "b": "a"}, out: `{"b":1,"a":2}`},
+		{in: `{"a": 1, "a": 2}`, mapping: map[string]string{"a": "b"}, out: `{"b":1,"b":2}`},
+		{in: `{"a": 1, "b": 2}`, mapping: map[string]string{"a": "b"}, err: &DuplicateKeyError{Key: "b", Offset: 9}},
+		{in: `{"a": 1, "b": 2}`, mapping: map[string]string{"a": "c", "b": "c"}, err: &DuplicateKeyError{Key: "c", Offset: 9}},
+		{in: `1`, mapping: map[string]string{"a": "b"}, err: ErrNotObject},
+	}
+
+	for i, c := range cases {
+		out, err := Rename([]byte(c.in), c.mapping)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotBool is returned when a boolean value is requested for a token that
+// is not True or False.
+var ErrNotBool = errors.New("jsonb: token is not a boolean")
+
+// Scanner is a convenience wrapper around a Parser, similar to a
+// bufio.Scanner. The typed accessors return the zero value when the current
+// token cannot be converted, and record the error, which is then returned
+// by Err and stops the scanning.
+type Scanner struct {
+	*Parser
+	err error
+}
+
+// NewScanner returns a scanner that reads from r, with a parser configured
+// with the provided options.
+func NewScanner(r io.Reader, opts ...ParserOption) *Scanner {
+	return &Scanner{Parser: NewParserOptions(r, opts...)}
+}
+
+// Scan advances the scanner to the next token. It returns false when the
+// scan stops, either by reaching the end of the input or an error.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	return s.Next()
+}
+
+// Err returns the first error recorded by an accessor, or else the error
+// of the parser, if any.
+func (s *Scanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Parser.Err()
+}
+
+// String returns the decoded value of the current String or ObjectKey
+// token.
+func (s *Scanner) String() string {
+	v, err := s.Parser.String()
+	s.fail(err)
+	return v
+}
+
+// Int64 returns the value of the current Number token as an int64.
+func (s *Scanner) Int64() int64 {
+	v, err := s.Parser.Int64()
+	s.fail(err)
+	return v
+}
+
+// Float64 returns the value of the current Number token as a float64.
+func (s *Scanner) Float64() float64 {
+	v, err := s.Parser.Float64()
+	s.fail(err)
+	return v
+}
+
+// Bool returns the value of the current True or False token.
+func (s *Scanner) Bool() bool {
+	switch s.tok {
+	case True:
+		return true
+	case False:
+		return false
+	}
+	s.fail(ErrNotBool)
+	return false
+}
+
+// IsNull returns true if the current token is Null.
+func (s *Scanner) IsNull() bool {
+	return s.tok == Null
+}
+
+// Key returns the decoded object key when the current token is that key or
+// the value that immediately follows it, as for Parser.Key. It returns an
+// empty string otherwise.
+func (s *Scanner) Key() string {
+	k := s.Parser.Key()
+	if k == nil {
+		return ""
+	}
+	b, err := appendUnquote(nil, k)
+	s.fail(err)
+	return string(b)
+}
+
+// fail records err if it is the first error of an accessor.
+func (s *Scanner) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
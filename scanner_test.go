@@ -0,0 +1,77 @@
+package jsonb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	s := NewScanner(strings.NewReader(`{"ab": "c\n", "d": 12, "e": 1.5, "f": true, "g": null, "h": [false]}`))
+
+	var got []interface{}
+	for s.Scan() {
+		switch s.Token() {
+		case ObjectKey:
+			got = append(got, s.Key())
+		case String:
+			got = append(got, s.String())
+		case Number:
+			if s.NumberKind() == NumberInt {
+				got = append(got, s.Int64())
+			} else {
+				got = append(got, s.Float64())
+			}
+		case True, False:
+			got = append(got, s.Bool())
+		case Null:
+			got = append(got, s.IsNull())
+		default:
+			got = append(got, string(s.Bytes()))
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{"{", "ab", "c\n", "d", int64(12), "e", 1.5, "f", true, "g", true, "h", "[", false, "]", "}"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestScannerErr(t *testing.T) {
+	cases := []struct {
+		in  string
+		fn  func(*Scanner)
+		err error
+	}{
+		{in: `[1, 2]`, fn: func(s *Scanner) { _ = s.String() }, err: ErrNotString},
+		{in: `["a", 2]`, fn: func(s *Scanner) { _ = s.Int64() }, err: ErrNotNumber},
+		{in: `[1.5, 2]`, fn: func(s *Scanner) { _ = s.Int64() }, err: ErrNotInteger},
+		{in: `[null, 2]`, fn: func(s *Scanner) { _ = s.Float64() }, err: ErrNotNumber},
+		{in: `[1, 2]`, fn: func(s *Scanner) { _ = s.Bool() }, err: ErrNotBool},
+		{in: `[1, 2]`, fn: func(s *Scanner) { _ = s.IsNull() }},
+	}
+
+	for i, c := range cases {
+		s := NewScanner(strings.NewReader(c.in))
+		var n int
+		for s.Scan() {
+			if s.Token() != ArrayStart {
+				c.fn(s)
+			}
+			n++
+		}
+		if s.Err() != c.err {
+			t.Errorf("%d: want error %v, got %v", i, c.err, s.Err())
+		}
+		// the scan stops on the first error
+		want := 4
+		if c.err != nil {
+			want = 2
+		}
+		if n != want {
+			t.Errorf("%d: got %d tokens", i, n)
+		}
+	}
+}
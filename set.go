@@ -0,0 +1,145 @@
+package jsonb
+
+// Set returns a copy of the JSON document data where the value addressed by
+// path, as for GetPath, is replaced by value, which must be a single valid
+// JSON value. The parts of the document that are not replaced are copied
+// verbatim, and data is not modified.
+//
+// If the last segment of path does not exist in its object, the member is
+// added at the end of the object, and the missing intermediate objects are
+// created. The "-" segment appends value to an array. If value is nil, the
+// addressed member or element is deleted, and the document is returned
+// unchanged if it does not exist. In an object with duplicate keys, the
+// first member of the key is replaced or deleted, unlike Equal and Hash
+// which use the last one.
+//
+// It returns the syntax error of data if it is not a single valid JSON
+// value.
+//
+// It returns ErrIndexOutOfRange if an array index is past the end of its
+// array, and ErrNotFound if a segment of path addresses a value that is not
+// an array or an object, or is not a valid index in an array.
+func Set(data []byte, path []string, value []byte) ([]byte, error) {
+	if err := ValidateBytes(data); err != nil {
+		return nil, err
+	}
+	if value != nil {
+		if err := ValidateBytes(value); err != nil {
+			return nil, err
+		}
+	} else if len(path) == 0 {
+		return nil, &PointerError{Reason: "cannot remove the root value"}
+	}
+	return setValue(nil, data, path, value)
+}
+
+// setValue appends data to dst with the value at path replaced by value,
+// as described by Set.
+func setValue(dst, data []byte, path []string, value []byte) ([]byte, error) {
+	if len(path) == 0 {
+		return append(dst, value...), nil
+	}
+
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	seg, rest := path[0], path[1:]
+	idx := 0
+	switch p.tok {
+	case ObjectStart:
+	case ArrayStart:
+		var ok bool
+		if idx, ok = arrayIndex(seg); !ok {
+			return nil, ErrNotFound
+		}
+	default:
+		return nil, ErrNotFound
+	}
+
+	var key []byte
+	prevEnd := int64(-1) // end of the previous member or element
+	for i := 0; ; i++ {
+		if err := p.nextToken(); err != nil {
+			return nil, err
+		}
+		if p.tok.IsEnd() {
+			break
+		}
+
+		start := p.start
+		match := i == idx
+		if p.tok == ObjectKey {
+			var err error
+			if key, err = appendUnquote(key[:0], p.buf.Bytes()); err != nil {
+				return nil, err
+			}
+			match = string(key) == seg
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+		}
+		valStart := p.start
+		if !p.Skip() {
+			return nil, p.valueErr()
+		}
+		valEnd := p.start + int64(p.buf.Len())
+		if !match {
+			prevEnd = valEnd
+			continue
+		}
+
+		if value == nil && len(rest) == 0 {
+			if prevEnd >= 0 {
+				// drop the comma that precedes the member
+				dst = append(dst, data[:prevEnd]...)
+				return append(dst, data[valEnd:]...), nil
+			}
+			// drop the comma that follows the member, if any
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			dst = append(dst, data[:start]...)
+			if !p.tok.IsEnd() {
+				return append(dst, data[p.start:]...), nil
+			}
+			return append(dst, data[valEnd:]...), nil
+		}
+
+		var err error
+		dst = append(dst, data[:valStart]...)
+		if dst, err = setValue(dst, data[valStart:valEnd], rest, value); err != nil {
+			return nil, err
+		}
+		return append(dst, data[valEnd:]...), nil
+	}
+
+	// the segment does not exist
+	if value == nil {
+		return append(dst, data...), nil
+	}
+	if p.tok == ArrayEnd && seg != "-" {
+		return nil, ErrIndexOutOfRange
+	}
+	at := p.start
+	if prevEnd >= 0 {
+		at = prevEnd
+	}
+	dst = append(dst, data[:at]...)
+	if prevEnd >= 0 {
+		dst = append(dst, ',')
+	}
+	if p.tok == ObjectEnd {
+		dst = appendQuote(dst, seg)
+		dst = append(dst, ':')
+	}
+	for _, seg := range rest {
+		dst = appendQuote(append(dst, '{'), seg)
+		dst = append(dst, ':')
+	}
+	dst = append(dst, value...)
+	for range rest {
+		dst = append(dst, '}')
+	}
+	return append(dst, data[at:]...), nil
+}
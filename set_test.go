@@ -0,0 +1,81 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	const doc = `{"a": [1, 2], "b": {"c": true}}`
+	cases := []struct {
+		path  []string
+		value string
+		null  bool // value is nil
+		out   string
+		err   error
+	}{
+		{value: `1`, out: `1`},
+		{path: []string{"a"}, value: `"x"`, out: `{"a": "x", "b": {"c": true}}`},
+		{path: []string{"a", "1"}, value: `[]`, out: `{"a": [1, []], "b": {"c": true}}`},
+		{path: []string{"b", "c"}, value: `null`, out: `{"a": [1, 2], "b": {"c": null}}`},
+		{path: []string{"b", "d"}, value: `0`, out: `{"a": [1, 2], "b": {"c": true,"d":0}}`},
+		{path: []string{"x", "y", "z"}, value: `0`, out: `{"a": [1, 2], "b": {"c": true},"x":{"y":{"z":0}}}`},
+		{path: []string{"a", "-"}, value: `3`, out: `{"a": [1, 2,3], "b": {"c": true}}`},
+		{path: []string{"a"}, null: true, out: `{"b": {"c": true}}`},
+		{path: []string{"b"}, null: true, out: `{"a": [1, 2]}`},
+		{path: []string{"b", "c"}, null: true, out: `{"a": [1, 2], "b": {}}`},
+		{path: []string{"a", "0"}, null: true, out: `{"a": [2], "b": {"c": true}}`},
+		{path: []string{"a", "1"}, null: true, out: `{"a": [1], "b": {"c": true}}`},
+		{path: []string{"x", "y"}, null: true, out: doc},
+		{path: []string{"a", "5"}, null: true, out: doc},
+		{path: []string{"a", "2"}, value: `3`, err: ErrIndexOutOfRange},
+		{path: []string{"a", "x"}, value: `3`, err: ErrNotFound},
+		{path: []string{"b", "c", "d"}, value: `3`, err: ErrNotFound},
+		{null: true, err: &PointerError{Reason: "cannot remove the root value"}},
+		{path: []string{"a"}, value: `[`, err: io.ErrUnexpectedEOF},
+		{path: []string{"a"}, value: ``, err: io.ErrUnexpectedEOF},
+		{path: []string{"a"}, value: `  `, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		var value []byte
+		if !c.null {
+			value = []byte(c.value)
+		}
+		data := []byte(doc)
+		out, err := Set(data, c.path, value)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%q): want error %v, got %v", i, c.path, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d (%q): want %s, got %s", i, c.path, c.out, out)
+		}
+		if string(data) != doc {
+			t.Errorf("%d (%q): data was modified: %s", i, c.path, data)
+		}
+	}
+
+	// the whole document is validated
+	if _, err := Set([]byte(`{"a": 1} x`), []string{"a"}, []byte(`3`)); !errors.Is(err, ErrSyntax) {
+		t.Errorf("want syntax error, got %v", err)
+	}
+
+	// the first member of duplicate keys
+	out, err := Set([]byte(`{"a": 1, "a": 2}`), []string{"a"}, []byte(`3`))
+	if want := `{"a": 3, "a": 2}`; err != nil || string(out) != want {
+		t.Errorf("want %s, got %s (%v)", want, out, err)
+	}
+
+	// empty containers
+	out, err = Set([]byte(`{"a": [], "b": { }}`), []string{"b", "c"}, []byte(`[1]`))
+	if want := `{"a": [], "b": { "c":[1]}}`; err != nil || string(out) != want {
+		t.Errorf("want %s, got %s (%v)", want, out, err)
+	}
+	out, err = Set([]byte(`{"a": []}`), []string{"a", "-"}, []byte(`1`))
+	if want := `{"a": [1]}`; err != nil || string(out) != want {
+		t.Errorf("want %s, got %s (%v)", want, out, err)
+	}
+}
@@ -0,0 +1,201 @@
+package jsonb
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+// Sort returns the JSON array data with its elements sorted in ascending
+// order, or descending order if desc is true, in compact form. If key is
+// empty, the elements are sorted by their value, otherwise they must be
+// objects and they are sorted by the value of their member key.
+//
+// Values of different types are ordered as null, false, true, numbers,
+// strings, arrays and objects, and missing values sort before null. Numbers
+// are compared by their float64 value and strings by their decoded bytes.
+// Arrays and objects are not ordered among themselves. The sort is stable,
+// so elements with equal values keep their order. It returns ErrNotArray if
+// data is not an array.
+func Sort(data []byte, key string, desc bool) ([]byte, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.tok != ArrayStart {
+		return nil, ErrNotArray
+	}
+
+	items := make([]sortItem, len(root.elems))
+	for i, elem := range root.elems {
+		v := elem
+		if key != "" {
+			v = nil
+			if elem.tok == ObjectStart {
+				v = elem.Get(key)
+			}
+		}
+		if items[i], err = newSortItem(elem, v); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return items[j].less(&items[i])
+		}
+		return items[i].less(&items[j])
+	})
+
+	for i := range items {
+		root.elems[i] = items[i].elem
+	}
+	return root.appendJSON(nil), nil
+}
+
+// SortKeys returns the JSON document data in compact form, with the members
+// of all its objects sorted by the bytes of their decoded keys. The sort is
+// stable, so duplicate keys keep their order. The order of the elements of
+// arrays is unchanged, and the keys, strings and numbers are written
+// verbatim, with their escape sequences untouched.
+func SortKeys(data []byte) ([]byte, error) {
+	p := NewParserBytes(data)
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	dst, err := appendSortedKeys(nil, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// sortedMember is a member of an object being sorted by SortKeys.
+type sortedMember struct {
+	key        []byte // decoded key
+	start, end int    // range of the member in the buffer of the object
+}
+
+// appendSortedKeys appends the current value of p to dst, with the members
+// of its objects sorted, advancing p to the end of the value.
+func appendSortedKeys(dst []byte, p *Parser) ([]byte, error) {
+	var err error
+	switch p.tok {
+	case ArrayStart:
+		dst = append(dst, '[')
+		for i := 0; ; i++ {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if p.tok == ArrayEnd {
+				return append(dst, ']'), nil
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendSortedKeys(dst, p); err != nil {
+				return nil, err
+			}
+		}
+
+	case ObjectStart:
+		var members []sortedMember
+		var buf []byte
+		for {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if p.tok == ObjectEnd {
+				break
+			}
+			m := sortedMember{start: len(buf)}
+			if m.key, err = appendUnquote(nil, p.buf.Bytes()); err != nil {
+				return nil, err
+			}
+			buf = append(buf, p.buf.Bytes()...)
+			buf = append(buf, ':')
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if buf, err = appendSortedKeys(buf, p); err != nil {
+				return nil, err
+			}
+			m.end = len(buf)
+			members = append(members, m)
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return bytes.Compare(members[i].key, members[j].key) < 0
+		})
+
+		dst = append(dst, '{')
+		for i, m := range members {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, buf[m.start:m.end]...)
+		}
+		return append(dst, '}'), nil
+	}
+	return append(dst, p.buf.Bytes()...), nil
+}
+
+// sortItem is an element of an array being sorted, with its sort value.
+type sortItem struct {
+	elem *Node
+	rank int     // order of the type of the sort value
+	num  float64 // value of a number
+	str  []byte  // decoded value of a string
+}
+
+// newSortItem returns the sortItem of elem, with the sort value v, which is
+// nil if missing.
+func newSortItem(elem, v *Node) (sortItem, error) {
+	it := sortItem{elem: elem}
+	if v == nil {
+		return it, nil
+	}
+
+	var err error
+	switch v.tok {
+	case Null:
+		it.rank = 1
+	case False:
+		it.rank = 2
+	case True:
+		it.rank = 3
+	case Number:
+		it.rank = 4
+		if it.num, err = strconv.ParseFloat(unsafeString(v.raw), 64); err != nil && !isRangeError(err) {
+			return it, err
+		}
+	case String:
+		it.rank = 5
+		if it.str, err = appendUnquote(nil, v.raw); err != nil {
+			return it, err
+		}
+	case ArrayStart:
+		it.rank = 6
+	case ObjectStart:
+		it.rank = 7
+	}
+	return it, nil
+}
+
+// less returns true if the sort value of it is less than the one of o.
+func (it *sortItem) less(o *sortItem) bool {
+	if it.rank != o.rank {
+		return it.rank < o.rank
+	}
+	switch it.rank {
+	case 4:
+		return it.num < o.num
+	case 5:
+		return bytes.Compare(it.str, o.str) < 0
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package jsonb
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	cases := []struct {
+		in   string
+		key  string
+		desc bool
+		out  string
+		err  error
+	}{
+		{in: `[]`, out: `[]`},
+		{in: `[3, 1.5, -2, 1e1, 2]`, out: `[-2,1.5,2,3,1e1]`},
+		{in: `[3, 1.5, -2, 1e1, 2]`, desc: true, out: `[1e1,3,2,1.5,-2]`},
+		{in: `["b", "a", "A", "ab"]`, out: `["A","a","ab","b"]`},
+		{in: `[{}, [], "a", 1, true, false, null]`, out: `[null,false,true,1,"a",[],{}]`},
+		{in: `[[2], [1], 0]`, out: `[0,[2],[1]]`},
+		{in: `[{"a": 2, "b": "x"}, {"a": 1}, {"b": 3}, 4, {"a": 1, "c": 0}]`, key: "a",
+			out: `[{"b":3},4,{"a":1},{"a":1,"c":0},{"a":2,"b":"x"}]`},
+		{in: `[{"a": 2, "b": "x"}, {"a": 1}, {"b": 3}, 4, {"a": 1, "c": 0}]`, key: "a", desc: true,
+			out: `[{"a":2,"b":"x"},{"a":1},{"a":1,"c":0},{"b":3},4]`},
+		{in: `{"a": 1}`, err: ErrNotArray},
+		{in: `[1, 2`, err: io.ErrUnexpectedEOF},
+	}
+
+	for i, c := range cases {
+		out, err := Sort([]byte(c.in), c.key, c.desc)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
+
+func BenchmarkSort10K(b *testing.B) {
+	var bd Builder
+	bd.BeginArray()
+	for i := 0; i < 10000; i++ {
+		bd.BeginObject()
+		bd.Key("id")
+		bd.Int64(int64(i * 7919 % 10007))
+		bd.Key("name")
+		bd.String("item " + strconv.Itoa(i))
+		bd.EndObject()
+	}
+	bd.EndArray()
+	data := bd.Bytes()
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Sort(data, "id", false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+		err error
+	}{
+		{in: ` 1.50 `, out: `1.50`},
+		{in: `{}`, out: `{}`},
+		{in: `{"b": 1, "a": {}, "c": []}`, out: `{"a":{},"b":1,"c":[]}`},
+		{in: `{"z": {"y": 1, "x": [{"b": 2, "a": 1E3}, "\u00e9"]}, "a": null}`, out: `{"a":null,"z":{"x":[{"a":1E3,"b":2},"\u00e9"],"y":1}}`},
+		{in: `[{"b": 1, "a": 2}, {"d": 3, "c": 4}]`, out: `[{"a":2,"b":1},{"c":4,"d":3}]`},
+		{in: `{"b": 1, "a": 2, "b": 3}`, out: `{"a":2,"b":1,"b":3}`},
+		{in: `{"\u00e9": 1, "z": 2, "\u0061": 3}`, out: `{"\u0061":3,"z":2,"\u00e9":1}`},
+		{in: `{"a": [1, 2}`, err: &SyntaxError{Char: '}', Line: 1, Column: 12, Offset: 11, Near: []byte(`{"a": [1, 2}`), typ: begVal}},
+		{in: `{"a": 1} 2`, err: &SyntaxError{Char: '2', Line: 1, Column: 10, Offset: 9, Near: []byte(`{"a": 1} 2`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		out, err := SortKeys([]byte(c.in))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, out)
+		}
+	}
+}
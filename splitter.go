@@ -0,0 +1,68 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrDocumentTooLarge is returned by Splitter.Next when a document is
+// larger than the maximum size of the splitter.
+var ErrDocumentTooLarge = errors.New("jsonb: document too large")
+
+// Splitter splits a stream of top-level JSON values, such as NDJSON, into
+// the raw bytes of each value.
+type Splitter struct {
+	p     *Parser
+	max   int  // maximum size of a value, if > 0
+	reuse bool // the values are accumulated in buf
+	buf   []byte
+}
+
+// NewSplitter returns a splitter that reads the values of p, which must
+// accept multiple top-level values, see WithMultiValue.
+func NewSplitter(p *Parser) *Splitter {
+	return &Splitter{p: p}
+}
+
+// NewReaderSplitter returns a splitter that reads the values from r, with
+// at most maxSize bytes per value. A maxSize <= 0 means no limit. The bytes
+// of the values are accumulated in a buffer reused by each call to Next.
+func NewReaderSplitter(r io.Reader, maxSize int) *Splitter {
+	return &Splitter{p: NewNDJSONParser(r), max: maxSize, reuse: true}
+}
+
+// Next returns the raw bytes of the next value, without insignificant
+// whitespace but otherwise verbatim. It returns io.EOF once all values have
+// been read. For a splitter created by NewReaderSplitter, the returned slice
+// is only valid until the next call to Next.
+//
+// If the value is larger than the maximum size, it returns
+// ErrDocumentTooLarge and the rest of the value is skipped, so that Next can
+// be called again to read the following value.
+func (s *Splitter) Next() ([]byte, error) {
+	if !s.p.Next() {
+		if err := s.p.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if s.p.tok == Invalid {
+		return nil, s.p.valueErr()
+	}
+	if !s.reuse {
+		return ReadRaw(s.p)
+	}
+
+	b, err := s.p.appendValueMax(s.buf[:0], s.max)
+	s.buf = b
+	if err == ErrDocumentTooLarge {
+		if err := s.p.skipTo(0); err != nil {
+			return nil, err
+		}
+		return nil, ErrDocumentTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,54 @@
+package jsonb
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSplitter(t *testing.T) {
+	const in = "{\"a\": [1, 2]}\n\n  \"b\"\n[true, {\"c\": null}] 3\n{}"
+	want := []string{`{"a":[1,2]}`, `"b"`, `[true,{"c":null}]`, `3`, `{}`}
+
+	for _, s := range []*Splitter{
+		NewSplitter(NewNDJSONParser(strings.NewReader(in))),
+		NewReaderSplitter(strings.NewReader(in), 0),
+		NewReaderSplitter(strings.NewReader(in), 100),
+	} {
+		var got []string
+		for {
+			b, err := s.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, string(b))
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
+
+func TestSplitterErrors(t *testing.T) {
+	s := NewReaderSplitter(strings.NewReader("[1, 2]\n[1, 2, 3, 4, 5]\n\"abcdefgh\"\n{}"), 6)
+	for i, want := range []string{`[1,2]`, "", "", `{}`} {
+		b, err := s.Next()
+		if want == "" && err != ErrDocumentTooLarge {
+			t.Errorf("%d: want %v, got %v", i, ErrDocumentTooLarge, err)
+		}
+		if want != "" && (err != nil || string(b) != want) {
+			t.Errorf("%d: want %s, got %s (%v)", i, want, b, err)
+		}
+	}
+	if _, err := s.Next(); err != io.EOF {
+		t.Errorf("want %v, got %v", io.EOF, err)
+	}
+
+	s = NewReaderSplitter(strings.NewReader("[1, 2"), 0)
+	if _, err := s.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,47 @@
+package jsonb
+
+// ParseStats holds statistics about the last top-level value read by a
+// parser. In multi-value mode, they are reset at the start of each
+// document, the first one starting at the start of the input.
+type ParseStats struct {
+	Tokens   int64 // number of valid tokens of the document
+	Bytes    int64 // number of bytes read since the start of the document
+	MaxDepth int   // maximum nesting depth of arrays and objects
+	Strings  int64 // number of string values, excluding object keys
+	Numbers  int64
+	Nulls    int64
+	Booleans int64
+	Objects  int64
+	Arrays   int64
+}
+
+// Stats returns a snapshot of the statistics of the last document read by
+// the parser, see ParseStats.
+func (p *Parser) Stats() ParseStats {
+	s := p.stats
+	s.Tokens = p.ntok - p.stats.Tokens
+	s.Bytes = p.BytesProcessed() - p.stats.Bytes
+	return s
+}
+
+// count updates the statistics for the current token, which must be valid.
+func (p *Parser) count() {
+	p.ntok++
+	switch p.tok {
+	case String:
+		p.stats.Strings++
+	case Number:
+		p.stats.Numbers++
+	case Null:
+		p.stats.Nulls++
+	case True, False:
+		p.stats.Booleans++
+	case ObjectStart:
+		p.stats.Objects++
+	case ArrayStart:
+		p.stats.Arrays++
+	}
+	if len(p.stack) > p.stats.MaxDepth {
+		p.stats.MaxDepth = len(p.stack)
+	}
+}
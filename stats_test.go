@@ -0,0 +1,67 @@
+package jsonb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	const in = `{"a": [1, "b", null, true, {"c": [false, 2.5]}], "d": {}} `
+	p := NewParserString(in)
+	for p.Next() {
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := ParseStats{
+		Tokens:   19,
+		Bytes:    int64(len(in)),
+		MaxDepth: 4,
+		Strings:  1,
+		Numbers:  2,
+		Nulls:    1,
+		Booleans: 2,
+		Objects:  3,
+		Arrays:   2,
+	}
+	if got := p.Stats(); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	// peeking does not count the next token
+	p.ResetString(`["a", "b"]`)
+	p.Next()
+	p.Peek()
+	if got := p.Stats(); got.Tokens != 1 || got.Strings != 0 || got.Arrays != 1 {
+		t.Errorf("want only the ArrayStart token, got %+v", got)
+	}
+	p.Next()
+	if got := p.Stats(); got.Tokens != 2 || got.Strings != 1 {
+		t.Errorf("want 2 tokens and 1 string, got %+v", got)
+	}
+
+	p.ResetString(``)
+	if got := p.Stats(); !reflect.DeepEqual(ParseStats{}, got) {
+		t.Errorf("want zero stats after reset, got %+v", got)
+	}
+
+	// the statistics of the last document in multi-value mode
+	p = NewParserOptions(strings.NewReader("[1, [2]]\n{\"a\": null}\n"), WithMultiValue())
+	var first ParseStats
+	for p.Next() {
+		if p.Document() == 0 {
+			first = p.Stats()
+		}
+	}
+	if want := (ParseStats{Tokens: 6, Bytes: 10, MaxDepth: 2, Numbers: 2, Arrays: 2}); !reflect.DeepEqual(want, first) {
+		t.Errorf("want %+v for the first document, got %+v", want, first)
+	}
+	if want := (ParseStats{Tokens: 4, Bytes: 12, MaxDepth: 1, Nulls: 1, Objects: 1}); !reflect.DeepEqual(want, p.Stats()) {
+		t.Errorf("want %+v for the last document, got %+v", want, p.Stats())
+	}
+	if n := p.TokenCount(); n != 10 {
+		t.Errorf("want 10 tokens in total, got %d", n)
+	}
+}
@@ -27,7 +27,7 @@ var (
 func init() {
 	jsonEEmpty = []byte(fmt.Sprintf(jsonTpl, ""))
 
-	lr := &io.LimitedReader{rand.Reader, 1 << 10}
+	lr := &io.LimitedReader{R: rand.Reader, N: 1 << 10}
 	e1k, err := ioutil.ReadAll(lr)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,90 @@
+package jsonb
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrSubParserActive is returned when a parser is advanced while a
+// sub-parser returned by SubParser is still reading its tokens.
+var ErrSubParserActive = errors.New("jsonb: parser is blocked by an active sub-parser")
+
+// SubParser returns a parser for the array or object that starts at the
+// current token, which must be an ArrayStart or an ObjectStart, or it
+// returns nil. The sub-parser emits the tokens of that container only,
+// starting with the current token, as if the container was the root of
+// its document, and Next returns false once the matching end token has
+// been emitted. The tokens are read from p, with its options.
+//
+// While the sub-parser is active, calling Next or Peek on p fails with
+// ErrSubParserActive, which stops p. Once the sub-parser reaches the end
+// of the container, p is positioned on the matching ArrayEnd or ObjectEnd
+// token and can be used again.
+func (p *Parser) SubParser() *Parser {
+	if p.sub != nil || !p.tok.IsStart() || p.err != nil && p.err != io.EOF {
+		return nil
+	}
+	s := newParser(nil)
+	s.parent = p
+	s.root = len(p.stack)
+	s.floatOverflow = p.floatOverflow
+	p.sub = s
+	return s
+}
+
+// blocked sets the error of a parser advanced while a sub-parser is active.
+func (p *Parser) blocked() bool {
+	if p.err == nil {
+		p.err = ErrSubParserActive
+	}
+	return false
+}
+
+// advanceSub advances the parent of the sub-parser p and sets the current
+// token of p from it. The first call sets the start token of the root
+// container without advancing the parent.
+func (p *Parser) advanceSub() bool {
+	pp := p.parent
+	if pp.sub != p {
+		// done, or the parent was reset
+		return false
+	}
+
+	ok := true
+	if p.ntok > 0 {
+		ok = pp.step()
+	}
+	p.tok = pp.tok
+	p.buf.Reset()
+	p.buf.Write(pp.buf.Bytes())
+	p.err = pp.err
+	p.start = pp.start
+	p.keyBuf = append(p.keyBuf[:0], pp.keyBuf...)
+	p.keyed = pp.keyed
+
+	// the stack and path relative to the root container
+	p.stack = p.stack[:0]
+	p.path = p.path[:0]
+	p.keys = p.keys[:0]
+	if base := p.root - 1; len(pp.stack) > base {
+		p.stack = append(p.stack, pp.stack[base:]...)
+		off := pp.path[base].key
+		for _, f := range pp.path[base:] {
+			f.key -= off
+			p.path = append(p.path, f)
+		}
+		p.keys = append(p.keys, pp.keys[off:]...)
+	}
+
+	if !ok {
+		return false
+	}
+	if p.tok != Invalid {
+		p.count()
+	}
+	if len(pp.stack) < p.root {
+		// end of the root container, unblock the parent
+		pp.sub = nil
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package jsonb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubParser(t *testing.T) {
+	p := NewParser(strings.NewReader(`{"a": [1, {"b": [2]}], "c": 3}`))
+
+	var toks []Token
+	for p.Next() {
+		toks = append(toks, p.Token())
+		if p.Token() != ArrayStart {
+			continue
+		}
+
+		s := p.SubParser()
+		if s == nil {
+			t.Fatal("want a sub-parser")
+		}
+		if p.SubParser() != nil {
+			t.Fatal("want no second sub-parser")
+		}
+
+		var stoks []Token
+		var depths []int
+		var paths [][]string
+		for s.Next() {
+			stoks = append(stoks, s.Token())
+			depths = append(depths, s.Depth())
+			paths = append(paths, s.Path())
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		want := []Token{ArrayStart, Number, ObjectStart, ObjectKey, ArrayStart, Number, ArrayEnd, ObjectEnd, ArrayEnd}
+		if !reflect.DeepEqual(want, stoks) {
+			t.Errorf("want sub-parser tokens %v, got %v", want, stoks)
+		}
+		wantDepths := []int{1, 1, 2, 2, 3, 3, 2, 1, 0}
+		if !reflect.DeepEqual(wantDepths, depths) {
+			t.Errorf("want sub-parser depths %v, got %v", wantDepths, depths)
+		}
+		wantPaths := [][]string{{}, {"0"}, {"1"}, {"1", "b"}, {"1", "b"}, {"1", "b", "0"}, {"1", "b"}, {"1"}, {}}
+		if len(paths) != len(wantPaths) {
+			t.Errorf("want %d sub-parser paths, got %d", len(wantPaths), len(paths))
+		}
+		for i := range paths {
+			if strings.Join(paths[i], "/") != strings.Join(wantPaths[i], "/") {
+				t.Errorf("%d: want sub-parser path %v, got %v", i, wantPaths[i], paths[i])
+			}
+		}
+
+		// the parent is positioned on the end of the array
+		if p.Token() != ArrayEnd || p.Depth() != 1 {
+			t.Errorf("want parent on ArrayEnd at depth 1, got %s at depth %d", p.Token(), p.Depth())
+		}
+		toks = append(toks, p.Token())
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []Token{ObjectStart, ObjectKey, ArrayStart, ArrayEnd, ObjectKey, Number, ObjectEnd}
+	if !reflect.DeepEqual(want, toks) {
+		t.Errorf("want parent tokens %v, got %v", want, toks)
+	}
+}
+
+func TestSubParserBlocked(t *testing.T) {
+	p := NewParser(strings.NewReader(`[[1, 2], 3]`))
+	if p.SubParser() != nil {
+		t.Fatal("want no sub-parser before the first token")
+	}
+	p.Next()
+	p.Next()
+	p.Next()
+	if p.SubParser() != nil {
+		t.Fatal("want no sub-parser for a number")
+	}
+
+	p = NewParser(strings.NewReader(`[[1, 2], 3]`))
+	p.Next()
+	p.Next()
+	s := p.SubParser()
+	s.Next()
+	s.Next()
+
+	if p.Next() || p.Err() != ErrSubParserActive {
+		t.Errorf("want %v, got %v", ErrSubParserActive, p.Err())
+	}
+	if p.Peek() != Invalid {
+		t.Errorf("want Invalid from Peek")
+	}
+	if s.Next() || s.Err() != ErrSubParserActive {
+		t.Errorf("want %v from the sub-parser, got %v", ErrSubParserActive, s.Err())
+	}
+}
+
+func TestSubParserError(t *testing.T) {
+	p := NewParser(strings.NewReader(`{"a": [1, 2 3]}`))
+	p.Next()
+	p.Next()
+	p.Next()
+	s := p.SubParser()
+	for s.Next() {
+	}
+	if err := s.Err(); err == nil || err != p.Err() {
+		t.Errorf("want the same error from both parsers, got %v and %v", err, p.Err())
+	}
+}
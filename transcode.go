@@ -0,0 +1,75 @@
+package jsonb
+
+import (
+	"bufio"
+	"io"
+)
+
+// Transcoder reads the next JSON value from src and writes it to dst,
+// token by token. Strings, object keys and numbers are written verbatim,
+// with their escape sequences untouched. If dst indents its output, see
+// Writer.SetIndent, the value is reformatted. It returns the first error
+// of src or dst.
+func Transcoder(src *Parser, dst *Writer) error {
+	depth := 0
+	for {
+		if err := src.nextToken(); err != nil {
+			return err
+		}
+
+		raw := src.buf.Bytes()
+		switch src.tok {
+		case ArrayStart:
+			dst.BeginArray()
+			depth++
+		case ObjectStart:
+			dst.BeginObject()
+			depth++
+		case ArrayEnd:
+			dst.EndArray()
+			depth--
+		case ObjectEnd:
+			dst.EndObject()
+			depth--
+		case ObjectKey:
+			if dst.beginKey("WriteKey") {
+				dst.endKey(append(dst.buf, raw...))
+			}
+		case String:
+			dst.writeRaw("WriteString", raw)
+		case Number:
+			dst.writeRaw("WriteRaw", raw)
+		case True, False:
+			dst.WriteBool(src.tok == True)
+		case Null:
+			dst.WriteNull()
+		}
+
+		if err := dst.Err(); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// TranscodeReformat reads the JSON document from src and writes it to dst
+// using Transcoder, indented as described by Format, or in compact form if
+// indent is empty. It is equivalent to Reformat.
+func TranscodeReformat(src io.Reader, dst io.Writer, prefix, indent string) error {
+	p := NewParser(src)
+	bw := bufio.NewWriter(dst)
+	w := NewWriter(bw)
+	w.SetIndent(prefix, indent)
+	if err := Transcoder(p, w); err != nil {
+		return err
+	}
+
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
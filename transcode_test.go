@@ -0,0 +1,38 @@
+package jsonb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTranscoder(t *testing.T) {
+	p := NewNDJSONParser(bytes.NewReader([]byte("{\"a\\u00e9\": [1.50, \"\\n\", false]}\n\"b\"")))
+
+	// each call transcodes one value
+	for _, want := range []string{`{"a\u00e9":[1.50,"\n",false]}`, `"b"`} {
+		var buf bytes.Buffer
+		if err := Transcoder(p, NewWriter(&buf)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want {
+			t.Errorf("want %s, got %s", want, buf.String())
+		}
+	}
+
+	// a value written to a writer that already has a top-level value
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteNull()
+	err := Transcoder(NewParserString(`[]`), w)
+	if want := (&WriterError{Op: "BeginArray", Reason: "multiple top-level values"}); err == nil || err.Error() != want.Error() {
+		t.Errorf("want %v, got %v", want, err)
+	}
+
+	// an error of the io.Writer
+	errFail := errors.New("fail")
+	err = Transcoder(NewParserString(`[1, 2, 3]`), NewWriter(&failWriter{n: 3, err: errFail}))
+	if err != errFail {
+		t.Errorf("want %v, got %v", errFail, err)
+	}
+}
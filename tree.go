@@ -0,0 +1,282 @@
+package jsonb
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// Node is a JSON value held in memory, as returned by BuildTree. Arrays hold
+// their elements and objects hold their members, in the order of the
+// document, and other values hold their raw bytes.
+type Node struct {
+	tok   Token    // type of the value, ArrayStart or ObjectStart for containers
+	raw   []byte   // raw bytes of a literal, string or number
+	keys  []string // decoded keys of the members of an object
+	elems []*Node  // elements of an array or values of the members of an object
+}
+
+// BuildTree reads the JSON document from r and returns it as a tree of
+// nodes.
+func BuildTree(r io.Reader) (*Node, error) {
+	return buildTree(NewParser(r))
+}
+
+// parseTree parses the JSON document data in memory.
+func parseTree(data []byte) (*Node, error) {
+	return buildTree(NewParserBytes(data))
+}
+
+// buildTree parses the JSON document read by p in memory.
+func buildTree(p *Parser) (*Node, error) {
+	if err := p.nextToken(); err != nil {
+		return nil, err
+	}
+	n, err := parseNode(p)
+	if err != nil {
+		return nil, err
+	}
+	// only whitespace may follow the value
+	p.Next()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// parseNode parses the value that starts at the current token of p.
+func parseNode(p *Parser) (*Node, error) {
+	n := &Node{tok: p.tok}
+	switch p.tok {
+	case ArrayStart:
+		for {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if p.tok == ArrayEnd {
+				return n, nil
+			}
+			elem, err := parseNode(p)
+			if err != nil {
+				return nil, err
+			}
+			n.elems = append(n.elems, elem)
+		}
+
+	case ObjectStart:
+		for {
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			if p.tok == ObjectEnd {
+				return n, nil
+			}
+			key, err := p.String()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.nextToken(); err != nil {
+				return nil, err
+			}
+			elem, err := parseNode(p)
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key)
+			n.elems = append(n.elems, elem)
+		}
+	}
+
+	n.raw = append([]byte(nil), p.buf.Bytes()...)
+	return n, nil
+}
+
+// appendJSON appends the compact JSON encoding of n to dst.
+func (n *Node) appendJSON(dst []byte) []byte {
+	switch n.tok {
+	case ArrayStart:
+		dst = append(dst, '[')
+		for i, elem := range n.elems {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = elem.appendJSON(dst)
+		}
+		return append(dst, ']')
+
+	case ObjectStart:
+		dst = append(dst, '{')
+		for i, elem := range n.elems {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendQuote(dst, n.keys[i])
+			dst = append(dst, ':')
+			dst = elem.appendJSON(dst)
+		}
+		return append(dst, '}')
+	}
+	return append(dst, n.raw...)
+}
+
+// clone returns a deep copy of n. The raw bytes are shared as they are
+// never modified.
+func (n *Node) clone() *Node {
+	c := &Node{tok: n.tok, raw: n.raw}
+	if n.keys != nil {
+		c.keys = append([]string(nil), n.keys...)
+	}
+	if n.elems != nil {
+		c.elems = make([]*Node, len(n.elems))
+		for i, elem := range n.elems {
+			c.elems[i] = elem.clone()
+		}
+	}
+	return c
+}
+
+// index returns the index of the first member of the object n with the
+// specified key, or -1.
+func (n *Node) index(key string) int {
+	for i, k := range n.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// equal returns true if n and o are equal JSON values. Strings are compared
+// after decoding, numbers by their numeric value and objects regardless of
+// the order of their members.
+func (n *Node) equal(o *Node) bool {
+	if n.tok != o.tok || len(n.elems) != len(o.elems) {
+		return false
+	}
+
+	switch n.tok {
+	case ArrayStart:
+		for i, elem := range n.elems {
+			if !elem.equal(o.elems[i]) {
+				return false
+			}
+		}
+		return true
+
+	case ObjectStart:
+		for i, elem := range n.elems {
+			j := o.index(n.keys[i])
+			if j < 0 || !elem.equal(o.elems[j]) {
+				return false
+			}
+		}
+		return true
+
+	case String:
+		if bytes.Equal(n.raw, o.raw) {
+			return true
+		}
+		s1, err1 := appendUnquote(nil, n.raw)
+		s2, err2 := appendUnquote(nil, o.raw)
+		return err1 == nil && err2 == nil && bytes.Equal(s1, s2)
+
+	case Number:
+		return bytes.Equal(n.raw, o.raw) || equalNumbers(n.raw, o.raw)
+	}
+	return true
+}
+
+// Token returns the type of the value of n, which is ArrayStart for an array
+// and ObjectStart for an object.
+func (n *Node) Token() Token {
+	return n.tok
+}
+
+// Len returns the number of elements of an array or members of an object,
+// and 0 for other values.
+func (n *Node) Len() int {
+	return len(n.elems)
+}
+
+// Get returns the value addressed by path, starting at n. Each element of
+// path is either the key of an object member or the index of an array
+// element. It returns nil if there is no such value. When an object has
+// duplicate keys, the first member with the key is used.
+func (n *Node) Get(path ...string) *Node {
+	for _, seg := range path {
+		switch n.tok {
+		case ArrayStart:
+			i, ok := arrayIndex(seg)
+			if !ok || i < 0 || i >= len(n.elems) {
+				return nil
+			}
+			n = n.elems[i]
+		case ObjectStart:
+			i := n.index(seg)
+			if i < 0 {
+				return nil
+			}
+			n = n.elems[i]
+		default:
+			return nil
+		}
+	}
+	return n
+}
+
+// Each calls fn for each element of an array or value of the members of an
+// object, in order, until fn returns false.
+func (n *Node) Each(fn func(*Node) bool) {
+	for _, elem := range n.elems {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// MarshalJSON returns the compact JSON encoding of n.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	return n.appendJSON(nil), nil
+}
+
+// String returns the decoded value of a String node.
+func (n *Node) String() (string, error) {
+	if n.tok != String {
+		return "", ErrNotString
+	}
+	b, err := appendUnquote(nil, n.raw)
+	return string(b), err
+}
+
+// Int64 returns the value of a Number node as an int64. The number must be
+// written as an integer, without fraction or exponent.
+func (n *Node) Int64() (int64, error) {
+	if n.tok != Number {
+		return 0, ErrNotNumber
+	}
+	return parseInt64(n.raw, false)
+}
+
+// Float64 returns the value of a Number node as a float64. Numbers too large
+// to be represented are returned as positive or negative infinity.
+func (n *Node) Float64() (float64, error) {
+	if n.tok != Number {
+		return 0, ErrNotNumber
+	}
+	f, err := strconv.ParseFloat(unsafeString(n.raw), 64)
+	if err != nil && !isRangeError(err) {
+		return 0, err
+	}
+	return f, nil
+}
+
+// Bool returns the value of a True or False node.
+func (n *Node) Bool() (bool, error) {
+	switch n.tok {
+	case True:
+		return true, nil
+	case False:
+		return false, nil
+	}
+	return false, ErrNotBool
+}
@@ -0,0 +1,78 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBuildTree(t *testing.T) {
+	const in = ` {"a": [1, 2.5, "x\ty"], "b": {"c": true, "d": null}, "e": [], "a": 3} `
+
+	root, err := BuildTree(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Token() != ObjectStart || root.Len() != 4 {
+		t.Fatalf("want object of 4 members, got %s of %d", root.Token(), root.Len())
+	}
+
+	b, err := json.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":[1,2.5,"x\ty"],"b":{"c":true,"d":null},"e":[],"a":3}`; string(b) != want {
+		t.Errorf("want %s, got %s", want, b)
+	}
+
+	if n, err := root.Get("a", "0").Int64(); err != nil || n != 1 {
+		t.Errorf("a/0: want 1, got %d (%v)", n, err)
+	}
+	if _, err := root.Get("a", "1").Int64(); err != ErrNotInteger {
+		t.Errorf("a/1: want ErrNotInteger, got %v", err)
+	}
+	if f, err := root.Get("a", "1").Float64(); err != nil || f != 2.5 {
+		t.Errorf("a/1: want 2.5, got %g (%v)", f, err)
+	}
+	if s, err := root.Get("a", "2").String(); err != nil || s != "x\ty" {
+		t.Errorf("a/2: want %q, got %q (%v)", "x\ty", s, err)
+	}
+	if v, err := root.Get("b", "c").Bool(); err != nil || !v {
+		t.Errorf("b/c: want true, got %t (%v)", v, err)
+	}
+	if _, err := root.Get("b", "d").Bool(); err != ErrNotBool {
+		t.Errorf("b/d: want ErrNotBool, got %v", err)
+	}
+	if _, err := root.Get("b").String(); err != ErrNotString {
+		t.Errorf("b: want ErrNotString, got %v", err)
+	}
+	if _, err := root.Get("e").Float64(); err != ErrNotNumber {
+		t.Errorf("e: want ErrNotNumber, got %v", err)
+	}
+	for _, path := range [][]string{{"z"}, {"a", "3"}, {"a", "-"}, {"a", "01"}, {"b", "c", "d"}} {
+		if n := root.Get(path...); n != nil {
+			t.Errorf("%v: want nil, got %s", path, n.Token())
+		}
+	}
+	if root.Get() != root {
+		t.Errorf("want the root for an empty path")
+	}
+
+	var toks []Token
+	root.Each(func(n *Node) bool {
+		toks = append(toks, n.Token())
+		return n.Token() != ObjectStart
+	})
+	if want := []Token{ArrayStart, ObjectStart}; len(toks) != len(want) || toks[0] != want[0] || toks[1] != want[1] {
+		t.Errorf("want %v, got %v", want, toks)
+	}
+
+	if _, err := BuildTree(strings.NewReader(`[1, 2`)); err != io.ErrUnexpectedEOF {
+		t.Errorf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	if _, err := BuildTree(strings.NewReader(`[1] 2`)); !errors.Is(err, ErrSyntax) {
+		t.Errorf("want a syntax error, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package jsonb
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NewUTF16Parser returns a parser that reads a JSON document encoded in
+// UTF-16 from r, transcoding it to UTF-8 on the fly. If the input starts
+// with a byte order mark, the mark is skipped and it sets the byte order,
+// otherwise bigEndian sets it. Invalid surrogates fail like invalid UTF-8.
+// The offsets reported by the parser are byte offsets in the UTF-16 input.
+func NewUTF16Parser(r io.Reader, bigEndian bool, opts ...ParserOption) *Parser {
+	return NewParserOptions(newUTFReader(r, 2, bigEndian), opts...)
+}
+
+// NewUTF32Parser is like NewUTF16Parser for a JSON document encoded in
+// UTF-32.
+func NewUTF32Parser(r io.Reader, bigEndian bool, opts ...ParserOption) *Parser {
+	return NewParserOptions(newUTFReader(r, 4, bigEndian), opts...)
+}
+
+// utfReader is a RuneReader that decodes UTF-16 or UTF-32. The parser
+// reads it with ReadRune, Read is only provided to satisfy io.Reader.
+type utfReader struct {
+	r     io.ByteReader
+	size  int  // size of a code unit, 2 or 4
+	big   bool // big-endian byte order
+	start bool // the byte order mark has not been checked yet
+
+	pending []byte // UTF-8 bytes of a rune not yet returned by Read
+	buf     [utf8.UTFMax]byte
+}
+
+func newUTFReader(r io.Reader, size int, bigEndian bool) *utfReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &utfReader{r: br, size: size, big: bigEndian, start: true}
+}
+
+// Read reads the decoded input, encoded in UTF-8.
+func (u *utfReader) Read(b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		if len(u.pending) == 0 {
+			r, _, err := u.ReadRune()
+			if err != nil {
+				if n > 0 && err == io.EOF {
+					err = nil
+				}
+				return n, err
+			}
+			u.pending = utf8.AppendRune(u.buf[:0], r)
+		}
+		c := copy(b[n:], u.pending)
+		u.pending = u.pending[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// ReadRune returns the next rune and the number of bytes of input that
+// encode it.
+func (u *utfReader) ReadRune() (rune, int, error) {
+	c, err := u.unit()
+	if err != nil {
+		return 0, 0, err
+	}
+	width := u.size
+
+	if u.start {
+		u.start = false
+		swapped := uint32(0xFFFE)
+		if u.size == 4 {
+			swapped = 0xFFFE0000
+		}
+		if c == 0xFEFF || c == swapped {
+			// byte order mark, swapped if it was read in the wrong order
+			if c == swapped {
+				u.big = !u.big
+			}
+			if c, err = u.unit(); err != nil {
+				return 0, 0, err
+			}
+			width += u.size
+		}
+	}
+
+	r := rune(c)
+	switch {
+	case c > utf8.MaxRune:
+		return 0, 0, errInvalidCodePoint
+	case u.size == 2 && utf16.IsSurrogate(r):
+		c2, err := u.unit()
+		if err == io.EOF {
+			err = nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if r = utf16.DecodeRune(r, rune(c2)); r == utf8.RuneError {
+			return 0, 0, errInvalidCodePoint
+		}
+		return r, width + u.size, nil
+	case utf16.IsSurrogate(r):
+		return 0, 0, errInvalidCodePoint
+	}
+	return r, width, nil
+}
+
+// unit reads the next code unit. It returns io.EOF if the input ends at
+// a code unit boundary, and io.ErrUnexpectedEOF otherwise.
+func (u *utfReader) unit() (uint32, error) {
+	var c uint32
+	for i := 0; i < u.size; i++ {
+		b, err := u.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && i > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if u.big {
+			c = c<<8 | uint32(b)
+		} else {
+			c |= uint32(b) << (8 * i)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package jsonb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF encodes s in UTF-16 or UTF-32, with a byte order mark if bom is
+// true.
+func encodeUTF(s string, size int, big, bom bool) []byte {
+	var order binary.AppendByteOrder = binary.LittleEndian
+	if big {
+		order = binary.BigEndian
+	}
+	runes := []rune(s)
+	if bom {
+		runes = append([]rune{0xFEFF}, runes...)
+	}
+
+	var b []byte
+	if size == 2 {
+		for _, u := range utf16.Encode(runes) {
+			b = order.AppendUint16(b, u)
+		}
+		return b
+	}
+	for _, r := range runes {
+		b = order.AppendUint32(b, uint32(r))
+	}
+	return b
+}
+
+func TestUTFParsers(t *testing.T) {
+	const in = `{"a": ["é", "😀😀", 1.5, true], "€": null}`
+
+	var want []string
+	p := NewParserString(in)
+	for p.Next() {
+		want = append(want, string(p.Bytes()))
+	}
+
+	for _, size := range []int{2, 4} {
+		for _, big := range []bool{false, true} {
+			for _, bom := range []bool{false, true} {
+				b := encodeUTF(in, size, big, bom)
+				newParser := NewUTF16Parser
+				if size == 4 {
+					newParser = NewUTF32Parser
+				}
+
+				// with a BOM, the byte order of the argument is ignored
+				p := newParser(bytes.NewReader(b), big != bom)
+				var got []string
+				for p.Next() {
+					got = append(got, string(p.Bytes()))
+				}
+				if err := p.Err(); err != nil {
+					t.Errorf("size %d, big %t, bom %t: %v", size, big, bom, err)
+				}
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("size %d, big %t, bom %t: want %q, got %q", size, big, bom, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestUTFParsersInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		p    *Parser
+		err  error
+	}{
+		{name: "odd byte", p: NewUTF16Parser(bytes.NewReader([]byte{'1', 0, ' '}), false), err: io.ErrUnexpectedEOF},
+		{name: "truncated unit", p: NewUTF32Parser(bytes.NewReader([]byte{'1', 0, 0, 0, ' ', 0}), false), err: io.ErrUnexpectedEOF},
+		{name: "lone high surrogate", p: NewUTF16Parser(bytes.NewReader([]byte{'"', 0, 0x3d, 0xd8, '"', 0}), false)},
+		{name: "lone low surrogate", p: NewUTF16Parser(bytes.NewReader([]byte{0, '"', 0xde, 0x00, 0, '"'}), true)},
+		{name: "high surrogate at end", p: NewUTF16Parser(bytes.NewReader([]byte{'"', 0, 0x3d, 0xd8}), false)},
+		{name: "out of range", p: NewUTF32Parser(bytes.NewReader([]byte{'"', 0, 0, 0, 0, 0, 0x11, 0, '"', 0, 0, 0}), false)},
+		{name: "surrogate in UTF-32", p: NewUTF32Parser(bytes.NewReader([]byte{'"', 0, 0, 0, 0, 0xd8, 0, 0, '"', 0, 0, 0}), false)},
+	}
+
+	for _, c := range cases {
+		for c.p.Next() {
+		}
+		err := c.p.Err()
+		if c.err != nil && err != c.err || c.err == nil && err == nil {
+			t.Errorf("%s: want error %v, got %v", c.name, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,532 @@
+package jsonb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal a JSON
+// description of themselves. It has the same method as the encoding/json
+// Unmarshaler, so that a type implementing one implements both.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// InvalidUnmarshalError is returned when the argument of Unmarshal is not
+// a non-nil pointer.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "jsonb: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "jsonb: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "jsonb: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// UnmarshalTypeError is returned when a JSON value cannot be stored in the
+// Go value of the corresponding type.
+type UnmarshalTypeError struct {
+	Value  string       // type of the JSON value, e.g. "number"
+	Type   reflect.Type // type of the Go value that could not be assigned
+	Offset int64        // byte offset of the JSON value
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "jsonb: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// Unmarshal parses the JSON document data and stores the result in the value
+// pointed to by v, following the rules of encoding/json.Unmarshal for struct
+// fields, pointers, slices, arrays, maps with string keys, interfaces and
+// primitive types. Types implementing Unmarshaler are given the raw bytes of
+// their value, without the whitespace between its tokens.
+//
+// If a JSON value does not fit the corresponding Go value, Unmarshal skips
+// it, decodes the rest of the document and returns an *UnmarshalTypeError
+// for the first such value.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	d := decodeState{p: NewParserBytes(data)}
+	if err := d.next(); err != nil {
+		return err
+	}
+	if err := d.value(rv); err != nil {
+		return err
+	}
+	// only whitespace may follow the value
+	d.p.Next()
+	if err := d.p.Err(); err != nil {
+		return err
+	}
+	return d.err
+}
+
+// decodeState stores the values of a parser in Go values.
+type decodeState struct {
+	p   *Parser
+	key []byte // decoded object key
+	err error  // first type error encountered
+}
+
+// next advances the parser to the next token, which must exist.
+func (d *decodeState) next() error {
+	return d.p.nextToken()
+}
+
+// skip skips the current value.
+func (d *decodeState) skip() error {
+	if d.p.Skip() {
+		return nil
+	}
+	return d.p.valueErr()
+}
+
+// typeError records a type error for the current value and skips it.
+func (d *decodeState) typeError(v reflect.Value) error {
+	if d.err == nil {
+		d.err = &UnmarshalTypeError{Value: d.p.tok.String(), Type: v.Type(), Offset: d.p.start}
+	}
+	return d.skip()
+}
+
+// value stores the value that starts at the current token in v.
+func (d *decodeState) value(v reflect.Value) error {
+	u, v := indirect(v, d.p.tok == Null)
+	if u != nil {
+		raw, err := d.p.appendValue(nil)
+		if err != nil {
+			return err
+		}
+		return u.UnmarshalJSON(raw)
+	}
+
+	switch d.p.tok {
+	case ObjectStart:
+		return d.object(v)
+	case ArrayStart:
+		return d.array(v)
+	default:
+		return d.literal(v)
+	}
+}
+
+// indirect walks down v, allocating nil pointers, until it reaches a value
+// that is not a pointer or that implements Unmarshaler. If null is true, it
+// stops at the last settable pointer so that it can be set to nil.
+func indirect(v reflect.Value, null bool) (Unmarshaler, reflect.Value) {
+	// the pointer to a named value may implement Unmarshaler
+	if v.Kind() != reflect.Ptr && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for v.Kind() == reflect.Ptr {
+		if null && v.CanSet() {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if u, ok := v.Interface().(Unmarshaler); ok {
+			return u, reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return nil, v
+}
+
+func (d *decodeState) object(v reflect.Value) error {
+	var fields *structFields
+	switch v.Kind() {
+	case reflect.Struct:
+		fields = cachedFields(v.Type())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return d.typeError(v)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return d.typeError(v)
+		}
+		m := reflect.New(reflect.TypeOf(map[string]interface{}(nil))).Elem()
+		err := d.object(m)
+		v.Set(m)
+		return err
+	default:
+		return d.typeError(v)
+	}
+
+	for {
+		if err := d.next(); err != nil {
+			return err
+		}
+		if d.p.tok == ObjectEnd {
+			return nil
+		}
+
+		var err error
+		if d.key, err = appendUnquote(d.key[:0], d.p.buf.Bytes()); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Map {
+			key := reflect.ValueOf(string(d.key)).Convert(v.Type().Key())
+			if err := d.next(); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+			continue
+		}
+
+		f := fields.lookup(d.key)
+		if err := d.next(); err != nil {
+			return err
+		}
+		var fv reflect.Value
+		if f != nil {
+			fv = fieldByIndex(v, f.index)
+		}
+		if !fv.IsValid() {
+			err = d.skip()
+		} else {
+			err = d.value(fv)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (d *decodeState) array(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return d.typeError(v)
+		}
+		s := reflect.New(reflect.TypeOf([]interface{}(nil))).Elem()
+		err := d.array(s)
+		v.Set(s)
+		return err
+	default:
+		return d.typeError(v)
+	}
+
+	i := 0
+	for ; ; i++ {
+		if err := d.next(); err != nil {
+			return err
+		}
+		if d.p.tok == ArrayEnd {
+			break
+		}
+
+		if v.Kind() == reflect.Slice && i >= v.Len() {
+			if i < v.Cap() {
+				v.SetLen(i + 1)
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			} else {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+		}
+		var err error
+		if i < v.Len() {
+			err = d.value(v.Index(i))
+		} else {
+			// more elements than the length of the array
+			err = d.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case v.Kind() == reflect.Array:
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	case i == 0 && v.IsNil():
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	default:
+		v.SetLen(i)
+	}
+	return nil
+}
+
+func (d *decodeState) literal(v reflect.Value) error {
+	p := d.p
+	empty := v.Kind() == reflect.Interface && v.NumMethod() == 0
+
+	switch p.tok {
+	case Null:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+
+	case True, False:
+		b := p.tok == True
+		switch {
+		case v.Kind() == reflect.Bool:
+			v.SetBool(b)
+		case empty:
+			v.Set(reflect.ValueOf(b))
+		default:
+			return d.typeError(v)
+		}
+
+	case String, ObjectKey:
+		s, err := p.String()
+		if err != nil {
+			return err
+		}
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(s)
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+		case empty:
+			v.Set(reflect.ValueOf(s))
+		default:
+			return d.typeError(v)
+		}
+
+	case Number:
+		return d.number(v, empty)
+	}
+	return nil
+}
+
+func (d *decodeState) number(v reflect.Value, empty bool) error {
+	p := d.p
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := p.Int64()
+		if err != nil || v.OverflowInt(n) {
+			return d.typeError(v)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := p.Uint64()
+		if err != nil || v.OverflowUint(n) {
+			return d.typeError(v)
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := p.Float64()
+		if err != nil || math.IsInf(f, 0) || v.OverflowFloat(f) {
+			return d.typeError(v)
+		}
+		v.SetFloat(f)
+
+	default:
+		if !empty {
+			return d.typeError(v)
+		}
+		f, err := p.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return d.typeError(v)
+		}
+		v.Set(reflect.ValueOf(f))
+	}
+	return nil
+}
+
+// field is a struct field that can be decoded from an object member.
+type field struct {
+	name  []byte
+	index []int
+	depth int // depth of embedding of the field
+}
+
+type structFields struct {
+	list   []field
+	byName map[string]*field
+}
+
+// lookup returns the field for the object key name, matching it exactly or,
+// failing that, case-insensitively. It returns nil if no field matches.
+func (fs *structFields) lookup(name []byte) *field {
+	if f := fs.byName[string(name)]; f != nil {
+		return f
+	}
+	for i := range fs.list {
+		if bytes.EqualFold(fs.list[i].name, name) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedFields returns the decodable fields of the struct type t.
+func cachedFields(t reflect.Type) *structFields {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.(*structFields)
+	}
+
+	var list []field
+	list = appendFields(list, t, nil)
+
+	// keep the shallowest field for each name, the first one at the same depth
+	best := make(map[string]int, len(list))
+	for i, f := range list {
+		if j, ok := best[string(f.name)]; !ok || f.depth < list[j].depth {
+			best[string(f.name)] = i
+		}
+	}
+	fs := &structFields{byName: make(map[string]*field, len(best))}
+	for i, f := range list {
+		if best[string(f.name)] == i {
+			fs.list = append(fs.list, f)
+		}
+	}
+	for i := range fs.list {
+		fs.byName[string(fs.list[i].name)] = &fs.list[i]
+	}
+
+	actual, _ := fieldCache.LoadOrStore(t, fs)
+	return actual.(*structFields)
+}
+
+// appendFields appends the fields of the struct type t to list, including the
+// fields promoted from embedded structs.
+func appendFields(list []field, t reflect.Type, index []int) []field {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		if ix := strings.IndexByte(tag, ','); ix >= 0 {
+			name = tag[:ix]
+		}
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+
+		if sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if name == "" && ft.Kind() == reflect.Struct {
+				list = appendFields(list, ft, idx)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		list = append(list, field{name: []byte(name), index: idx, depth: len(index)})
+	}
+	return list
+}
+
+// fieldByIndex returns the field of v at index, allocating the embedded
+// pointers to structs as needed. It returns the zero Value if an embedded
+// pointer cannot be allocated.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// appendValue appends the raw bytes of the current value to dst, advancing
+// the parser to the end of the value if it is an array or an object. The
+// whitespace between the tokens of a container is not preserved.
+func (p *Parser) appendValue(dst []byte) ([]byte, error) {
+	return p.appendValueMax(dst, 0)
+}
+
+// appendValueMax is like appendValue, but it fails with ErrDocumentTooLarge
+// as soon as more than max bytes are appended, if max > 0.
+func (p *Parser) appendValueMax(dst []byte, max int) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, p.buf.Bytes()...)
+	if max > 0 && len(dst)-n > max {
+		return dst, ErrDocumentTooLarge
+	}
+	if p.tok != ArrayStart && p.tok != ObjectStart {
+		return dst, nil
+	}
+
+	depth := len(p.stack) - 1
+	prev := p.tok
+	for len(p.stack) > depth {
+		if !p.Next() || p.tok == Invalid {
+			return dst, p.valueErr()
+		}
+		switch {
+		case prev == ObjectKey:
+			dst = append(dst, ':')
+		case p.tok != ArrayEnd && p.tok != ObjectEnd && prev != ArrayStart && prev != ObjectStart:
+			dst = append(dst, ',')
+		}
+		dst = append(dst, p.buf.Bytes()...)
+		if max > 0 && len(dst)-n > max {
+			return dst, ErrDocumentTooLarge
+		}
+		prev = p.tok
+	}
+	return dst, nil
+}
+
+// nextToken advances the parser to the next token, returning an error if
+// there is no valid token.
+func (p *Parser) nextToken() error {
+	if p.Next() && p.tok != Invalid {
+		return nil
+	}
+	return p.valueErr()
+}
+
+// valueErr returns the error that stopped the parser before the end of a
+// value, which is io.ErrUnexpectedEOF if the input ended early.
+func (p *Parser) valueErr() error {
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
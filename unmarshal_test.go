@@ -0,0 +1,140 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type upper string
+
+func (u *upper) UnmarshalJSON(b []byte) error {
+	*u = upper(strings.ToUpper(string(b)))
+	return nil
+}
+
+type Embedded struct {
+	X int `json:"x"`
+}
+
+type outer struct {
+	*Embedded
+	Name    string               `json:"name"`
+	Skip    string               `json:"-"`
+	Tags    []string             `json:"tags,omitempty"`
+	Attrs   map[string]int       `json:"attrs"`
+	Ptr     *float64             `json:"ptr"`
+	Any     interface{}          `json:"any"`
+	Upper   upper                `json:"upper"`
+	Raw     json.RawMessage      `json:"raw"`
+	Arr     [2]uint8             `json:"arr"`
+	Bytes   []byte               `json:"bytes"`
+	Nested  map[string]*Embedded `json:"nested"`
+	Default bool
+	private int
+}
+
+func TestUnmarshal(t *testing.T) {
+	f := 1.5
+	cases := []struct {
+		in   string
+		dst  interface{}
+		want interface{}
+		err  error
+	}{
+		{in: `1`, dst: new(int), want: 1},
+		{in: ` "a\nb" `, dst: new(string), want: "a\nb"},
+		{in: `true`, dst: new(bool), want: true},
+		{in: `-1.5e1`, dst: new(float32), want: float32(-15)},
+		{in: `[1, 2, 3]`, dst: new([]int), want: []int{1, 2, 3}},
+		{in: `[]`, dst: new([]int), want: []int{}},
+		{in: `null`, dst: new([]int), want: []int(nil)},
+		{in: `{"a": [1, "b", null, {"c": false}]}`, dst: new(interface{}),
+			want: map[string]interface{}{"a": []interface{}{float64(1), "b", nil, map[string]interface{}{"c": false}}}},
+		{in: `{"field_a": "test", "field_b": 123, "field_c": true, "field_d": null, "field_e": "e"}`, dst: new(fieldsAE),
+			want: fieldsAE{fieldsAD: fieldsAD{A: "test", B: 123, C: true}, E: "e"}},
+		{in: `{"x": 1, "name": "n", "Skip": "s", "tags": ["a", "b"], "attrs": {"a": 1}, "ptr": 1.5, "any": [1],
+			"upper": "abc", "raw": {"a" : [1, 2]}, "arr": [1, 2, 3], "bytes": "AQI=", "nested": {"a": {"x": 2}, "b": null},
+			"default": true, "private": 1, "unknown": {"a": [{}]}}`, dst: new(outer),
+			want: outer{Embedded: &Embedded{X: 1}, Name: "n", Tags: []string{"a", "b"}, Attrs: map[string]int{"a": 1}, Ptr: &f, Any: []interface{}{float64(1)},
+				Upper: `"ABC"`, Raw: json.RawMessage(`{"a":[1,2]}`), Arr: [2]uint8{1, 2}, Bytes: []byte{1, 2},
+				Nested: map[string]*Embedded{"a": {X: 2}, "b": nil}, Default: true}},
+		{in: `{"a": 1, "b": "x", "c": 3}`, dst: new(map[string]int), want: map[string]int{"a": 1, "b": 0, "c": 3},
+			err: &UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0), Offset: 14}},
+		{in: `[1.5, 256, -1, 2]`, dst: new([]uint8), want: []uint8{0, 0, 0, 2},
+			err: &UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(uint8(0)), Offset: 1}},
+		{in: `1e700`, dst: new(float64), want: float64(0), err: &UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(float64(0)), Offset: 0}},
+		{in: `[-1e700]`, dst: new(interface{}), want: []interface{}{nil},
+			err: &UnmarshalTypeError{Value: "number", Type: reflect.TypeOf((*interface{})(nil)).Elem(), Offset: 1}},
+		{in: `{"a": 1}`, dst: new([]int), want: []int(nil), err: &UnmarshalTypeError{Value: "{", Type: reflect.TypeOf([]int{}), Offset: 0}},
+		{in: `[1, 2`, dst: new([]int), want: []int{1, 2}, err: io.ErrUnexpectedEOF},
+		{in: ``, dst: new(int), want: 0, err: io.ErrUnexpectedEOF},
+		{in: `1 2`, dst: new(int), want: 1, err: &SyntaxError{Char: '2', Line: 1, Column: 3, Offset: 2, Near: []byte(`1 2`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		err := Unmarshal([]byte(c.in), c.dst)
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): want error %v, got %v", i, c.in, c.err, err)
+		}
+		if got := reflect.ValueOf(c.dst).Elem().Interface(); !reflect.DeepEqual(c.want, got) {
+			t.Errorf("%d (%s): want %#v, got %#v", i, c.in, c.want, got)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	var i int
+	for _, v := range []interface{}{nil, i, (*int)(nil)} {
+		err := Unmarshal([]byte(`1`), v)
+		if _, ok := err.(*InvalidUnmarshalError); !ok {
+			t.Errorf("%#v: want *InvalidUnmarshalError, got %v", v, err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalAD(b *testing.B) {
+	var dst fieldsAD
+
+	for i := 0; i < b.N; i++ {
+		err := Unmarshal(jsonEEmpty, &dst)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalAEIgnoreE(b *testing.B) {
+	var dst fieldsAD
+
+	for i := 0; i < b.N; i++ {
+		err := Unmarshal(jsonE1M, &dst)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalAE1K(b *testing.B) {
+	var dst fieldsAE
+
+	for i := 0; i < b.N; i++ {
+		err := Unmarshal(jsonE1K, &dst)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalAE1M(b *testing.B) {
+	var dst fieldsAE
+
+	for i := 0; i < b.N; i++ {
+		err := Unmarshal(jsonE1M, &dst)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package jsonb
+
+import (
+	"io"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Validator checks that a JSON document is syntactically valid. It runs the
+// same state machine as the Parser, but does not store the bytes of the
+// tokens.
+type Validator struct {
+	p *Parser
+}
+
+// NewValidator returns a validator that reads from r.
+func NewValidator(r io.Reader) *Validator {
+	p := NewParser(r)
+	p.discard = true
+	return &Validator{p: p}
+}
+
+// Validate reads the whole document and returns the first error encountered,
+// or nil if the document is valid. An empty document, or one made only of
+// whitespace, is invalid and returns io.ErrUnexpectedEOF.
+func (v *Validator) Validate() error {
+	for v.p.Next() {
+	}
+	if err := v.p.Err(); err != nil {
+		return err
+	}
+	if v.p.TokenCount() == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// ValidateBytes returns the first error encountered in the JSON document b,
+// or nil if it is valid.
+func ValidateBytes(b []byte) error {
+	p := NewParserBytes(b)
+	p.discard = true
+	return (&Validator{p: p}).Validate()
+}
+
+// ValidateString returns the first error encountered in the JSON document s,
+// or nil if it is valid.
+func ValidateString(s string) error {
+	p := NewParserString(s)
+	p.discard = true
+	return (&Validator{p: p}).Validate()
+}
+
+// maxValidDepth is the nesting depth up to which IsValidJSON validates the
+// document without allocating.
+const maxValidDepth = 512
+
+// IsValidJSON returns true if data is a valid JSON document, that is if
+// ValidateBytes would return nil, so an empty document is invalid. It does not allocate, scanning the bytes
+// directly instead of decoding them to runes, except for documents nested
+// deeper than 512 levels which are validated with ValidateBytes.
+func IsValidJSON(data []byte) bool {
+	var stack [maxValidDepth]byte // the closing byte of the open containers
+	depth := 0
+
+	i := skipWhitespace(data, 0)
+	for {
+		// a value starts at i
+		if i == len(data) {
+			return false
+		}
+		switch c := data[i]; c {
+		case '[', '{':
+			if depth == maxValidDepth {
+				return ValidateBytes(data) == nil
+			}
+			end := byte(']')
+			if c == '{' {
+				end = '}'
+			}
+			stack[depth] = end
+			depth++
+			if i = skipWhitespace(data, i+1); i < len(data) && data[i] == end {
+				depth--
+				i++
+			} else if c == '{' {
+				if i = validKey(data, i); i < 0 {
+					return false
+				}
+				continue
+			} else {
+				continue
+			}
+		case '"':
+			if i = validString(data, i); i < 0 {
+				return false
+			}
+		case 't':
+			if i = validLiteral(data, i, "true"); i < 0 {
+				return false
+			}
+		case 'f':
+			if i = validLiteral(data, i, "false"); i < 0 {
+				return false
+			}
+		case 'n':
+			if i = validLiteral(data, i, "null"); i < 0 {
+				return false
+			}
+		default:
+			if i = validNumber(data, i); i < 0 {
+				return false
+			}
+		}
+
+		// the value ends at i, close the containers that end there
+		for {
+			i = skipWhitespace(data, i)
+			if depth == 0 {
+				return i == len(data)
+			}
+			if i == len(data) {
+				return false
+			}
+			if data[i] != stack[depth-1] {
+				break
+			}
+			depth--
+			i++
+		}
+		if data[i] != ',' {
+			return false
+		}
+		i = skipWhitespace(data, i+1)
+		if stack[depth-1] == '}' {
+			if i = validKey(data, i); i < 0 {
+				return false
+			}
+		}
+	}
+}
+
+// IsValidJSONString is like IsValidJSON for the JSON document s. The string
+// is not copied.
+func IsValidJSONString(s string) bool {
+	return IsValidJSON(unsafe.Slice(unsafe.StringData(s), len(s)))
+}
+
+// skipWhitespace returns the index of the first byte of data at or after
+// i that is not a whitespace.
+func skipWhitespace(data []byte, i int) int {
+	for i < len(data) && isWhitespace(rune(data[i])) {
+		i++
+	}
+	return i
+}
+
+// validKey checks the object key and colon that start at i, returning the
+// index of the value that follows, or -1 if they are invalid.
+func validKey(data []byte, i int) int {
+	if i == len(data) || data[i] != '"' {
+		return -1
+	}
+	if i = validString(data, i); i < 0 {
+		return -1
+	}
+	if i = skipWhitespace(data, i); i == len(data) || data[i] != ':' {
+		return -1
+	}
+	return skipWhitespace(data, i+1)
+}
+
+// validString checks the string literal that starts at i, returning the
+// index after it, or -1 if it is invalid.
+func validString(data []byte, i int) int {
+	for i++; i < len(data); {
+		switch c := data[i]; {
+		case c == '"':
+			return i + 1
+		case c == '\\':
+			if i+1 == len(data) {
+				return -1
+			}
+			switch data[i+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i += 2
+			case 'u':
+				if i+6 > len(data) {
+					return -1
+				}
+				if _, ok := hex4(data[i+2 : i+6]); !ok {
+					return -1
+				}
+				i += 6
+			default:
+				return -1
+			}
+		case c < 0x20:
+			return -1
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			// like the Parser, reject invalid encodings
+			r, n := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && n == 1 {
+				return -1
+			}
+			i += n
+		}
+	}
+	return -1
+}
+
+// validLiteral checks that the literal lit starts at i, returning the index
+// after it, or -1 if it does not.
+func validLiteral(data []byte, i int, lit string) int {
+	if len(data)-i < len(lit) || string(data[i:i+len(lit)]) != lit {
+		return -1
+	}
+	return i + len(lit)
+}
+
+// validNumber checks the number that starts at i, returning the index after
+// it, or -1 if it is invalid.
+func validNumber(data []byte, i int) int {
+	if data[i] == '-' {
+		i++
+	}
+	switch {
+	case i == len(data):
+		return -1
+	case data[i] == '0':
+		i++
+	case isDigit(data[i]):
+		i = skipDigits(data, i)
+	default:
+		return -1
+	}
+
+	if i < len(data) && data[i] == '.' {
+		if i++; i == len(data) || !isDigit(data[i]) {
+			return -1
+		}
+		i = skipDigits(data, i)
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		if i++; i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		if i == len(data) || !isDigit(data[i]) {
+			return -1
+		}
+		i = skipDigits(data, i)
+	}
+	return i
+}
+
+// skipDigits returns the index of the first byte of data at or after i that
+// is not a decimal digit.
+func skipDigits(data []byte, i int) int {
+	for i < len(data) && isDigit(data[i]) {
+		i++
+	}
+	return i
+}
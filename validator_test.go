@@ -0,0 +1,110 @@
+package jsonb
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	cases := []struct {
+		in  string
+		err error
+	}{
+		{in: ``, err: io.ErrUnexpectedEOF},
+		{in: `   `, err: io.ErrUnexpectedEOF},
+		{in: `null`},
+		{in: `{"a": [1, -2.5e3, "b\né", true, false, null, {}], "c": {"d": []}}`},
+		{in: `{"a": 1,}`, err: &SyntaxError{Char: '}', Line: 1, Column: 9, Offset: 8, Near: []byte(`{"a": 1,}`), typ: begKey}},
+		{in: `[1, 2`, err: io.ErrUnexpectedEOF},
+		{in: `[nul]`, err: &LiteralError{Line: 1, Column: 5, Offset: 4, Near: []byte(`[nul]`), want: 'l', got: ']', tok: Null}},
+		{in: `"\x"`, err: &SyntaxError{Char: 'x', Line: 1, Column: 3, Offset: 2, Near: []byte(`"\x"`), typ: chrEsc}},
+		{in: `1 2`, err: &SyntaxError{Char: '2', Line: 1, Column: 3, Offset: 2, Near: []byte(`1 2`), typ: endLit}},
+	}
+
+	for i, c := range cases {
+		if err := NewValidator(strings.NewReader(c.in)).Validate(); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): Validate: want %v, got %v", i, c.in, c.err, err)
+		}
+		if err := ValidateBytes([]byte(c.in)); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): ValidateBytes: want %v, got %v", i, c.in, c.err, err)
+		}
+		if err := ValidateString(c.in); !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d (%s): ValidateString: want %v, got %v", i, c.in, c.err, err)
+		}
+	}
+}
+
+func BenchmarkValidatorE1M(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := NewValidator(bytes.NewReader(jsonE1M)).Validate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserE1M(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := NewParser(bytes.NewReader(jsonE1M))
+		for p.Next() {
+		}
+		if err := p.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestIsValidJSON(t *testing.T) {
+	cases := []string{
+		``, ` `, `null`, `true`, `false`, `0`, `-0`, `1.5`, `-1.5e+10`, `1E-2`, `""`, `"a"`, `[]`, `{}`,
+		` [ 1 , "a" , { "b" : [ ] } ] `, `{"a": {"b": {"c": [[], {}]}}, "d": null}`,
+		`"\"\\\/\b\f\n\r\té😀"`, "\"é😀\u007f\"", `[1, [2, [3, [4]]]]`,
+		`nul`, `nulll`, `truex`, `t`, `fals`, `01`, `-`, `1.`, `.5`, `1e`, `1e+`, `+1`, `1.5.3`, `--1`,
+		`"`, `"a`, `"\x"`, `"\u12"`, `"\u12g4"`, "\"a\tb\"", "\"\xff\"", "\"�\"", "\xef\xbb\xbf1",
+		`[`, `]`, `[1`, `[1,`, `[1,]`, `[,1]`, `[1 2]`, `[1}`, `{"a"}`, `{"a":}`, `{"a" 1}`, `{1:2}`,
+		`{"a":1,}`, `{"a":1 "b":2}`, `{,}`, `{"a":1]`, `1 2`, `[] []`, `{}x`, `"a" "b"`, `x`, `/**/1`,
+	}
+
+	for i, c := range cases {
+		want := ValidateString(c) == nil
+		if got := IsValidJSON([]byte(c)); got != want {
+			t.Errorf("%d (%s): IsValidJSON: want %t, got %t", i, c, want, got)
+		}
+		if got := IsValidJSONString(c); got != want {
+			t.Errorf("%d (%s): IsValidJSONString: want %t, got %t", i, c, want, got)
+		}
+	}
+
+	// a document must have a value
+	for _, c := range []string{``, `   `} {
+		if IsValidJSON([]byte(c)) || IsValidJSONString(c) {
+			t.Errorf("(%q): want invalid empty document", c)
+		}
+	}
+
+	// deeper documents are validated with ValidateBytes
+	deep := strings.Repeat("[", 1000) + strings.Repeat("]", 1000)
+	if !IsValidJSONString(deep) {
+		t.Errorf("want valid deep document")
+	}
+	if IsValidJSONString(deep[:len(deep)-1]) {
+		t.Errorf("want invalid deep document")
+	}
+
+	if n := testing.AllocsPerRun(10, func() { IsValidJSON(jsonE1M) }); n != 0 {
+		t.Errorf("want no allocation, got %f", n)
+	}
+}
+
+func BenchmarkIsValidJSONE1M(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !IsValidJSON(jsonE1M) {
+			b.Fatal("invalid")
+		}
+	}
+}
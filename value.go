@@ -0,0 +1,77 @@
+package jsonb
+
+// TypeOf returns the type of the raw JSON value b, as returned by Get or
+// ReadRaw, from its first byte: String, Number, True, False, Null,
+// ArrayStart or ObjectStart. It returns Invalid if b is empty or does not
+// start like a JSON value. The rest of b is not validated.
+func TypeOf(b []byte) Token {
+	if len(b) == 0 {
+		return Invalid
+	}
+	switch c := b[0]; {
+	case c == '"':
+		return String
+	case c == '-' || isDigit(c):
+		return Number
+	case c == 't':
+		return True
+	case c == 'f':
+		return False
+	case c == 'n':
+		return Null
+	case c == '[':
+		return ArrayStart
+	case c == '{':
+		return ObjectStart
+	}
+	return Invalid
+}
+
+// StringValue returns the decoded value of the raw JSON string b, including
+// its surrounding double-quotes. It returns ErrNotString if b is not a
+// string, and ErrInvalidString if it is not a valid string literal.
+func StringValue(b []byte) (string, error) {
+	if TypeOf(b) != String {
+		return "", ErrNotString
+	}
+	s, err := appendUnquote(nil, b)
+	if err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// NumberValue returns the value of the raw JSON number b as a float64, as
+// for ParseNumber. It returns ErrNotNumber if b is not a number.
+func NumberValue(b []byte) (float64, error) {
+	if TypeOf(b) != Number {
+		return 0, ErrNotNumber
+	}
+	return ParseNumber(b)
+}
+
+// IntValue returns the value of the raw JSON number b as an int64, as for
+// ParseInt. It returns ErrNotNumber if b is not a number.
+func IntValue(b []byte) (int64, error) {
+	if TypeOf(b) != Number {
+		return 0, ErrNotNumber
+	}
+	return ParseInt(b)
+}
+
+// BoolValue returns the value of the raw JSON literal b, which must be true
+// or false, otherwise it returns ErrNotBool.
+func BoolValue(b []byte) (bool, error) {
+	switch string(b) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, ErrNotBool
+}
+
+// IsNull returns true if the raw JSON value b is null.
+func IsNull(b []byte) bool {
+	return string(b) == "null"
+}
@@ -0,0 +1,73 @@
+package jsonb
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestTypeOf(t *testing.T) {
+	cases := []struct {
+		in  string
+		tok Token
+	}{
+		{``, Invalid},
+		{`"a"`, String},
+		{`-1`, Number},
+		{`0.5`, Number},
+		{`true`, True},
+		{`false`, False},
+		{`null`, Null},
+		{`[1]`, ArrayStart},
+		{`{}`, ObjectStart},
+		{` 1`, Invalid},
+		{`x`, Invalid},
+	}
+	for i, c := range cases {
+		if tok := TypeOf([]byte(c.in)); tok != c.tok {
+			t.Errorf("%d: want %s, got %s", i, c.tok, tok)
+		}
+	}
+}
+
+func TestScalarValues(t *testing.T) {
+	cases := []struct {
+		in   string
+		fn   func([]byte) (interface{}, error)
+		want interface{}
+		err  error
+	}{
+		{`"a\u00e9"`, stringValue, "a\u00e9", nil},
+		{`"a`, stringValue, "", ErrInvalidString},
+		{`1`, stringValue, "", ErrNotString},
+		{`1.5e2`, numberValue, 150.0, nil},
+		{`1.`, numberValue, 0.0, &NumberSyntaxError{Num: `1.`}},
+		{`"1"`, numberValue, 0.0, ErrNotNumber},
+		{`-42`, intValue, int64(-42), nil},
+		{`1.5`, intValue, int64(0), ErrNotInteger},
+		{`9223372036854775808`, intValue, int64(0), &strconv.NumError{Func: "ParseInt", Num: "9223372036854775808", Err: strconv.ErrRange}},
+		{`null`, intValue, int64(0), ErrNotNumber},
+		{`true`, boolValue, true, nil},
+		{`false`, boolValue, false, nil},
+		{`tru`, boolValue, false, ErrNotBool},
+		{`null`, boolValue, false, ErrNotBool},
+	}
+	for i, c := range cases {
+		got, err := c.fn([]byte(c.in))
+		if !reflect.DeepEqual(c.err, err) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, err)
+		}
+		if got != c.want {
+			t.Errorf("%d: want %v, got %v", i, c.want, got)
+		}
+	}
+
+	if !IsNull([]byte(`null`)) || IsNull([]byte(`"null"`)) {
+		t.Error("IsNull: invalid result")
+	}
+}
+
+func stringValue(b []byte) (interface{}, error) { return StringValue(b) }
+func numberValue(b []byte) (interface{}, error) { return NumberValue(b) }
+func intValue(b []byte) (interface{}, error)    { return IntValue(b) }
+func boolValue(b []byte) (interface{}, error)   { return BoolValue(b) }
@@ -0,0 +1,25 @@
+package jsonb
+
+// Warning reports the use of a non-standard JSON feature accepted by the
+// options of a parser created with WithWarnOnExtensions. The feature is one
+// of "comment", "trailing comma", "single-quoted string", "single-quote
+// escape", "unquoted key", "special float", "hex number" or "octal number".
+type Warning struct {
+	Feature string // the non-standard feature
+	Offset  int64  // byte offset where the feature is used
+}
+
+// Warnings returns the warnings recorded since the parser was created or
+// reset, in order. Warnings are only recorded with WithWarnOnExtensions,
+// and do not affect the error of the parser.
+func (p *Parser) Warnings() []Warning {
+	return append([]Warning(nil), p.warnings...)
+}
+
+// warn records a warning for the feature used at offset off, if warnings
+// are enabled.
+func (p *Parser) warn(feature string, off int64) {
+	if p.warnExt {
+		p.warnings = append(p.warnings, Warning{Feature: feature, Offset: off})
+	}
+}
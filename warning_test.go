@@ -0,0 +1,58 @@
+package jsonb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	all := []ParserOption{
+		WithAllowComments(), WithAllowTrailingCommas(), WithAllowSpecialFloats(),
+		WithAllowSingleQuotes(), WithAllowUnquotedKeys(), WithAllowHexNumbers(),
+		WithAllowOctalNumbers(), WithWarnOnExtensions(),
+	}
+	cases := []struct {
+		in   string
+		opts []ParserOption
+		want []Warning
+	}{
+		{in: `{"a": [1, 2]}`, opts: all},
+		{in: `// c
+[1, /* c */ 2,]`, opts: all, want: []Warning{
+			{Feature: "comment", Offset: 0},
+			{Feature: "comment", Offset: 9},
+			{Feature: "trailing comma", Offset: 18},
+		}},
+		{in: `{a: 'b', "c": "d\'", e: -Infinity, f: NaN,}`, opts: all, want: []Warning{
+			{Feature: "unquoted key", Offset: 1},
+			{Feature: "single-quoted string", Offset: 4},
+			{Feature: "single-quote escape", Offset: 16},
+			{Feature: "unquoted key", Offset: 21},
+			{Feature: "special float", Offset: 24},
+			{Feature: "unquoted key", Offset: 35},
+			{Feature: "special float", Offset: 38},
+			{Feature: "trailing comma", Offset: 41},
+		}},
+		{in: `[0xFF, -0o17, 0]`, opts: all, want: []Warning{
+			{Feature: "hex number", Offset: 1},
+			{Feature: "octal number", Offset: 7},
+		}},
+
+		// without WithWarnOnExtensions
+		{in: `[1, /* c */ 2,]`, opts: all[:len(all)-1]},
+	}
+
+	for i, c := range cases {
+		p := NewParserOptions(strings.NewReader(c.in), c.opts...)
+		for p.Next() {
+		}
+		if err := p.Err(); err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got := p.Warnings(); !reflect.DeepEqual(c.want, got) {
+			t.Errorf("%d: want %v, got %v", i, c.want, got)
+		}
+	}
+}
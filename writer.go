@@ -0,0 +1,290 @@
+package jsonb
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// WriterError is returned by Writer.Err when a method of the writer is
+// called at a position where it would produce invalid JSON.
+type WriterError struct {
+	Op     string // the method of the Writer that failed
+	Reason string
+}
+
+func (e *WriterError) Error() string {
+	return fmt.Sprintf("jsonb: Writer.%s: %s", e.Op, e.Reason)
+}
+
+// Writer writes a JSON document to an io.Writer as it is generated. Like
+// Builder, it inserts the commas between the elements of arrays and the
+// members of objects, and the colons after the object keys.
+//
+// The first error, either an invalid call or an error returned by the
+// io.Writer, sets the error returned by Err, and all later calls are
+// ignored. The Writer does not buffer its output, wrap the io.Writer in
+// a bufio.Writer to reduce the number of writes.
+type Writer struct {
+	w      io.Writer
+	buf    []byte // scratch buffer for the current write
+	stack  []state
+	elems  bool // an element was written in the current container
+	done   bool // the top-level value was written
+	err    error
+	prefix string
+	indent string
+}
+
+// NewWriter returns a writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// SetIndent makes the writer indent the document as described by Format,
+// with one element per line. An empty indent, the default, writes the
+// document in compact form.
+func (w *Writer) SetIndent(prefix, indent string) {
+	w.prefix = prefix
+	w.indent = indent
+}
+
+// Err returns the first error encountered.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// WriteNull writes a null value.
+func (w *Writer) WriteNull() {
+	if w.value("WriteNull") {
+		w.write(append(w.buf, "null"...))
+	}
+}
+
+// WriteBool writes a true or false value.
+func (w *Writer) WriteBool(v bool) {
+	if w.value("WriteBool") {
+		w.write(strconv.AppendBool(w.buf, v))
+	}
+}
+
+// WriteString writes s as a string value, escaping it as needed. Invalid
+// UTF-8 bytes are replaced by \ufffd.
+func (w *Writer) WriteString(s string) {
+	if w.value("WriteString") {
+		w.write(appendQuote(w.buf, s))
+	}
+}
+
+// WriteInt writes v as a number value.
+func (w *Writer) WriteInt(v int64) {
+	if w.value("WriteInt") {
+		w.write(strconv.AppendInt(w.buf, v, 10))
+	}
+}
+
+// WriteUint writes v as a number value.
+func (w *Writer) WriteUint(v uint64) {
+	if w.value("WriteUint") {
+		w.write(strconv.AppendUint(w.buf, v, 10))
+	}
+}
+
+// WriteFloat writes v as a number value, formatted as by
+// strconv.FormatFloat with the fmt and prec arguments. The fmt must be one
+// of 'e', 'E', 'f', 'g' or 'G'. NaN and infinite values cannot be written
+// and set the error.
+func (w *Writer) WriteFloat(v float64, fmt byte, prec int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		w.fail("WriteFloat", "unsupported value "+strconv.FormatFloat(v, 'g', -1, 64))
+		return
+	}
+	switch fmt {
+	case 'e', 'E', 'f', 'g', 'G':
+	default:
+		w.fail("WriteFloat", "unsupported format "+strconv.QuoteRune(rune(fmt)))
+		return
+	}
+	if w.value("WriteFloat") {
+		w.write(strconv.AppendFloat(w.buf, v, fmt, prec, 64))
+	}
+}
+
+// WriteRaw writes v verbatim as a value. It must be a single valid JSON
+// value.
+func (w *Writer) WriteRaw(v []byte) {
+	if err := ValidateBytes(v); err != nil {
+		w.fail("WriteRaw", err.Error())
+		return
+	}
+	w.writeRaw("WriteRaw", v)
+}
+
+// writeRaw writes v verbatim as a value, without validating it.
+func (w *Writer) writeRaw(op string, v []byte) {
+	if w.value(op) {
+		w.write(append(w.buf, v...))
+	}
+}
+
+// BeginObject starts an object value.
+func (w *Writer) BeginObject() {
+	if w.value("BeginObject") {
+		w.write(append(w.buf, '{'))
+		w.stack = append(w.stack, stObjKey)
+		w.elems = false
+	}
+}
+
+// EndObject ends the current object.
+func (w *Writer) EndObject() {
+	if w.end("EndObject", stObjKey) {
+		w.write(append(w.buf, '}'))
+	}
+}
+
+// BeginArray starts an array value.
+func (w *Writer) BeginArray() {
+	if w.value("BeginArray") {
+		w.write(append(w.buf, '['))
+		w.stack = append(w.stack, stArray)
+		w.elems = false
+	}
+}
+
+// EndArray ends the current array.
+func (w *Writer) EndArray() {
+	if w.end("EndArray", stArray) {
+		w.write(append(w.buf, ']'))
+	}
+}
+
+// WriteKey writes the key of the next member of the current object. It must
+// be followed by the value of the member. The key is escaped like
+// WriteString.
+func (w *Writer) WriteKey(k string) {
+	if w.beginKey("WriteKey") {
+		w.endKey(appendQuote(w.buf, k))
+	}
+}
+
+// beginKey prepares the writer to write an object key, returning false if
+// a key cannot be written at the current position. The comma and the
+// indentation that precede the key, if any, are appended to the scratch
+// buffer.
+func (w *Writer) beginKey(op string) bool {
+	if w.err != nil {
+		return false
+	}
+	l := len(w.stack)
+	if l == 0 || w.stack[l-1] != stObjKey {
+		w.fail(op, "not expecting an object key")
+		return false
+	}
+	w.buf = w.buf[:0]
+	if w.elems {
+		w.buf = append(w.buf, ',')
+	}
+	w.buf = w.newline(w.buf, l)
+	return true
+}
+
+// endKey writes b, which ends with the quoted key, followed by the colon.
+func (w *Writer) endKey(b []byte) {
+	b = append(b, ':')
+	if w.indent != "" {
+		b = append(b, ' ')
+	}
+	w.stack[len(w.stack)-1] = stObjVal
+	w.write(b)
+}
+
+// value prepares the writer to write a value, returning false if a value
+// cannot be written at the current position. The comma that precedes the
+// value, if any, is appended to the scratch buffer.
+func (w *Writer) value(op string) bool {
+	if w.err != nil {
+		return false
+	}
+	w.buf = w.buf[:0]
+
+	l := len(w.stack)
+	if l == 0 {
+		if w.done {
+			w.fail(op, "multiple top-level values")
+			return false
+		}
+		w.done = true
+		return true
+	}
+
+	switch w.stack[l-1] {
+	case stObjKey:
+		w.fail(op, "missing object key")
+		return false
+	case stObjVal:
+		w.stack[l-1] = stObjKey
+	default:
+		if w.elems {
+			w.buf = append(w.buf, ',')
+		}
+		w.buf = w.newline(w.buf, l)
+	}
+	w.elems = true
+	return true
+}
+
+// end closes the current container, which must be of the specified state,
+// returning false if it cannot be closed.
+func (w *Writer) end(op string, st state) bool {
+	if w.err != nil {
+		return false
+	}
+	w.buf = w.buf[:0]
+
+	l := len(w.stack)
+	if l == 0 || w.stack[l-1] != st {
+		if l > 0 && w.stack[l-1] == stObjVal {
+			w.fail(op, "missing value for object key")
+		} else {
+			w.fail(op, "no matching begin")
+		}
+		return false
+	}
+	if w.elems {
+		w.buf = w.newline(w.buf, l-1)
+	}
+	w.stack = w.stack[:l-1]
+	w.elems = true
+	return true
+}
+
+// newline appends a newline and the indentation for depth to b, if the
+// writer indents the document.
+func (w *Writer) newline(b []byte, depth int) []byte {
+	if w.indent == "" {
+		return b
+	}
+	b = append(b, '\n')
+	b = append(b, w.prefix...)
+	for i := 0; i < depth; i++ {
+		b = append(b, w.indent...)
+	}
+	return b
+}
+
+// write writes b to the underlying writer, keeping it as scratch buffer.
+func (w *Writer) write(b []byte) {
+	if _, err := w.w.Write(b); err != nil {
+		w.err = err
+	}
+	w.buf = b[:0]
+}
+
+// fail sets the error of the writer, if it is the first one.
+func (w *Writer) fail(op, reason string) {
+	if w.err == nil {
+		w.err = &WriterError{Op: op, Reason: reason}
+	}
+}
@@ -0,0 +1,134 @@
+package jsonb
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	cases := []struct {
+		write func(w *Writer)
+		out   string
+		err   error
+	}{
+		{write: func(w *Writer) {}, out: ``},
+		{write: func(w *Writer) { w.WriteNull() }, out: `null`},
+		{write: func(w *Writer) {
+			w.BeginArray()
+			w.WriteBool(true)
+			w.WriteInt(-1)
+			w.WriteUint(math.MaxUint64)
+			w.WriteFloat(1.5e-7, 'g', -1)
+			w.WriteFloat(2, 'f', 2)
+			w.WriteString("a\"\n")
+			w.WriteRaw([]byte(`{"x": [1]}`))
+			w.BeginArray()
+			w.EndArray()
+			w.EndArray()
+		}, out: `[true,-1,18446744073709551615,1.5e-07,2.00,"a\"\n",{"x": [1]},[]]`},
+		{write: func(w *Writer) {
+			w.BeginObject()
+			w.WriteKey("k\xff")
+			w.WriteString("a\xffb")
+			w.EndObject()
+		}, out: `{"k\ufffd":"a\ufffdb"}`},
+		{write: func(w *Writer) {
+			w.BeginObject()
+			w.WriteKey("a")
+			w.BeginObject()
+			w.EndObject()
+			w.WriteKey("b")
+			w.BeginArray()
+			w.BeginObject()
+			w.WriteKey("c")
+			w.WriteNull()
+			w.EndObject()
+			w.WriteInt(0)
+			w.EndArray()
+			w.WriteKey("d")
+			w.WriteString("e")
+			w.EndObject()
+		}, out: `{"a":{},"b":[{"c":null},0],"d":"e"}`},
+		{write: func(w *Writer) {
+			w.BeginArray()
+			w.WriteKey("a")
+			w.WriteNull()
+		}, out: `[`, err: &WriterError{Op: "WriteKey", Reason: "not expecting an object key"}},
+		{write: func(w *Writer) {
+			w.BeginObject()
+			w.WriteNull()
+		}, out: `{`, err: &WriterError{Op: "WriteNull", Reason: "missing object key"}},
+		{write: func(w *Writer) {
+			w.BeginObject()
+			w.WriteKey("a")
+			w.EndObject()
+		}, out: `{"a":`, err: &WriterError{Op: "EndObject", Reason: "missing value for object key"}},
+		{write: func(w *Writer) {
+			w.BeginObject()
+			w.WriteKey("a")
+			w.WriteRaw(nil)
+			w.EndObject()
+		}, out: `{"a":`, err: &WriterError{Op: "WriteRaw", Reason: "unexpected EOF"}},
+		{write: func(w *Writer) {
+			w.BeginArray()
+			w.EndObject()
+		}, out: `[`, err: &WriterError{Op: "EndObject", Reason: "no matching begin"}},
+		{write: func(w *Writer) {
+			w.WriteInt(1)
+			w.WriteInt(2)
+		}, out: `1`, err: &WriterError{Op: "WriteInt", Reason: "multiple top-level values"}},
+		{write: func(w *Writer) {
+			w.BeginArray()
+			w.WriteFloat(math.Inf(1), 'g', -1)
+			w.WriteNull()
+		}, out: `[`, err: &WriterError{Op: "WriteFloat", Reason: "unsupported value +Inf"}},
+		{write: func(w *Writer) {
+			w.WriteFloat(1, 'x', -1)
+		}, out: ``, err: &WriterError{Op: "WriteFloat", Reason: "unsupported format 'x'"}},
+	}
+
+	for i, c := range cases {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		c.write(w)
+		if !reflect.DeepEqual(c.err, w.Err()) {
+			t.Errorf("%d: want error %v, got %v", i, c.err, w.Err())
+		}
+		if buf.String() != c.out {
+			t.Errorf("%d: want %s, got %s", i, c.out, buf.String())
+		}
+	}
+}
+
+// failWriter fails after n bytes have been written.
+type failWriter struct {
+	n   int
+	err error
+}
+
+func (f *failWriter) Write(b []byte) (int, error) {
+	if len(b) > f.n {
+		return f.n, f.err
+	}
+	f.n -= len(b)
+	return len(b), nil
+}
+
+func TestWriterIOError(t *testing.T) {
+	errFail := errors.New("fail")
+	fw := &failWriter{n: 5, err: errFail}
+	w := NewWriter(fw)
+	w.BeginArray()
+	w.WriteInt(123)
+	w.WriteInt(456)
+	w.WriteInt(789)
+	if w.Err() != errFail {
+		t.Errorf("want %v, got %v", errFail, w.Err())
+	}
+	if fw.n != 1 {
+		t.Errorf("want no write after the error, got %d bytes left", fw.n)
+	}
+}